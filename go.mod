@@ -6,22 +6,27 @@ require (
 	github.com/code-ready/clicumber v0.0.0-20210201104241-cecb794bdf9a
 	github.com/cucumber/godog v0.9.0
 	github.com/cucumber/messages-go/v10 v10.0.3
+	github.com/fatih/color v1.9.0 // indirect
+	github.com/gofrs/uuid v3.3.0+incompatible // indirect
+	github.com/google/cel-go v0.6.0
 	github.com/google/go-cmp v0.5.5
 	github.com/h2non/gock v1.0.9
+	github.com/imdario/mergo v0.3.11 // indirect
 	github.com/jenkins-x/go-scm v1.8.1
+	github.com/mattn/go-colorable v0.1.7 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/mkmik/multierror v0.3.0
 	github.com/openshift/api v0.0.0-20210503193030-25175d9d392d
 	github.com/openshift/client-go v0.0.0-20210503124028-ac0910aac9fa
 	github.com/openshift/odo v1.2.6
-	github.com/operator-framework/api v0.8.0
-	github.com/operator-framework/operator-lifecycle-manager v0.18.0
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/afero v1.6.0
 	github.com/spf13/cobra v1.1.3
 	github.com/tektoncd/pipeline v0.22.0
 	github.com/tektoncd/triggers v0.12.1
 	github.com/zalando/go-keyring v0.1.1
+	google.golang.org/genproto v0.0.0-20210416161957-9910b6c460de
 	gopkg.in/AlecAivazis/survey.v1 v1.8.8
 	k8s.io/api v0.21.0
 	k8s.io/apimachinery v0.21.0
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	addRecommendedCommandName = "add"
+)
+
+var (
+	addExample = ktemplates.Examples(`	Add an Application backed by an external config repository to an environment in GitOps
+	%[1]s --env-name dev --app-name taxi --config-repo-url https://github.com/org/taxi-config.git --path deploy/dev`)
+
+	addLongDesc  = ktemplates.LongDesc(`Add an Application to an environment, the Argo CD Application syncs the configuration from an external config repository`)
+	addShortDesc = `Add an Application with an external config repository`
+)
+
+// AddOptions encapsulates the parameters for application add command
+type AddOptions struct {
+	*pipelines.AddApplicationOptions
+}
+
+// Complete is called when the command is completed
+func (o *AddOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the AddOptions.
+func (o *AddOptions) Validate() error {
+	return nil
+}
+
+// Run runs the application add command.
+func (o *AddOptions) Run() error {
+	err := pipelines.AddApplication(o.AddApplicationOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Successf("Created Application %s successfully in environment %s.\n", o.AppName, o.EnvName)
+	return nil
+}
+
+func newCmdAdd(name, fullName string) *cobra.Command {
+	o := &AddOptions{AddApplicationOptions: &pipelines.AddApplicationOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   addShortDesc,
+		Long:    addLongDesc,
+		Example: fmt.Sprintf(addExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the application will be added")
+	cmd.Flags().StringVar(&o.AppName, "app-name", "", "Name of the application to be added")
+	cmd.Flags().StringVar(&o.ConfigRepoURL, "config-repo-url", "", "URL of the Git repository that contains the application configuration")
+	cmd.Flags().StringVar(&o.Path, "path", "", "Path within the config repository to the application configuration")
+	cmd.Flags().StringVar(&o.TargetRevision, "target-revision", "", "Commit, tag, or branch of the config repository to sync, if not provided HEAD is synced")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("env-name")
+	_ = cmd.MarkFlagRequired("app-name")
+	_ = cmd.MarkFlagRequired("config-repo-url")
+	_ = cmd.MarkFlagRequired("path")
+	return cmd
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type keyValuePair struct {
+	key   string
+	value string
+}
+
+func TestAddCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing app-name flag",
+			[]keyValuePair{flag("env-name", "dev"), flag("config-repo-url", "https://github.com/org/config.git"), flag("path", "deploy")},
+			`required flag(s) "app-name" not set`},
+		{"Missing config-repo-url flag",
+			[]keyValuePair{flag("env-name", "dev"), flag("app-name", "taxi"), flag("path", "deploy")},
+			`required flag(s) "config-repo-url" not set`},
+		{"Missing path and env-name flags",
+			[]keyValuePair{flag("app-name", "taxi"), flag("config-repo-url", "https://github.com/org/config.git")},
+			`required flag(s) "env-name", "path" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := executeCommand(newCmdAdd("add", "kam app"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				t.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
+	buf := new(bytes.Buffer)
+	cmd.SetOutput(buf)
+	for _, flag := range flags {
+		err = cmd.Flags().Set(flag.key, flag.value)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	c, err = cmd.ExecuteC()
+	return c, buf.String(), err
+}
+
+func flag(k, v string) keyValuePair {
+	return keyValuePair{
+		key:   k,
+		value: v,
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended application command name.
+const RecommendedCommandName = "app"
+
+// NewCmd creates a new application command
+func NewCmd(name, fullName string) *cobra.Command {
+
+	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage applications in an environment",
+		Long:  "Manage applications in a GitOps environment where the configuration is maintained in an external config repository",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.Flags().AddFlagSet(addCmd.Flags())
+	cmd.AddCommand(addCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,22 +20,25 @@ import (
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines"
 	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
-	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 const (
 	// BootstrapRecommendedCommandName the recommended command name
 	BootstrapRecommendedCommandName = "bootstrap"
 
-	pipelinesOperatorNS    = "openshift-operators"
 	gitopsRepoURLFlag      = "gitops-repo-url"
 	serviceRepoURLFlag     = "service-repo-url"
 	gitHostAccessTokenFlag = "git-host-access-token"
 	imageRepoFlag          = "image-repo"
-	gitopsOperatorName     = "OpenShift GitOps Operator"
-	pipelinesOperatorName  = "OpenShift Pipelines Operator"
+	argoCDName             = "Argo CD"
+	tektonPipelinesName    = "Tekton Pipelines"
+	tektonTriggersName     = "Tekton Triggers"
+	pipelineResourcesName  = "Tekton PipelineResources"
 )
 
 type drivers []string
@@ -45,6 +47,8 @@ var (
 	supportedDrivers = drivers{
 		"github",
 		"gitlab",
+		"gitea",
+		"bitbucketserver",
 	}
 )
 
@@ -70,7 +74,8 @@ var (
 // BootstrapParameters encapsulates the parameters for the kam pipelines init command.
 type BootstrapParameters struct {
 	*pipelines.BootstrapOptions
-	Interactive bool
+	Interactive          bool
+	SkipDependencyChecks bool
 }
 
 // NewBootstrapParameters bootsraps a Bootstrap Parameters instance.
@@ -84,11 +89,6 @@ func NewBootstrapParameters() *BootstrapParameters {
 // If the prefix provided doesn't have a "-" then one is added, this makes the
 // generated environment names nicer to read.
 func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []string) error {
-	client, err := utility.NewClient()
-	if err != nil {
-		return err
-	}
-
 	if io.PrivateRepoDriver != "" {
 		host, err := accesstoken.HostFromURL(io.GitOpsRepoURL)
 		if err != nil {
@@ -97,16 +97,24 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
 		factory.DefaultIdentifier = identifier
 	}
-	if err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout)); err != nil {
-		return err
+	if io.SkipDependencyChecks {
+		log.Warning("Skipping the dependency checks, the generated resources need Argo CD, Tekton Pipelines and Tekton Triggers")
+	} else {
+		client, err := utility.NewClient()
+		if err != nil {
+			return err
+		}
+		if err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout)); err != nil {
+			return err
+		}
 	}
 
 	if cmd.Flags().NFlag() == 0 || io.Interactive {
-		return initiateInteractiveMode(io, client, cmd)
+		return initiateInteractiveMode(io, cmd)
 	}
 
 	addGitURLSuffixIfNecessary(io)
-	return nonInteractiveMode(io, client)
+	return nonInteractiveMode(io)
 }
 
 func addGitURLSuffixIfNecessary(io *BootstrapParameters) {
@@ -115,7 +123,7 @@ func addGitURLSuffixIfNecessary(io *BootstrapParameters) {
 }
 
 // nonInteractiveMode gets triggered if a flag is passed, checks for mandatory flags.
-func nonInteractiveMode(io *BootstrapParameters, client *utility.Client) error {
+func nonInteractiveMode(io *BootstrapParameters) error {
 	mandatoryFlags := map[string]string{serviceRepoURLFlag: io.ServiceRepoURL, gitopsRepoURLFlag: io.GitOpsRepoURL, gitHostAccessTokenFlag: io.GitHostAccessToken}
 	if err := checkMandatoryFlags(mandatoryFlags); err != nil {
 		return err
@@ -146,7 +154,7 @@ func missingFlagErr(flags []string) error {
 }
 
 // initiateInteractiveMode starts the interactive mode impplementation if no flags are passed.
-func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cmd *cobra.Command) error {
+func initiateInteractiveMode(io *BootstrapParameters, cmd *cobra.Command) error {
 	log.Progressf("\nStarting interactive prompt\n")
 	// Prompt if user wants to use all default values and only be prompted with required or other necessary questions
 	promptForAll := !ui.UseDefaultValues()
@@ -174,8 +182,9 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 				io.DockerConfigJSONFilename = ui.EnterDockercfg()
 			}
 		}
-	} else if promptForAll {
-		if ui.UseInternalRegistry() {
+	} else if promptForAll || io.Platform == config.PlatformKubernetes {
+		// The internal registry is only available on OpenShift.
+		if io.Platform != config.PlatformKubernetes && ui.UseInternalRegistry() {
 			io.ImageRepo = ui.EnterImageRepoInternalRegistry()
 		} else {
 			io.ImageRepo = ui.EnterImageRepoExternalRepository()
@@ -236,7 +245,7 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
@@ -267,30 +276,74 @@ func setAccessToken(io *BootstrapParameters) error {
 	return nil
 }
 
+// bootstrapDependency is an API group that the generated resources need, and
+// how to install it.
+type bootstrapDependency struct {
+	utility.APIDependency
+	install string
+}
+
+// bootstrapDependencies returns the dependencies of the resources generated
+// for the Tekton API version.
+func bootstrapDependencies(tektonAPI string) []bootstrapDependency {
+	pipelinesVersion, triggersVersion := "v1beta1", "v1alpha1"
+	if tektonAPI == config.TektonAPIV1 {
+		pipelinesVersion, triggersVersion = "v1", "v1beta1"
+	}
+	deps := []bootstrapDependency{
+		{
+			APIDependency: utility.APIDependency{Name: argoCDName, Group: "argoproj.io", Resource: "applications", MinVersion: "v1alpha1"},
+			install:       "Please install OpenShift GitOps Operator from OperatorHub, or Argo CD",
+		},
+		{
+			APIDependency: utility.APIDependency{Name: tektonPipelinesName, Group: "tekton.dev", Resource: "pipelines", MinVersion: pipelinesVersion},
+			install:       "Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Pipelines",
+		},
+		{
+			APIDependency: utility.APIDependency{Name: tektonTriggersName, Group: "triggers.tekton.dev", Resource: "eventlisteners", MinVersion: triggersVersion},
+			install:       "Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Triggers",
+		},
+	}
+	if tektonAPI != config.TektonAPIV1 {
+		// The v1beta1 Pipelines use PipelineResources, which later releases of
+		// Tekton Pipelines no longer serve.
+		deps = append(deps, bootstrapDependency{
+			APIDependency: utility.APIDependency{Name: pipelineResourcesName, Group: "tekton.dev", Resource: "pipelineresources", MinVersion: "v1alpha1"},
+			install:       "Please install a release of OpenShift Pipelines that serves PipelineResources, or use --tekton-api v1",
+		})
+	}
+	return deps
+}
+
+// checkBootstrapDependencies discovers the API groups served by the cluster,
+// so that dependencies are found however they were installed, and reports the
+// version of each API group that was found.
 func checkBootstrapDependencies(io *BootstrapParameters, client *utility.Client, spinner utility.Status) error {
 	missingDeps := []string{}
 	log.Progressf("\nChecking dependencies\n")
 
-	spinner.Start("Checking if Argo CD is installed with the default configuration", false)
-	if err := client.CheckIfArgoCDExists(argocd.ArgoCDNamespace); err != nil {
-		warnIfNotFound(spinner, "Please install OpenShift GitOps Operator from OperatorHub", err)
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for OpenShift GitOps Operator: %w", err)
+	for _, d := range bootstrapDependencies(io.TektonAPI) {
+		spinner.Start(fmt.Sprintf("Checking if %s is installed", d.Name), false)
+		version, err := client.CheckAPIDependency(d.APIDependency)
+		var versionErr *utility.UnsupportedVersionError
+		switch {
+		case err == nil:
+			spinner.Start(fmt.Sprintf("Found %s %s/%s", d.Name, d.Group, version), false)
+			spinner.End(true)
+		case errors.As(err, &versionErr):
+			spinner.WarningStatus(fmt.Sprintf("%s. %s", versionErr, d.install))
+			spinner.End(false)
+			missingDeps = append(missingDeps, d.Name)
+		case apierrors.IsNotFound(err):
+			warnIfNotFound(spinner, d.install, err)
+			missingDeps = append(missingDeps, d.Name)
+		default:
+			spinner.End(false)
+			return fmt.Errorf("failed to check for %s: %w", d.Name, err)
 		}
-		missingDeps = append(missingDeps, gitopsOperatorName)
 	}
-
-	spinner.Start("Checking if OpenShift Pipelines Operator is installed with the default configuration", false)
-	if err := client.CheckIfPipelinesExists(pipelinesOperatorNS); err != nil {
-		warnIfNotFound(spinner, "Please install OpenShift Pipelines Operator from OperatorHub", err)
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for OpenShift Pipelines Operator: %w", err)
-		}
-		missingDeps = append(missingDeps, pipelinesOperatorName)
-	}
-	spinner.End(true)
 	if len(missingDeps) > 0 {
-		return fmt.Errorf("failed to satisfy the required dependencies: %s", strings.Join(missingDeps, ", "))
+		return fmt.Errorf("failed to satisfy the required dependencies: %s, use --skip-dependency-checks to bootstrap without checking", strings.Join(missingDeps, ", "))
 	}
 	return nil
 }
@@ -304,13 +357,19 @@ func warnIfNotFound(spinner utility.Status, warningMsg string, err error) {
 
 // Validate validates the parameters of the BootstrapParameters.
 func (io *BootstrapParameters) Validate() error {
-	gr, err := url.Parse(io.GitOpsRepoURL)
+	gr, err := giturl.Parse(io.GitOpsRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse url %s: %w", io.GitOpsRepoURL, err)
 	}
 
-	// TODO: this may not work with GitLab as the repo can have more path elements.
-	if len(utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))) != 2 {
+	// GitLab projects can be in nested subgroups e.g. group/subgroup/repo,
+	// Gitea can be hosted with a sub-path and Bitbucket Server URLs have an
+	// /scm/ prefix, GitHub repositories are always org/repo.
+	elements := len(utility.RemoveEmptyStrings(strings.Split(gr.Path, "/")))
+	if elements < 2 {
+		return fmt.Errorf("repo must be org/repo or group/subgroup/repo: %s", strings.Trim(gr.Path, ".git"))
+	}
+	if elements > 2 && gitOpsDriver(io.BootstrapOptions) == "github" {
 		return fmt.Errorf("repo must be org/repo: %s", strings.Trim(gr.Path, ".git"))
 	}
 
@@ -322,10 +381,32 @@ func (io *BootstrapParameters) Validate() error {
 	if io.SaveTokenKeyRing && io.GitHostAccessToken == "" {
 		return errors.New("--git-host-access-token is required if --save-token-keyring is enabled")
 	}
+	if err := validateSecretsMode(io.BootstrapOptions); err != nil {
+		return err
+	}
+	if err := validateTektonAPI(io.TektonAPI); err != nil {
+		return err
+	}
+	if err := validatePlatform(io.BootstrapOptions); err != nil {
+		return err
+	}
 	io.Prefix = utility.MaybeCompletePrefix(io.Prefix)
 	return nil
 }
 
+// gitOpsDriver returns the driver for the GitOps repository, either the
+// explicitly configured driver, or the driver identified from the host.
+func gitOpsDriver(o *pipelines.BootstrapOptions) string {
+	if o.PrivateRepoDriver != "" {
+		return o.PrivateRepoDriver
+	}
+	driver, err := scm.GetDriverName(o.GitOpsRepoURL)
+	if err != nil {
+		return ""
+	}
+	return driver
+}
+
 // Run runs the project Bootstrap command.
 func (io *BootstrapParameters) Run() error {
 	log.Progressf("\nCompleting Bootstrap process\n")
@@ -341,7 +422,7 @@ func (io *BootstrapParameters) Run() error {
 		}
 		log.Successf("Created repository")
 	}
-	nextSteps()
+	nextSteps(io.SealedSecretsCert != "" || io.SecretsMode == config.SecretsModeExternal)
 	return nil
 }
 
@@ -358,7 +439,7 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 			genericclioptions.GenericRun(o, cmd, args)
 		},
 	}
-	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git or git@github.com:organisation/repository.git")
 	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().StringVar(&o.OutputPath, "output", "./gitops", "Path to write GitOps resources")
 	bootstrapCmd.Flags().StringVarP(&o.Prefix, "prefix", "p", "", "Add a prefix to the environment names(Dev, stage,prod,cicd etc.) to distinguish and identify individual environments")
@@ -366,23 +447,80 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 	bootstrapCmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image repository of the form <registry>/<username>/<repository> or <project>/<app> which is used to push newly built images")
 	bootstrapCmd.Flags().StringVar(&o.GitHostAccessToken, "git-host-access-token", "", "Used to authenticate repository clones. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
 	bootstrapCmd.Flags().BoolVar(&o.Overwrite, "overwrite", false, "Overwrites previously existing GitOps configuration (if any) on the local filesystem")
-	bootstrapCmd.Flags().StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git")
+	bootstrapCmd.Flags().StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git or git@github.com:organisation/service.git")
 	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().BoolVar(&o.SaveTokenKeyRing, "save-token-keyring", false, "Explicitly pass this flag to update the git-host-access-token in the keyring on your local machine")
-	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github or gitlab")
+	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github, gitlab, gitea or bitbucketserver")
 	bootstrapCmd.Flags().BoolVar(&o.PushToGit, "push-to-git", false, "If true, automatically creates and populates the gitops-repo-url with the generated resources")
+	bootstrapCmd.Flags().BoolVar(&o.SkipDependencyChecks, "skip-dependency-checks", false, "Skip checking the cluster for Argo CD, Tekton Pipelines and Tekton Triggers, this allows bootstrapping without access to a cluster")
 	bootstrapCmd.Flags().BoolVar(&o.Interactive, "interactive", false, "If true, enable prompting for most options if not already specified on the command line")
+	bootstrapCmd.Flags().StringVar(&o.SealedSecretsCert, "sealed-secrets-cert", "", "Path to the Sealed Secrets controller certificate (kubeseal --fetch-cert), if provided, generated secrets are sealed and written to the GitOps repository, and the path is recorded in the manifest")
+	bootstrapCmd.Flags().StringVar(&o.SecretsMode, "secrets-mode", "", "How generated secrets are written: raw, sealed or external (defaults to sealed if --sealed-secrets-cert is provided, otherwise raw)")
+	bootstrapCmd.Flags().StringVar(&o.SecretStoreName, "secret-store", "", "Name of the SecretStore that ExternalSecrets read values from, required with --secrets-mode=external")
+	bootstrapCmd.Flags().StringVar(&o.SecretStoreKind, "secret-store-kind", "SecretStore", "Kind of the secret store, SecretStore or ClusterSecretStore")
+	bootstrapCmd.Flags().StringVar(&o.SecretsKeyPath, "secrets-key-path", "", "Path in the external secret store under which secret values are read e.g. secret/kam")
+	bootstrapCmd.Flags().StringVar(&o.Platform, "platform", "", "Platform to generate resources for, openshift or kubernetes (defaults to openshift), on kubernetes services and the EventListener are exposed with Ingresses instead of Routes")
+	bootstrapCmd.Flags().StringVar(&o.IngressClass, "ingress-class", "", "IngressClass of the generated Ingresses, used with --platform=kubernetes")
+	bootstrapCmd.Flags().StringVar(&o.IngressHost, "ingress-host", "", "Template for the hosts of the generated Ingresses e.g. {{.Name}}-{{.Namespace}}.apps.example.com, used with --platform=kubernetes")
+	bootstrapCmd.Flags().StringVar(&o.TektonAPI, "tekton-api", "", "Version of the Tekton APIs to generate resources for, v1beta1 or v1 (defaults to v1beta1)")
 	return bootstrapCmd
 }
 
-func nextSteps() {
+func nextSteps(sealed bool) {
 	log.Success("Bootstrapped OpenShift resources successfully\n\n",
 		"Next Steps:\n",
 		"Please refer to https://github.com/redhat-developer/kam/tree/master/docs to get started.\n",
 	)
+	if sealed {
+		return
+	}
 	log.Info(" WARNING: Generated secrets are not encrypted. Deploying the GitOps configuration without encrypting secrets is insecure and is not recommended.\n For more information on secret management see: https://github.com/redhat-developer/kam/tree/master/docs/journey/day1#secrets\n")
 }
 
+func validateSecretsMode(o *pipelines.BootstrapOptions) error {
+	switch o.SecretsMode {
+	case "", config.SecretsModeRaw:
+		if o.SecretsMode != "" && o.SealedSecretsCert != "" {
+			return errors.New("--sealed-secrets-cert can't be used with --secrets-mode=raw")
+		}
+	case config.SecretsModeSealed:
+		if o.SealedSecretsCert == "" {
+			return errors.New("--sealed-secrets-cert is required if --secrets-mode=sealed")
+		}
+	case config.SecretsModeExternal:
+		if o.SecretStoreName == "" {
+			return errors.New("--secret-store is required if --secrets-mode=external")
+		}
+	default:
+		return fmt.Errorf("invalid secrets mode: %q", o.SecretsMode)
+	}
+	return nil
+}
+
+func validateTektonAPI(api string) error {
+	switch api {
+	case "", config.TektonAPIV1Beta1, config.TektonAPIV1:
+		return nil
+	}
+	return fmt.Errorf("invalid Tekton API: %q, must be one of %s or %s", api, config.TektonAPIV1Beta1, config.TektonAPIV1)
+}
+
+func validatePlatform(o *pipelines.BootstrapOptions) error {
+	switch o.Platform {
+	case "", config.PlatformOpenShift:
+		if o.IngressClass != "" || o.IngressHost != "" {
+			return errors.New("--ingress-class and --ingress-host can only be used with --platform=kubernetes")
+		}
+	case config.PlatformKubernetes:
+		if o.ImageRepo == "" {
+			return errors.New("--image-repo is required if --platform=kubernetes")
+		}
+	default:
+		return fmt.Errorf("invalid platform: %q, must be one of %s or %s", o.Platform, config.PlatformOpenShift, config.PlatformKubernetes)
+	}
+	return nil
+}
+
 func isKnownDriver(repoURL string) bool {
 	host, err := accesstoken.HostFromURL(repoURL)
 	if err != nil {
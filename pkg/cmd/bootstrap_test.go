@@ -8,17 +8,13 @@ import (
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/google/go-cmp/cmp"
-	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	operatorsfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines"
-	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
-	appv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -106,8 +102,15 @@ func TestValidateBootstrapParameter(t *testing.T) {
 	}{
 		{"invalid repo", "test", "", "repo must be org/repo"},
 		{"valid repo", "test/repo", "", ""},
+		{"valid nested repo", "https://gitlab.com/group/subgroup/team/repo.git", "gitlab", ""},
+		{"valid nested repo without a driver", "https://gitlab.com/group/subgroup/repo.git", "", ""},
+		{"invalid nested github repo", "https://github.com/org/team/repo.git", "", "repo must be org/repo: "},
+		{"invalid nested repo with github driver", "https://example.com/org/team/repo.git", "github", "repo must be org/repo: "},
+		{"valid gitea repo with sub-path", "https://example.com/gitea/org/repo.git", "gitea", ""},
 		{"invalid driver", "test/repo", "unknown", "invalid"},
 		{"valid driver gitlab", "test/repo", "gitlab", ""},
+		{"valid driver gitea", "test/repo", "gitea", ""},
+		{"valid driver bitbucketserver", "test/repo", "bitbucketserver", ""},
 	}
 
 	for _, tt := range optionTests {
@@ -131,6 +134,77 @@ func TestValidateBootstrapParameter(t *testing.T) {
 	}
 }
 
+func TestValidateSecretsMode(t *testing.T) {
+	optionTests := []struct {
+		name   string
+		opts   pipelines.BootstrapOptions
+		errMsg string
+	}{
+		{"default mode", pipelines.BootstrapOptions{}, ""},
+		{"sealed mode without a cert", pipelines.BootstrapOptions{SecretsMode: "sealed"}, "--sealed-secrets-cert is required"},
+		{"sealed mode with a cert", pipelines.BootstrapOptions{SecretsMode: "sealed", SealedSecretsCert: "cert.pem"}, ""},
+		{"raw mode with a cert", pipelines.BootstrapOptions{SecretsMode: "raw", SealedSecretsCert: "cert.pem"}, "can't be used with --secrets-mode=raw"},
+		{"external mode without a store", pipelines.BootstrapOptions{SecretsMode: "external"}, "--secret-store is required"},
+		{"external mode with a store", pipelines.BootstrapOptions{SecretsMode: "external", SecretStoreName: "vault"}, ""},
+		{"unknown mode", pipelines.BootstrapOptions{SecretsMode: "encrypted"}, "invalid secrets mode"},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			err := validateSecretsMode(&tt.opts)
+			if !matchError(rt, tt.errMsg, err) {
+				rt.Errorf("validateSecretsMode() failed to match error: got %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateTektonAPI(t *testing.T) {
+	optionTests := []struct {
+		api    string
+		errMsg string
+	}{
+		{"", ""},
+		{"v1beta1", ""},
+		{"v1", ""},
+		{"v1alpha1", "invalid Tekton API"},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.api, func(rt *testing.T) {
+			err := validateTektonAPI(tt.api)
+			if !matchError(rt, tt.errMsg, err) {
+				rt.Errorf("validateTektonAPI() failed to match error: got %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidatePlatform(t *testing.T) {
+	optionTests := []struct {
+		name   string
+		opts   pipelines.BootstrapOptions
+		errMsg string
+	}{
+		{"default platform", pipelines.BootstrapOptions{}, ""},
+		{"openshift", pipelines.BootstrapOptions{Platform: "openshift"}, ""},
+		{"openshift with ingress", pipelines.BootstrapOptions{Platform: "openshift", IngressClass: "nginx"}, "--ingress-class and --ingress-host can only be used with --platform=kubernetes"},
+		{"default platform with ingress host", pipelines.BootstrapOptions{IngressHost: "{{.Name}}.example.com"}, "--ingress-class and --ingress-host can only be used with --platform=kubernetes"},
+		{"kubernetes", pipelines.BootstrapOptions{Platform: "kubernetes", ImageRepo: "quay.io/org/app", IngressClass: "nginx"}, ""},
+		{"kubernetes without image repo", pipelines.BootstrapOptions{Platform: "kubernetes"}, "--image-repo is required if --platform=kubernetes"},
+		{"unknown platform", pipelines.BootstrapOptions{Platform: "nomad"}, "invalid platform: \"nomad\""},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			err := validatePlatform(&tt.opts)
+			if !matchError(rt, tt.errMsg, err) {
+				rt.Errorf("validatePlatform() failed to match error: got %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
 func TestCheckSpinner(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -168,29 +242,37 @@ func TestCheckSpinner(t *testing.T) {
 }
 
 func TestDependenciesWithNothingInstalled(t *testing.T) {
-	fakeClient := newFakeClient(nil, nil)
+	fakeClient := newFakeClient()
 
 	wantMsg := `
-Checking if Argo CD is installed with the default configuration [Please install OpenShift GitOps Operator from OperatorHub]
-Checking if OpenShift Pipelines Operator is installed with the default configuration [Please install OpenShift Pipelines Operator from OperatorHub]`
+Checking if Argo CD is installed [Please install OpenShift GitOps Operator from OperatorHub, or Argo CD]
+Checking if Tekton Pipelines is installed [Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Pipelines]
+Checking if Tekton Triggers is installed [Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Triggers]
+Checking if Tekton PipelineResources is installed [Please install a release of OpenShift Pipelines that serves PipelineResources, or use --tekton-api v1]`
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
 	err := checkBootstrapDependencies(
 		&BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}},
 		fakeClient, fakeSpinner)
-	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, %s", gitopsOperatorName, pipelinesOperatorName)
+	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, %s, %s, %s, use --skip-dependency-checks to bootstrap without checking", argoCDName, tektonPipelinesName, tektonTriggersName, pipelineResourcesName)
 
 	assertError(t, err, wantErr)
 	assertMessage(t, buff.String(), wantMsg)
 }
 
 func TestDependenciesWithAllInstalled(t *testing.T) {
-	fakeClient := newFakeClient([]runtime.Object{pipelinesOperator()}, []runtime.Object{argoCDCSV()})
+	fakeClient := newFakeClient(argoCDAPI(), pipelineResourcesAPI(), pipelinesAPI("v1beta1"), triggersAPI("v1alpha1"))
 
 	wantMsg := `
-Checking if Argo CD is installed with the default configuration
-Checking if OpenShift Pipelines Operator is installed with the default configuration`
+Checking if Argo CD is installed
+Found Argo CD argoproj.io/v1alpha1
+Checking if Tekton Pipelines is installed
+Found Tekton Pipelines tekton.dev/v1beta1
+Checking if Tekton Triggers is installed
+Found Tekton Triggers triggers.tekton.dev/v1alpha1
+Checking if Tekton PipelineResources is installed
+Found Tekton PipelineResources tekton.dev/v1alpha1`
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
@@ -199,14 +281,47 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 
 	assertError(t, err, "")
 	assertMessage(t, buff.String(), wantMsg)
+	if !fakeSpinner.end {
+		t.Fatal("the last dependency check failed")
+	}
+}
+
+func TestDependenciesWithNoPipelineResources(t *testing.T) {
+	fakeClient := newFakeClient(argoCDAPI(), pipelinesAPI("v1beta1"), pipelinesAPI("v1"), triggersAPI("v1alpha1"), triggersAPI("v1beta1"))
+
+	wantMsg := `
+Checking if Argo CD is installed
+Found Argo CD argoproj.io/v1alpha1
+Checking if Tekton Pipelines is installed
+Found Tekton Pipelines tekton.dev/v1beta1
+Checking if Tekton Triggers is installed
+Found Tekton Triggers triggers.tekton.dev/v1alpha1
+Checking if Tekton PipelineResources is installed [Please install a release of OpenShift Pipelines that serves PipelineResources, or use --tekton-api v1]`
+
+	buff := &bytes.Buffer{}
+	fakeSpinner := &mockSpinner{writer: buff}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}
+	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
+	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, use --skip-dependency-checks to bootstrap without checking", pipelineResourcesName)
+
+	assertError(t, err, wantErr)
+	assertMessage(t, buff.String(), wantMsg)
+	if fakeSpinner.end {
+		t.Fatal("the failed dependency check was reported as a success")
+	}
 }
 
 func TestDependenciesWithNoArgoCD(t *testing.T) {
-	fakeClient := newFakeClient([]runtime.Object{pipelinesOperator()}, nil)
+	fakeClient := newFakeClient(pipelineResourcesAPI(), pipelinesAPI("v1beta1"), triggersAPI("v1alpha1"))
 
 	wantMsg := `
-Checking if Argo CD is installed with the default configuration [Please install OpenShift GitOps Operator from OperatorHub]
-Checking if OpenShift Pipelines Operator is installed with the default configuration`
+Checking if Argo CD is installed [Please install OpenShift GitOps Operator from OperatorHub, or Argo CD]
+Checking if Tekton Pipelines is installed
+Found Tekton Pipelines tekton.dev/v1beta1
+Checking if Tekton Triggers is installed
+Found Tekton Triggers triggers.tekton.dev/v1alpha1
+Checking if Tekton PipelineResources is installed
+Found Tekton PipelineResources tekton.dev/v1alpha1`
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
@@ -214,27 +329,72 @@ Checking if OpenShift Pipelines Operator is installed with the default configura
 		BootstrapOptions: &pipelines.BootstrapOptions{},
 	}
 	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
-	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s", gitopsOperatorName)
+	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, use --skip-dependency-checks to bootstrap without checking", argoCDName)
 
 	assertError(t, err, wantErr)
 	assertMessage(t, buff.String(), wantMsg)
 }
 
 func TestDependenciesWithNoPipelines(t *testing.T) {
-	fakeClient := newFakeClient([]runtime.Object{}, []runtime.Object{argoCDCSV()})
+	fakeClient := newFakeClient(argoCDAPI())
 
 	wantMsg := `
-Checking if Argo CD is installed with the default configuration
-Checking if OpenShift Pipelines Operator is installed with the default configuration [Please install OpenShift Pipelines Operator from OperatorHub]`
+Checking if Argo CD is installed
+Found Argo CD argoproj.io/v1alpha1
+Checking if Tekton Pipelines is installed [Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Pipelines]
+Checking if Tekton Triggers is installed [Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Triggers]
+Checking if Tekton PipelineResources is installed [Please install a release of OpenShift Pipelines that serves PipelineResources, or use --tekton-api v1]`
 
 	buff := &bytes.Buffer{}
 	fakeSpinner := &mockSpinner{writer: buff}
 	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{}}
 	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
-	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s", pipelinesOperatorName)
+	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, %s, %s, use --skip-dependency-checks to bootstrap without checking", tektonPipelinesName, tektonTriggersName, pipelineResourcesName)
+
+	assertError(t, err, wantErr)
+	assertMessage(t, buff.String(), wantMsg)
+}
+
+func TestDependenciesWithOldTektonAPIs(t *testing.T) {
+	fakeClient := newFakeClient(argoCDAPI(), pipelinesAPI("v1beta1"), triggersAPI("v1alpha1"))
+
+	wantMsg := `
+Checking if Argo CD is installed
+Found Argo CD argoproj.io/v1alpha1
+Checking if Tekton Pipelines is installed [tekton.dev/v1 or later is not served, the cluster serves v1beta1. Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Pipelines]
+Checking if Tekton Triggers is installed [triggers.tekton.dev/v1beta1 or later is not served, the cluster serves v1alpha1. Please install OpenShift Pipelines Operator from OperatorHub, or Tekton Triggers]`
+
+	buff := &bytes.Buffer{}
+	fakeSpinner := &mockSpinner{writer: buff}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{TektonAPI: "v1"}}
+	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
+	wantErr := fmt.Sprintf("failed to satisfy the required dependencies: %s, %s, use --skip-dependency-checks to bootstrap without checking", tektonPipelinesName, tektonTriggersName)
 
 	assertError(t, err, wantErr)
 	assertMessage(t, buff.String(), wantMsg)
+	if fakeSpinner.end {
+		t.Fatal("the failed dependency check was reported as a success")
+	}
+}
+
+func TestDependenciesWithUpstreamTektonV1(t *testing.T) {
+	fakeClient := newFakeClient(argoCDAPI(), pipelinesAPI("v1beta1"), pipelinesAPI("v1"), triggersAPI("v1alpha1"), triggersAPI("v1beta1"))
+
+	wantMsg := `
+Checking if Argo CD is installed
+Found Argo CD argoproj.io/v1alpha1
+Checking if Tekton Pipelines is installed
+Found Tekton Pipelines tekton.dev/v1
+Checking if Tekton Triggers is installed
+Found Tekton Triggers triggers.tekton.dev/v1beta1`
+
+	buff := &bytes.Buffer{}
+	fakeSpinner := &mockSpinner{writer: buff}
+	wizardParams := &BootstrapParameters{BootstrapOptions: &pipelines.BootstrapOptions{TektonAPI: "v1"}}
+	err := checkBootstrapDependencies(wizardParams, fakeClient, fakeSpinner)
+
+	assertError(t, err, "")
+	assertMessage(t, buff.String(), wantMsg)
 }
 
 func assertError(t *testing.T, err error, msg string) {
@@ -257,14 +417,6 @@ func assertMessage(t *testing.T, got, want string) {
 	}
 }
 
-func pipelinesOperator() *appv1.Deployment {
-	return &appv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "openshift-pipelines-operator",
-			Namespace: "openshift-operators",
-		},
-	}
-}
 func matchError(t *testing.T, s string, e error) bool {
 	t.Helper()
 	if s == "" && e == nil {
@@ -293,27 +445,37 @@ func (m *mockSpinner) WarningStatus(status string) {
 	fmt.Fprintf(m.writer, " [%s]", status)
 }
 
-func newFakeClient(k8sObjs []runtime.Object, clientObjs []runtime.Object) *utility.Client {
-	return &utility.Client{
-		KubeClient:     fake.NewSimpleClientset(k8sObjs...),
-		OperatorClient: operatorsfake.NewSimpleClientset(clientObjs...).OperatorsV1alpha1(),
+func newFakeClient(resources ...*metav1.APIResourceList) *utility.Client {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = resources
+	return &utility.Client{KubeClient: clientset}
+}
+
+func argoCDAPI() *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "applications"}, {Name: "appprojects"}},
 	}
 }
 
-func argoCDCSV() *v1alpha1.ClusterServiceVersion {
-	return &v1alpha1.ClusterServiceVersion{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd",
-			Namespace: argocd.ArgoCDNamespace,
-		},
-		Spec: v1alpha1.ClusterServiceVersionSpec{
-			CustomResourceDefinitions: v1alpha1.CustomResourceDefinitions{
-				Owned: []v1alpha1.CRDDescription{
-					{Name: "argocds.argoproj.io", Kind: "ArgoCD"},
-					{Name: "fake.crd", Kind: "ArgoCD"},
-				},
-			},
-		},
+func pipelinesAPI(version string) *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "tekton.dev/" + version,
+		APIResources: []metav1.APIResource{{Name: "pipelines"}, {Name: "tasks"}},
+	}
+}
+
+func pipelineResourcesAPI() *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "tekton.dev/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "pipelineresources"}},
+	}
+}
+
+func triggersAPI(version string) *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "triggers.tekton.dev/" + version,
+		APIResources: []metav1.APIResource{{Name: "eventlisteners"}},
 	}
 }
 
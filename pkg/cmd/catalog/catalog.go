@@ -0,0 +1,31 @@
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended catalog command name.
+const RecommendedCommandName = "catalog"
+
+// NewCmd creates a new catalog command
+func NewCmd(name, fullName string) *cobra.Command {
+	updateCmd := newCmdUpdate(updateRecommendedCommandName, utility.GetFullName(fullName, updateRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage the Tekton catalog Tasks",
+		Long:  "Manage the Tasks from the Tekton catalog that are written to the CI/CD environment",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, updateRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(updateCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
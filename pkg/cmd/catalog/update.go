@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/catalog"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const updateRecommendedCommandName = "update"
+
+var (
+	updateExample = ktemplates.Examples(`	# Update all the catalog Tasks in the CI/CD environment to the pinned versions
+	%[1]s
+
+	# Update the git-clone and buildah Tasks
+	%[1]s --task git-clone --task buildah`)
+)
+
+// UpdateOptions encapsulates the parameters for the catalog update command.
+type UpdateOptions struct {
+	*pipelines.UpdateCatalogTasksOptions
+}
+
+// Complete is called when the command is completed
+func (o *UpdateOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the UpdateOptions.
+func (o *UpdateOptions) Validate() error {
+	for _, name := range o.Tasks {
+		if catalog.PinnedVersion(name) == "" {
+			return fmt.Errorf("task %s is not in the catalog, the catalog Tasks are %v", name, catalog.Names())
+		}
+	}
+	return nil
+}
+
+// Run runs the catalog update command.
+func (o *UpdateOptions) Run() error {
+	updates, err := pipelines.UpdateCatalogTasks(o.UpdateCatalogTasksOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return fmt.Errorf("failed to update the catalog Tasks: %w", err)
+	}
+	if len(updates) == 0 {
+		log.Success("The catalog Tasks are already at the pinned versions")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "TASK\tFROM\tTO")
+	fmt.Fprintln(w, "====\t====\t==")
+	for _, u := range updates {
+		from := u.FromVersion
+		if from == "" {
+			from = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", u.Name, from, u.ToVersion)
+	}
+	w.Flush()
+	log.Success("Updated the catalog Tasks")
+	return nil
+}
+
+func newCmdUpdate(name, fullName string) *cobra.Command {
+	o := &UpdateOptions{UpdateCatalogTasksOptions: &pipelines.UpdateCatalogTasksOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   "Update the catalog Tasks to the pinned versions",
+		Long:    "Record the versions of the Tekton catalog Tasks that are pinned by this release in the manifest, and rewrite the Tasks in the CI/CD environment",
+		Example: fmt.Sprintf(updateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().StringArrayVar(&o.Tasks, "task", nil, "Name of a catalog Task to update, this can be repeated (defaults to the Tasks recorded in the manifest)")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestValidateForUpdate(t *testing.T) {
+	testcases := []struct {
+		tasks  []string
+		errMsg string
+	}{
+		{nil, ""},
+		{[]string{"git-clone", "kaniko"}, ""},
+		{[]string{"golang-test"}, "task golang-test is not in the catalog"},
+	}
+
+	for i, tt := range testcases {
+		o := &UpdateOptions{UpdateCatalogTasksOptions: &pipelines.UpdateCatalogTasksOptions{Tasks: tt.tasks}}
+		err := o.Validate()
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() #%d failed to match error: got %v, want %s", i, err, tt.errMsg)
+		}
+	}
+}
+
+func matchError(t *testing.T, s string, e error) bool {
+	t.Helper()
+	if s == "" && e == nil {
+		return true
+	}
+	if s != "" && e == nil {
+		return false
+	}
+	match, err := regexp.MatchString(s, e.Error())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return match
+}
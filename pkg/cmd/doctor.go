@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	// DoctorRecommendedCommandName the recommended command name
+	DoctorRecommendedCommandName = "doctor"
+)
+
+var (
+	doctorExample = ktemplates.Examples(`
+	# Check the cluster against the manifest in the current folder
+	%[1]s
+
+	# Check the cluster and output the report as JSON
+	%[1]s --pipelines-folder ./gitops --output json
+	`)
+
+	doctorLongDesc  = ktemplates.LongDesc(`Check that the resources in the cluster match the manifest: the namespaces of the environments, the secrets of the pipeline ServiceAccount, the webhook secrets, the EventListener route, the Tasks used by the pipelines and the Argo CD Applications. Failed checks have a hint to fix them.`)
+	doctorShortDesc = `Check the cluster against the manifest`
+)
+
+// DoctorParameters encapsulates the parameters for the kam doctor command.
+type DoctorParameters struct {
+	pipelinesFolderPath string
+	output              string // the format of the report, "table" or "json"
+}
+
+// NewDoctorParameters bootstraps a DoctorParameters instance.
+func NewDoctorParameters() *DoctorParameters {
+	return &DoctorParameters{}
+}
+
+// Complete completes DoctorParameters after they've been created.
+func (io *DoctorParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the DoctorParameters.
+func (io *DoctorParameters) Validate() error {
+	if io.output != "table" && io.output != "json" {
+		return fmt.Errorf("invalid output format: %q, must be one of table or json", io.output)
+	}
+	return nil
+}
+
+// Run runs the doctor command, it fails if any of the checks failed.
+func (io *DoctorParameters) Run() error {
+	clients, err := pipelines.NewDoctorClients()
+	if err != nil {
+		return fmt.Errorf("failed to create the clients for the cluster: %w", err)
+	}
+	report, err := pipelines.Doctor(&pipelines.DoctorOptions{PipelinesFolderPath: io.pipelinesFolderPath}, ioutils.NewFilesystem(), clients)
+	if err != nil {
+		return err
+	}
+	machineOutput := io.output == "json" || log.IsJSON()
+	if machineOutput {
+		genericclioptions.OutputSuccess(report)
+	} else {
+		printReport(os.Stdout, report)
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(report.Checks))
+	}
+	if !machineOutput {
+		log.Success("All checks passed")
+	}
+	return nil
+}
+
+func printReport(out io.Writer, report *pipelines.DoctorReport) {
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "STATUS\tCHECK\tMESSAGE")
+	fmt.Fprintln(w, "======\t=====\t=======")
+	for _, c := range report.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Status, c.Name, c.Message)
+	}
+	w.Flush()
+
+	for _, c := range report.Checks {
+		if c.Status == pipelines.CheckFail {
+			fmt.Fprintf(out, "\n%s: %s\n", c.Name, c.Remediation)
+		}
+	}
+}
+
+// NewCmdDoctor creates the doctor command.
+func NewCmdDoctor(name, fullName string) *cobra.Command {
+	o := NewDoctorParameters()
+	doctorCmd := &cobra.Command{
+		Use:     name,
+		Short:   doctorShortDesc,
+		Long:    doctorLongDesc,
+		Example: fmt.Sprintf(doctorExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	doctorCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	doctorCmd.Flags().StringVarP(&o.output, "output", "o", "table", "Format of the report, table or json")
+	return doctorCmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestDoctorValidate(t *testing.T) {
+	optionTests := []struct {
+		output string
+		errMsg string
+	}{
+		{"table", ""},
+		{"json", ""},
+		{"yaml", `invalid output format: "yaml"`},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.output, func(rt *testing.T) {
+			err := (&DoctorParameters{output: tt.output}).Validate()
+			if !matchError(rt, tt.errMsg, err) {
+				rt.Errorf("Validate() failed to match error: got %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	report := &pipelines.DoctorReport{
+		Checks: []*pipelines.Check{
+			{Name: "namespace cicd", Status: pipelines.CheckPass, Message: "exists"},
+			{Name: "namespace dev", Status: pipelines.CheckFail, Message: `namespaces "dev" not found`, Remediation: "Sync the environment with Argo CD"},
+		},
+	}
+	buff := &bytes.Buffer{}
+
+	printReport(buff, report)
+
+	wantMsg := `STATUS   CHECK            MESSAGE
+======   =====            =======
+pass     namespace cicd   exists
+fail     namespace dev    namespaces "dev" not found
+
+namespace dev: Sync the environment with Argo CD
+`
+	assertMessage(t, buff.String(), wantMsg)
+}
@@ -14,18 +14,20 @@ const EnvRecommendedCommandName = "environment"
 func NewCmdEnv(name, fullName string) *cobra.Command {
 
 	addEnvCmd := NewCmdAddEnv(AddEnvRecommendedCommandName, utility.GetFullName(fullName, AddEnvRecommendedCommandName))
+	removeEnvCmd := NewCmdRemoveEnv(RemoveEnvRecommendedCommandName, utility.GetFullName(fullName, RemoveEnvRecommendedCommandName))
 
 	var envCmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage an environment in GitOps",
-		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
-			fullName, AddEnvRecommendedCommandName),
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, AddEnvRecommendedCommandName, RemoveEnvRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
 
 	envCmd.Flags().AddFlagSet(addEnvCmd.Flags())
 	envCmd.AddCommand(addEnvCmd)
+	envCmd.AddCommand(removeEnvCmd)
 
 	envCmd.Annotations = map[string]string{"command": "main"}
 	return envCmd
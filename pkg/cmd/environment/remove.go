@@ -0,0 +1,107 @@
+package environment
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+const (
+	// RemoveEnvRecommendedCommandName the recommended command name
+	RemoveEnvRecommendedCommandName = "remove"
+)
+
+var (
+	removeEnvExample = ktemplates.Examples(`
+	# Remove an environment from GitOps
+	%[1]s --env-name stage
+	`)
+
+	removeEnvLongDesc  = ktemplates.LongDesc(`Remove an environment, and all of its applications and services, from the GitOps repository`)
+	removeEnvShortDesc = `Remove an environment`
+)
+
+// RemoveEnvParameters encapsulates the parameters for the kam environment remove command.
+type RemoveEnvParameters struct {
+	envName         string
+	pipelinesFolder string
+	deleteWebhooks  bool
+	accessToken     string
+}
+
+// NewRemoveEnvParameters bootstraps a RemoveEnvParameters instance.
+func NewRemoveEnvParameters() *RemoveEnvParameters {
+	return &RemoveEnvParameters{}
+}
+
+// Complete completes RemoveEnvParameters after they've been created.
+func (eo *RemoveEnvParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RemoveEnvParameters.
+func (eo *RemoveEnvParameters) Validate() error {
+	return nil
+}
+
+// Run runs the environment remove command.
+//
+// The webhooks are found before the environment is removed from the manifest,
+// and deleted once it has been removed, so that they're left in place if the
+// environment can't be removed.
+func (eo *RemoveEnvParameters) Run() error {
+	var deleteHooks func() ([]string, error)
+	if eo.deleteWebhooks {
+		var err error
+		deleteHooks, err = webhook.PrepareDeleteEnvironment(eo.accessToken, eo.pipelinesFolder, eo.envName)
+		if err != nil {
+			return fmt.Errorf("unable to find webhooks: %v", err)
+		}
+	}
+	options := pipelines.RemoveEnvOptions{
+		EnvName:             eo.envName,
+		PipelinesFolderPath: eo.pipelinesFolder,
+	}
+	err := pipelines.RemoveEnv(&options, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Successf("Removed Environment %s successfully.", eo.envName)
+	if deleteHooks != nil {
+		ids, err := deleteHooks()
+		if err != nil {
+			return fmt.Errorf("unable to delete webhooks: %v", err)
+		}
+		log.Successf("Deleted webhooks %v", ids)
+	}
+	return nil
+}
+
+// NewCmdRemoveEnv creates the project remove environment command.
+func NewCmdRemoveEnv(name, fullName string) *cobra.Command {
+	o := NewRemoveEnvParameters()
+
+	removeEnvCmd := &cobra.Command{
+		Use:     name,
+		Short:   removeEnvShortDesc,
+		Long:    removeEnvLongDesc,
+		Example: fmt.Sprintf(removeEnvExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	removeEnvCmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment/namespace")
+	_ = removeEnvCmd.MarkFlagRequired("env-name")
+	removeEnvCmd.Flags().StringVar(&o.pipelinesFolder, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	removeEnvCmd.Flags().BoolVar(&o.deleteWebhooks, "delete-webhooks", false, "If true, the webhooks for the source repositories of the environment's services are deleted from the Git host, unless other services use the repositories")
+	removeEnvCmd.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used to delete the Git repository webhooks. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
+	return removeEnvCmd
+}
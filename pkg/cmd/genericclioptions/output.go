@@ -0,0 +1,20 @@
+package genericclioptions
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+)
+
+// OutputSuccess outputs a "successful" machine-readable output format in json
+func OutputSuccess(machineOutput interface{}) {
+	printableOutput, err := json.MarshalIndent(machineOutput, "", "	")
+
+	// If we error out... there's no way to output it (since we disable logging when using -o json)
+	if err != nil {
+		fmt.Fprintf(log.GetStderr(), "unable to unmarshal JSON: %s\n", err.Error())
+	} else {
+		fmt.Fprintf(log.GetStdout(), "%s\n", string(printableOutput))
+	}
+}
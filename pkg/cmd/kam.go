@@ -3,7 +3,10 @@ package cmd
 import (
 	"log"
 
+	"github.com/redhat-developer/kam/pkg/cmd/app"
+	"github.com/redhat-developer/kam/pkg/cmd/catalog"
 	"github.com/redhat-developer/kam/pkg/cmd/environment"
+	"github.com/redhat-developer/kam/pkg/cmd/secrets"
 	"github.com/redhat-developer/kam/pkg/cmd/service"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/cmd/version"
@@ -29,10 +32,14 @@ func MakeRootCmd() *cobra.Command {
 	rootCmd.AddCommand(
 		NewCmdBootstrap(BootstrapRecommendedCommandName, utility.GetFullName(fullName, BootstrapRecommendedCommandName)),
 		environment.NewCmdEnv(environment.EnvRecommendedCommandName, utility.GetFullName(fullName, environment.EnvRecommendedCommandName)),
+		app.NewCmd(app.RecommendedCommandName, utility.GetFullName(fullName, app.RecommendedCommandName)),
 		service.NewCmd(service.RecommendedCommandName, utility.GetFullName(fullName, service.RecommendedCommandName)),
+		secrets.NewCmd(secrets.RecommendedCommandName, utility.GetFullName(fullName, secrets.RecommendedCommandName)),
+		catalog.NewCmd(catalog.RecommendedCommandName, utility.GetFullName(fullName, catalog.RecommendedCommandName)),
 		version.NewCmd(version.RecommendedCommandName, utility.GetFullName(fullName, version.RecommendedCommandName)),
 		webhook.NewCmdWebhook(webhook.RecommendedCommandName, utility.GetFullName(fullName, webhook.RecommendedCommandName)),
 		NewCmdBuild(BuildRecommendedCommandName, utility.GetFullName(fullName, BuildRecommendedCommandName)),
+		NewCmdDoctor(DoctorRecommendedCommandName, utility.GetFullName(fullName, DoctorRecommendedCommandName)),
 		completionCmd,
 	)
 	return rootCmd
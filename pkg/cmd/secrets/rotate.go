@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+const rotateRecommendedCommandName = "rotate"
+
+var (
+	rotateExample = ktemplates.Examples(`	# Rotate the webhook secret for the GitOps repository
+	%[1]s --cicd
+
+	# Rotate the webhook secret for a service's source repository
+	%[1]s --env-name dev --service-name taxi`)
+)
+
+// RotateOptions encapsulates the parameters for the secrets rotate command.
+type RotateOptions struct {
+	*pipelines.RotateSecretOptions
+	accessToken string
+}
+
+// Complete is called when the command is completed
+func (o *RotateOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RotateOptions.
+func (o *RotateOptions) Validate() error {
+	if o.IsCICD {
+		if o.ServiceName != "" || o.EnvName != "" {
+			return errors.New("only one of 'cicd' or 'env-name/service-name' can be specified")
+		}
+		return nil
+	}
+	if o.ServiceName == "" || o.EnvName == "" {
+		return errors.New("one of 'cicd' or 'env-name/service-name' must be specified")
+	}
+	return nil
+}
+
+// Run runs the secrets rotate command.
+//
+// The webhooks are updated before the rotated secret is written, if the
+// webhooks can't be updated, the existing secret is left in place, unless a
+// webhook was already given the rotated secret.
+func (o *RotateOptions) Run() error {
+	appFs := ioutils.NewFilesystem()
+	rotated, err := pipelines.RotateWebhookSecret(o.RotateSecretOptions, appFs)
+	if err != nil {
+		return fmt.Errorf("failed to rotate the webhook secret: %w", err)
+	}
+
+	serviceName := &webhook.QualifiedServiceName{EnvironmentName: o.EnvName, ServiceName: o.ServiceName}
+	rotation, hookErr := webhook.Rotate(o.accessToken, o.PipelinesFolderPath, serviceName, o.IsCICD, rotated.Value)
+	if hookErr != nil && (rotation == nil || !rotation.Changed()) {
+		return fmt.Errorf("failed to update the webhooks with the rotated secret, the webhook secret was not changed: %w", hookErr)
+	}
+	if err := rotated.Write(appFs); err != nil {
+		return fmt.Errorf("failed to write the rotated webhook secret: %w", err)
+	}
+	log.Success("Rotated the webhook secret")
+	if hookErr != nil {
+		if rotation.Created != "" {
+			return fmt.Errorf("failed to delete the replaced webhooks: %w", hookErr)
+		}
+		return fmt.Errorf("failed to update all the webhooks, updated %v: %w", rotation.Updated, hookErr)
+	}
+	if !rotation.Changed() {
+		log.Warning("No webhooks were found for the repository, create one with \"kam webhook create\"")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	if rotation.Created != "" {
+		fmt.Fprintln(w, "REPLACED ID\tNEW ID")
+		fmt.Fprintln(w, "===========\t======")
+		for _, id := range rotation.Replaced {
+			fmt.Fprintf(w, "%s\t%s\n", id, rotation.Created)
+		}
+	} else {
+		fmt.Fprintln(w, "UPDATED ID")
+		fmt.Fprintln(w, "==========")
+		for _, id := range rotation.Updated {
+			fmt.Fprintln(w, id)
+		}
+	}
+	w.Flush()
+	log.Info("Commit the rotated secret, and apply it to the cluster, the webhooks will fail to authenticate until it is applied")
+	return nil
+}
+
+func newCmdRotate(name, fullName string) *cobra.Command {
+	o := &RotateOptions{RotateSecretOptions: &pipelines.RotateSecretOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   "Rotate a webhook secret",
+		Long:    "Regenerate a webhook secret, rewrite the secret file, and update the matching webhooks on the Git host",
+		Example: fmt.Sprintf(rotateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().BoolVar(&o.IsCICD, "cicd", false, "Provide this flag to rotate the webhook secret for the GitOps repository")
+	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Provide service name to rotate the webhook secret for a service's source repository")
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Provide environment name to rotate the webhook secret for a service's source repository")
+	cmd.Flags().StringVar(&o.WebhookSecret, "webhook-secret", "", "The new webhook secret (if not provided, it will be auto-generated), this is required when the secrets mode is external")
+	cmd.Flags().StringVar(&o.SealedSecretsCert, "sealed-secrets-cert", "", "Path to the Sealed Secrets controller certificate (kubeseal --fetch-cert), defaults to the certificate recorded in the manifest")
+	cmd.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used to update the Git repository webhooks. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
+	return cmd
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestValidateForRotate(t *testing.T) {
+	testcases := []struct {
+		options *pipelines.RotateSecretOptions
+		errMsg  string
+	}{
+		{&pipelines.RotateSecretOptions{IsCICD: true}, ""},
+		{&pipelines.RotateSecretOptions{IsCICD: true, ServiceName: "foo"}, "only one of 'cicd' or 'env-name/service-name' can be specified"},
+		{&pipelines.RotateSecretOptions{IsCICD: true, EnvName: "foo"}, "only one of 'cicd' or 'env-name/service-name' can be specified"},
+		{&pipelines.RotateSecretOptions{ServiceName: "foo"}, "one of 'cicd' or 'env-name/service-name' must be specified"},
+		{&pipelines.RotateSecretOptions{EnvName: "foo"}, "one of 'cicd' or 'env-name/service-name' must be specified"},
+		{&pipelines.RotateSecretOptions{EnvName: "foo", ServiceName: "bar"}, ""},
+	}
+
+	for i, tt := range testcases {
+		o := &RotateOptions{RotateSecretOptions: tt.options}
+		err := o.Validate()
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() #%d failed to match error: got %v, want %s", i, err, tt.errMsg)
+		}
+	}
+}
+
+func matchError(t *testing.T, s string, e error) bool {
+	t.Helper()
+	if s == "" && e == nil {
+		return true
+	}
+	if s != "" && e == nil {
+		return false
+	}
+	match, err := regexp.MatchString(s, e.Error())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return match
+}
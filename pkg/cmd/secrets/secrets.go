@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended secrets command name.
+const RecommendedCommandName = "secrets"
+
+// NewCmd creates a new secrets command
+func NewCmd(name, fullName string) *cobra.Command {
+	rotateCmd := newCmdRotate(rotateRecommendedCommandName, utility.GetFullName(fullName, rotateRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage generated secrets",
+		Long:  "Manage the secrets generated for the GitOps configuration",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, rotateRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(rotateCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
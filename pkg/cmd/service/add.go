@@ -9,6 +9,7 @@ import (
 
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/cobra"
 
@@ -45,13 +46,20 @@ func (o *AddServiceOptions) Validate() error {
 
 // Run runs the project bootstrap command.
 func (o *AddServiceOptions) Run() error {
-	err := pipelines.AddService(o.AddServiceOptions, ioutils.NewFilesystem())
+	appFs := ioutils.NewFilesystem()
+	err := pipelines.AddService(o.AddServiceOptions, appFs)
 
 	if err != nil {
 		return err
 	}
 
 	log.Successf("Created Service %s successfully at environment %s.\n", o.ServiceName, o.EnvName)
+	if o.SealedSecretsCert != "" {
+		return nil
+	}
+	if m, err := config.LoadManifest(appFs, o.PipelinesFolderPath); err == nil && m.GetSecretsConfig().GetMode() != config.SecretsModeRaw {
+		return nil
+	}
 	log.Info(" WARNING: Generated secrets are not encrypted. Deploying the GitOps configuration without encrypting secrets is insecure and is not recommended.\n For more information on secret management see: https://github.com/redhat-developer/kam/tree/master/docs/journey/day1#secrets\n")
 	return nil
 }
@@ -70,11 +78,15 @@ func newCmdAdd(name, fullName string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&o.GitRepoURL, "git-repo-url", "", "Service repository URL e.g. https://github.com/organisation/repository - only needed when you need to rebuild the source image for the environment")
+	cmd.Flags().StringVar(&o.SourcePath, "source-path", "", "Path within the service repository to build the service from e.g. services/api, this allows multiple services to be built from the same repository")
+	cmd.Flags().StringVar(&o.BuildStrategy, "build-strategy", "", "Strategy used to build the service image, one of buildah, s2i, buildpacks or kaniko (default buildah)")
+	cmd.Flags().StringVar(&o.BuilderImage, "builder-image", "", "Builder image used by the s2i and buildpacks build strategies e.g. registry.access.redhat.com/ubi8/nodejs-14")
 	cmd.Flags().StringVar(&o.WebhookSecret, "webhook-secret", "", "Source Git repository webhook secret (if not provided, it will be auto-generated)")
 	cmd.Flags().StringVar(&o.AppName, "app-name", "", "Name of the application where the service will be added")
 	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be added")
 	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service will be added")
 	cmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image registry of the form <registry>/<username>/<image name> or <project>/<app> which is used to push newly built images")
+	cmd.Flags().StringVar(&o.SealedSecretsCert, "sealed-secrets-cert", "", "Path to the Sealed Secrets controller certificate (kubeseal --fetch-cert), if provided, the webhook secret is sealed and written to the GitOps repository, defaults to the certificate recorded in the manifest")
 	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
 
 	// required flags
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+const (
+	removeRecommendedCommandName = "remove"
+)
+
+var (
+	removeExample = ktemplates.Examples(`	Remove a Service from an environment in GitOps
+	%[1]s --env-name dev --service-name taxi`)
+
+	removeLongDesc  = ktemplates.LongDesc(`Remove a Service from an environment, and delete the files generated for it`)
+	removeShortDesc = `Remove a Service from an environment`
+)
+
+// RemoveServiceOptions encapsulates the parameters for service remove command
+type RemoveServiceOptions struct {
+	*pipelines.RemoveServiceOptions
+	deleteWebhook bool
+	accessToken   string
+}
+
+// Complete is called when the command is completed
+func (o *RemoveServiceOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RemoveServiceOptions.
+func (o *RemoveServiceOptions) Validate() error {
+	return nil
+}
+
+// Run runs the service remove command.
+//
+// The webhooks are found before the service is removed from the manifest, and
+// deleted once it has been removed, so that they're left in place if the
+// service can't be removed.
+func (o *RemoveServiceOptions) Run() error {
+	var deleteHooks func() ([]string, error)
+	if o.deleteWebhook {
+		var err error
+		deleteHooks, err = webhook.PrepareDelete(o.accessToken, o.PipelinesFolderPath, &webhook.QualifiedServiceName{EnvironmentName: o.EnvName, ServiceName: o.ServiceName}, false)
+		if err != nil {
+			return fmt.Errorf("unable to find webhooks: %v", err)
+		}
+	}
+	err := pipelines.RemoveService(o.RemoveServiceOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Successf("Removed Service %s successfully from environment %s.\n", o.ServiceName, o.EnvName)
+	if deleteHooks != nil {
+		ids, err := deleteHooks()
+		if err != nil {
+			return fmt.Errorf("unable to delete webhook: %v", err)
+		}
+		log.Successf("Deleted webhooks %v", ids)
+	}
+	return nil
+}
+
+func newCmdRemove(name, fullName string) *cobra.Command {
+	o := &RemoveServiceOptions{RemoveServiceOptions: &pipelines.RemoveServiceOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   removeShortDesc,
+		Long:    removeLongDesc,
+		Example: fmt.Sprintf(removeExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be removed")
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service will be removed from")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().BoolVar(&o.deleteWebhook, "delete-webhook", false, "If true, the webhook for the service's source repository is deleted from the Git host, unless other services use the repository")
+	cmd.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used to delete the Git repository webhook. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("service-name")
+	_ = cmd.MarkFlagRequired("env-name")
+	return cmd
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestRemoveCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing service-name flag",
+			[]keyValuePair{flag("env-name", "test")},
+			`required flag(s) "service-name" not set`},
+		{"Missing env-name flag",
+			[]keyValuePair{flag("service-name", "sample")},
+			`required flag(s) "env-name" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := executeCommand(newCmdRemove("remove", "kam service"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				t.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -14,19 +14,23 @@ const RecommendedCommandName = "service"
 func NewCmd(name, fullName string) *cobra.Command {
 
 	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+	removeCmd := newCmdRemove(removeRecommendedCommandName, utility.GetFullName(fullName, removeRecommendedCommandName))
+	updateImageCmd := newCmdUpdateImage(updateImageRecommendedCommandName, utility.GetFullName(fullName, updateImageRecommendedCommandName))
 
 	var cmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage services in an environment",
 		Long:  "Manage services in a GitOps environment where service source repositories are synchronized",
-		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
-			fullName, addRecommendedCommandName),
+		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName, removeRecommendedCommandName, updateImageRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
 
 	cmd.Flags().AddFlagSet(addCmd.Flags())
 	cmd.AddCommand(addCmd)
+	cmd.AddCommand(removeCmd)
+	cmd.AddCommand(updateImageCmd)
 
 	cmd.Annotations = map[string]string{"command": "main"}
 	return cmd
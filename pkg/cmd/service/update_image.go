@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	updateImageRecommendedCommandName = "update-image"
+)
+
+var (
+	updateImageExample = ktemplates.Examples(`	Update the image for a Service in an environment in GitOps
+	%[1]s --env-name dev --service-name taxi --image quay.io/org/taxi:main-a1b2c3
+
+	Clone the GitOps repository, update the image and open a pull request
+	%[1]s --env-name dev --service-name taxi --image quay.io/org/taxi:main-a1b2c3 --pipelines-folder /tmp/gitops --gitops-repo-url https://github.com/org/gitops.git --pull-request --git-host-access-token <token>`)
+
+	updateImageLongDesc  = ktemplates.LongDesc(`Update the image for a Service in an environment, with a Kustomize image override in the Service's overlay`)
+	updateImageShortDesc = `Update the image for a Service in an environment`
+)
+
+// UpdateImageOptions encapsulates the parameters for service update-image command
+type UpdateImageOptions struct {
+	*pipelines.UpdateImageOptions
+}
+
+// Complete is called when the command is completed
+func (o *UpdateImageOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the UpdateImageOptions.
+func (o *UpdateImageOptions) Validate() error {
+	if o.PullRequest && o.GitOpsRepoURL == "" {
+		return errors.New("--gitops-repo-url is required to open a pull request")
+	}
+	if o.PullRequest && o.GitHostAccessToken == "" {
+		return errors.New("--git-host-access-token is required to open a pull request")
+	}
+	return nil
+}
+
+// Run runs the service update-image command.
+func (o *UpdateImageOptions) Run() error {
+	var err error
+	if o.GitOpsRepoURL != "" {
+		err = pipelines.PromoteImage(o.UpdateImageOptions, factory.FromRepoURL, pipelines.NewCmdExecutor(), ioutils.NewFilesystem())
+	} else {
+		err = pipelines.UpdateImage(o.UpdateImageOptions, ioutils.NewFilesystem())
+	}
+	if err != nil {
+		return err
+	}
+	log.Successf("Updated the image for Service %s in environment %s to %s.\n", o.ServiceName, o.EnvName, o.Image)
+	return nil
+}
+
+func newCmdUpdateImage(name, fullName string) *cobra.Command {
+	o := &UpdateImageOptions{UpdateImageOptions: &pipelines.UpdateImageOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   updateImageShortDesc,
+		Long:    updateImageLongDesc,
+		Example: fmt.Sprintf(updateImageExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to update the image for")
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service is deployed")
+	cmd.Flags().StringVar(&o.Image, "image", "", "Image to deploy e.g. quay.io/org/taxi:main-a1b2c3")
+	cmd.Flags().StringVar(&o.ImageName, "image-name", "", "Name of the image in the service's resources that is replaced (defaults to the name of the image without the tag)")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "If provided, the GitOps repository is cloned into the pipelines folder, and the change is pushed to it")
+	cmd.Flags().StringVar(&o.GitOpsBranch, "gitops-branch", "main", "Branch of the GitOps repository to update")
+	cmd.Flags().BoolVar(&o.PullRequest, "pull-request", false, "If true, a pull request is opened for the change rather than pushing it to the GitOps branch")
+	cmd.Flags().StringVar(&o.GitHostAccessToken, "git-host-access-token", "", "Access token used to open the pull request")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("service-name")
+	_ = cmd.MarkFlagRequired("env-name")
+	_ = cmd.MarkFlagRequired("image")
+	return cmd
+}
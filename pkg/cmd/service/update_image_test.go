@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestUpdateImageCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing image flag",
+			[]keyValuePair{flag("env-name", "test"), flag("service-name", "sample")},
+			`required flag(s) "image" not set`},
+		{"Missing service-name flag",
+			[]keyValuePair{flag("env-name", "test"), flag("image", "quay.io/org/sample:v1")},
+			`required flag(s) "service-name" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := executeCommand(newCmdUpdateImage("update-image", "kam service"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				t.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateImageValidate(t *testing.T) {
+	optionTests := []struct {
+		desc    string
+		options *pipelines.UpdateImageOptions
+		wantErr string
+	}{
+		{"local update", &pipelines.UpdateImageOptions{}, ""},
+		{"pull request without repository", &pipelines.UpdateImageOptions{PullRequest: true, GitHostAccessToken: "token"},
+			"--gitops-repo-url is required to open a pull request"},
+		{"pull request without token", &pipelines.UpdateImageOptions{PullRequest: true, GitOpsRepoURL: "https://github.com/org/gitops.git"},
+			"--git-host-access-token is required to open a pull request"},
+	}
+	for _, tt := range optionTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			o := UpdateImageOptions{UpdateImageOptions: tt.options}
+			err := o.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("got error %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
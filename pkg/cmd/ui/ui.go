@@ -210,7 +210,7 @@ func SelectPrivateRepoDriver() string {
 	var driver string
 	prompt := &survey.Select{
 		Message: "Please select which driver to use for your Git host",
-		Options: []string{"github", "gitlab"},
+		Options: []string{"github", "gitlab", "gitea", "bitbucketserver"},
 	}
 
 	err := survey.AskOne(prompt, &driver, survey.Required)
@@ -3,12 +3,12 @@ package ui
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -90,7 +90,7 @@ func ValidateAccessToken(input interface{}, serviceRepo string) error {
 		if err != nil {
 			return fmt.Errorf("%w. %s", err, "Check that the --private-repo-driver option is provided.")
 		}
-		parsedURL, err := url.Parse(serviceRepo)
+		parsedURL, err := giturl.Parse(serviceRepo)
 		if err != nil {
 			return fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
 		}
@@ -109,7 +109,7 @@ func ValidateAccessToken(input interface{}, serviceRepo string) error {
 
 func validateURL(input interface{}) error {
 	if u, ok := input.(string); ok {
-		p, err := url.Parse(u)
+		p, err := giturl.Parse(u)
 		if err != nil {
 			return fmt.Errorf("invalid URL, err: %v", err)
 		}
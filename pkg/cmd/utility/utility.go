@@ -1,22 +1,18 @@
 package utility
 
 import (
-	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/openshift/odo/pkg/log"
-	operatorsclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/typed/operators/v1alpha1"
 	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
 	"k8s.io/apimachinery/pkg/api/errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 )
 
-const (
-	argocdCRD = "argocds.argoproj.io"
-)
-
 type Status interface {
 	WarningStatus(status string)
 	Start(status string, debug bool)
@@ -59,8 +55,7 @@ func MaybeCompletePrefix(s string) string {
 
 // Client represents a client for K8s
 type Client struct {
-	KubeClient     kubernetes.Interface
-	OperatorClient operatorsclientset.OperatorsV1alpha1Interface
+	KubeClient kubernetes.Interface
 }
 
 // NewClient returns a new client to check dependencies
@@ -73,34 +68,96 @@ func NewClient() (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	operatorClientSet, err := operatorsclientset.NewForConfig(clientConfig)
-	if err != nil {
-		return nil, err
-	}
-	return &Client{KubeClient: clientSet, OperatorClient: operatorClientSet}, nil
+	return &Client{KubeClient: clientSet}, nil
+}
+
+// APIDependency is a dependency that is detected by the API group that it
+// serves, regardless of how it was installed.
+type APIDependency struct {
+	Name  string
+	Group string
+	// Resource is a resource that the API group must serve, some groups are
+	// shared by several projects e.g. Argo CD and Argo Rollouts.
+	Resource string
+	// MinVersion is the oldest version of the API group that the generated
+	// resources can be used with.
+	MinVersion string
+}
+
+// UnsupportedVersionError is returned when a dependency is installed, but
+// only serves versions of its API group that are older than the minimum.
+type UnsupportedVersionError struct {
+	Group      string
+	MinVersion string
+	Served     []string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s/%s or later is not served, the cluster serves %s", e.Group, e.MinVersion, strings.Join(e.Served, ", "))
 }
 
-// CheckIfArgoCDExists checks if ArgoCD operator is installed
-func (c *Client) CheckIfArgoCDExists(ns string) error {
-	csvList, err := c.OperatorClient.ClusterServiceVersions(ns).List(context.Background(), v1.ListOptions{})
+// CheckAPIDependency discovers the API group of the dependency, and returns
+// the version of the group that serves the resource, the preferred version
+// of the cluster is returned if it serves the resource.
+//
+// An UnsupportedVersionError is returned if the cluster only serves versions
+// older than the minimum, and a NotFound error is returned if the group or
+// resource is not served.
+func (c *Client) CheckAPIDependency(d APIDependency) (string, error) {
+	groups, err := c.KubeClient.Discovery().ServerGroups()
 	if err != nil {
-		return err
+		return "", err
+	}
+	preferred := ""
+	served := []string{}
+	for _, g := range groups.Groups {
+		if g.Name != d.Group {
+			continue
+		}
+		preferred = g.PreferredVersion.Version
+		for _, v := range g.Versions {
+			served = append(served, v.Version)
+		}
+	}
+	notFound := errors.NewNotFound(schema.GroupResource{Group: d.Group, Resource: d.Resource}, d.Name)
+	if len(served) == 0 {
+		return "", notFound
 	}
-	for _, csv := range csvList.Items {
-		if csv.OwnsCRD(argocdCRD) {
-			return nil
+	sort.Slice(served, func(i, j int) bool {
+		return k8sversion.CompareKubeAwareVersionStrings(served[i], served[j]) > 0
+	})
+	if k8sversion.CompareKubeAwareVersionStrings(served[0], d.MinVersion) < 0 {
+		return "", &UnsupportedVersionError{Group: d.Group, MinVersion: d.MinVersion, Served: served}
+	}
+	for _, v := range preferredFirst(preferred, served) {
+		if k8sversion.CompareKubeAwareVersionStrings(v, d.MinVersion) < 0 {
+			continue
+		}
+		resources, err := c.KubeClient.Discovery().ServerResourcesForGroupVersion(d.Group + "/" + v)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range resources.APIResources {
+			if r.Name == d.Resource {
+				return v, nil
+			}
 		}
 	}
-	return errors.NewNotFound(schema.GroupResource{Group: "argoproj.io", Resource: "argocd"}, argocdCRD)
+	return "", notFound
 }
 
-// CheckIfPipelinesExists checks is OpenShift pipelines operator is installed
-func (c *Client) CheckIfPipelinesExists(ns string) error {
-	_, err := c.KubeClient.AppsV1().Deployments(ns).Get(context.Background(), "openshift-pipelines-operator", v1.GetOptions{})
-	if err != nil {
-		return err
+// preferredFirst returns the versions with the preferred version moved to the
+// front.
+func preferredFirst(preferred string, versions []string) []string {
+	ordered := []string{}
+	for _, v := range versions {
+		if v == preferred {
+			ordered = append([]string{v}, ordered...)
+			continue
+		}
+		ordered = append(ordered, v)
 	}
-	return nil
+	return ordered
 }
 
 // GetFullName generates a command's full name based on its parent's full name and its own name
@@ -1,13 +1,12 @@
 package utility
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	operatorsfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
-	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -79,52 +78,106 @@ func TestMaybeCompletePrefix(t *testing.T) {
 	}
 }
 
-func TestCheckIfArgoCDExists(t *testing.T) {
-	operatorClient := operatorsfake.NewSimpleClientset(&v1alpha1.ClusterServiceVersion{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd",
-			Namespace: "argocd",
+func TestCheckAPIDependency(t *testing.T) {
+	pipelines := APIDependency{Name: "Tekton Pipelines", Group: "tekton.dev", Resource: "pipelines", MinVersion: "v1beta1"}
+	dependencyTests := []struct {
+		name        string
+		resources   []*metav1.APIResourceList
+		wantVersion string
+		wantErr     string
+	}{
+		{
+			"group not served",
+			nil,
+			"",
+			`Tekton Pipelines" not found`,
+		},
+		{
+			"required version served",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1alpha1", "pipelines"), apiResources("tekton.dev/v1beta1", "pipelines")},
+			"v1beta1",
+			"",
+		},
+		{
+			"later version also served",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1beta1", "pipelines"), apiResources("tekton.dev/v1", "pipelines")},
+			"v1beta1",
+			"",
+		},
+		{
+			"later version preferred",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1", "pipelines"), apiResources("tekton.dev/v1beta1", "pipelines")},
+			"v1",
+			"",
+		},
+		{
+			"older version served",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1alpha1", "pipelines")},
+			"",
+			"tekton.dev/v1beta1 or later is not served, the cluster serves v1alpha1",
+		},
+		{
+			"only later version served",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1", "pipelines"), apiResources("tekton.dev/v1alpha1", "pipelines")},
+			"v1",
+			"",
 		},
-		Spec: v1alpha1.ClusterServiceVersionSpec{
-			CustomResourceDefinitions: v1alpha1.CustomResourceDefinitions{
-				Owned: []v1alpha1.CRDDescription{
-					{Name: "argocds.argoproj.io", Kind: "ArgoCD"},
-					{Name: "fake.crd", Kind: "ArgoCD"},
-				},
-			},
+		{
+			"resource not served in preferred version",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1", "tasks"), apiResources("tekton.dev/v1beta1", "pipelines")},
+			"v1beta1",
+			"",
 		},
-	})
+		{
+			"resource not served",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1beta1", "tasks")},
+			"",
+			`Tekton Pipelines" not found`,
+		},
+		{
+			"resource not served in minimum version",
+			[]*metav1.APIResourceList{apiResources("tekton.dev/v1alpha1", "pipelines"), apiResources("tekton.dev/v1beta1", "tasks")},
+			"",
+			`Tekton Pipelines" not found`,
+		},
+	}
 
-	fakeClient := &Client{OperatorClient: operatorClient.OperatorsV1alpha1()}
+	for _, tt := range dependencyTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = tt.resources
+			fakeClient := &Client{KubeClient: clientset}
 
-	err := fakeClient.CheckIfArgoCDExists("argocd")
-	if err != nil {
-		t.Fatalf("CheckIfArgoCDExists failed: got %v,want %v", err, nil)
-	}
-	err = fakeClient.CheckIfArgoCDExists("unknown")
-	wantErr := `deployments "unknown" not found`
-	if err == nil {
-		t.Fatalf("CheckIfArgoCDExists failed: got %v, want %v", nil, wantErr)
+			version, err := fakeClient.CheckAPIDependency(pipelines)
+			if !matchError(rt, tt.wantErr, err) {
+				rt.Fatalf("CheckAPIDependency() failed: got error %v, want %s", err, tt.wantErr)
+			}
+			if version != tt.wantVersion {
+				rt.Fatalf("CheckAPIDependency() failed: got version %q, want %q", version, tt.wantVersion)
+			}
+		})
 	}
 }
 
-func TestCheckIfPipelinesExists(t *testing.T) {
-	fakeClientSet := fake.NewSimpleClientset(&appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "openshift-pipelines-operator",
-			Namespace: "openshift-operators",
-		},
-	})
-
-	fakeClient := Client{KubeClient: fakeClientSet}
+func apiResources(groupVersion string, names ...string) *metav1.APIResourceList {
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, n := range names {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: n})
+	}
+	return list
+}
 
-	err := fakeClient.CheckIfPipelinesExists("openshift-operators")
-	if err != nil {
-		t.Fatalf("CheckIfPipelinesExists failed: got %v,want %v", err, nil)
+func matchError(t *testing.T, s string, e error) bool {
+	t.Helper()
+	if s == "" && e == nil {
+		return true
 	}
-	err = fakeClient.CheckIfPipelinesExists("unknown")
-	wantErr := `deployments "unknown" not found`
-	if err == nil {
-		t.Fatalf("CheckIfPipelinesExists failed: got %v,want %v", nil, wantErr)
+	if s != "" && e == nil {
+		return false
+	}
+	match, err := regexp.MatchString(s, e.Error())
+	if err != nil {
+		t.Fatal(err)
 	}
+	return match
 }
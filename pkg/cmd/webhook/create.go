@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -35,7 +34,7 @@ func (o *createOptions) Run() error {
 
 	if id != "" {
 		if log.IsJSON() {
-			outputSuccess(id)
+			genericclioptions.OutputSuccess(id)
 		} else {
 			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
 			fmt.Fprintln(w, "CREATED ID")
@@ -63,20 +62,3 @@ func newCmdCreate(name, fullName string) *cobra.Command {
 	o.setFlags(command)
 	return command
 }
-
-// outputSuccess outputs a "successful" machine-readable output format in json
-func outputSuccess(machineOutput interface{}) {
-	printableOutput, err := marshalJSONIndented(machineOutput)
-
-	// If we error out... there's no way to output it (since we disable logging when using -o json)
-	if err != nil {
-		fmt.Fprintf(log.GetStderr(), "unable to unmarshal JSON: %s\n", err.Error())
-	} else {
-		fmt.Fprintf(log.GetStdout(), "%s\n", string(printableOutput))
-	}
-}
-
-// marshalJSONIndented returns indented json representation of obj
-func marshalJSONIndented(obj interface{}) ([]byte, error) {
-	return json.MarshalIndent(obj, "", "	")
-}
@@ -30,7 +30,7 @@ func (o *deleteOptions) Run() error {
 
 	if len(ids) > 0 {
 		if log.IsJSON() {
-			outputSuccess(ids)
+			genericclioptions.OutputSuccess(ids)
 		} else {
 			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
 			fmt.Fprintln(w, "DELETED ID")
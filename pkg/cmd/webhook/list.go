@@ -3,6 +3,7 @@ package webhook
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/openshift/odo/pkg/log"
@@ -16,45 +17,64 @@ import (
 const listRecommendedCommandName = "list"
 
 var (
-	listExample = ktemplates.Examples(`	# List Git repository webhook IDs 
-	%[1]s`)
+	listExample = ktemplates.Examples(`	# List Git repository webhooks
+	%[1]s
+
+	# List Git repository webhooks, including webhooks to previous EventListener addresses
+	%[1]s --stale`)
 )
 
 type listOptions struct {
 	options
+	stale bool
 }
 
 // Run contains the logic for the kam command
 func (o *listOptions) Run() error {
-	ids, err := backend.List(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	webhooks, err := backend.List(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD, o.stale)
 	if err != nil {
-		return fmt.Errorf("unable to a get list of webhook IDs: %v", err)
+		return fmt.Errorf("unable to a get list of webhooks: %v", err)
 	}
 
-	if ids != nil {
-		if log.IsJSON() {
-			outputSuccess(ids)
-		} else {
-			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
-			fmt.Fprintln(w, "ID")
-			fmt.Fprintln(w, "==")
-			for _, id := range ids {
-				fmt.Fprintln(w, id)
-			}
-			w.Flush()
-		}
+	if log.IsJSON() {
+		genericclioptions.OutputSuccess(webhooks)
+		return nil
 	}
-
+	if len(webhooks) == 0 {
+		log.Info("No webhooks found")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "ID\tREPOSITORY\tTARGET\tEVENTS\tACTIVE\tSSL VERIFICATION\tLAST DELIVERY")
+	fmt.Fprintln(w, "==\t==========\t======\t======\t======\t================\t=============")
+	for _, h := range webhooks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\n", h.ID, h.Repository, webhookTarget(h), valueOrDash(strings.Join(h.Events, ",")), h.Active, sslVerification(h), valueOrDash(h.LastDelivery))
+	}
+	w.Flush()
 	return nil
 }
 
+func webhookTarget(h *backend.Webhook) string {
+	if h.Stale {
+		return h.Target + " (stale)"
+	}
+	return h.Target
+}
+
+func sslVerification(h *backend.Webhook) string {
+	if h.SkipVerify {
+		return "disabled"
+	}
+	return "enabled"
+}
+
 func newCmdList(name, fullName string) *cobra.Command {
 
 	o := &listOptions{}
 	command := &cobra.Command{
 		Use:     name,
-		Short:   "List existing webhook Ids.",
-		Long:    "List existing Git repository webhook IDs of the target repository and listener.",
+		Short:   "List existing webhooks.",
+		Long:    "List the existing Git repository webhooks of the target repository and listener, with their events, state, SSL verification, and the status of the last delivery where the Git hosting service reports it.",
 		Example: fmt.Sprintf(listExample, fullName),
 		Run: func(cmd *cobra.Command, args []string) {
 			genericclioptions.GenericRun(o, cmd, args)
@@ -62,5 +82,6 @@ func newCmdList(name, fullName string) *cobra.Command {
 	}
 
 	o.setFlags(command)
+	command.Flags().BoolVar(&o.stale, "stale", false, "Include the webhooks that target a previous address of the EventListener")
 	return command
 }
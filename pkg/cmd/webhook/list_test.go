@@ -3,6 +3,8 @@ package webhook
 import (
 	"fmt"
 	"testing"
+
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
 )
 
 func TestValidateForList(t *testing.T) {
@@ -12,43 +14,43 @@ func TestValidateForList(t *testing.T) {
 	}{
 		{
 			&listOptions{
-				options{isCICD: true, serviceName: "foo"},
+				options: options{isCICD: true, serviceName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, envName: "foo"},
+				options: options{isCICD: true, envName: "foo"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, envName: "foo", serviceName: "bar"},
+				options: options{isCICD: true, envName: "foo", serviceName: "bar"},
 			},
 			"Only one of 'cicd' or 'env-name/service-name' can be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false},
+				options: options{isCICD: false},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false, serviceName: "foo"},
+				options: options{isCICD: false, serviceName: "foo"},
 			},
 			"One of 'cicd' or 'env-name/service-name' must be specified",
 		},
 		{
 			&listOptions{
-				options{isCICD: false, serviceName: "foo", envName: "gau"},
+				options: options{isCICD: false, serviceName: "foo", envName: "gau"},
 			},
 			"",
 		},
 		{
 			&listOptions{
-				options{isCICD: true, serviceName: ""},
+				options: options{isCICD: true, serviceName: ""},
 			},
 			"",
 		},
@@ -69,3 +71,23 @@ func TestValidateForList(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookColumns(t *testing.T) {
+	testcases := []struct {
+		webhook    *backend.Webhook
+		wantTarget string
+		wantSSL    string
+	}{
+		{&backend.Webhook{Target: "https://el.example.com"}, "https://el.example.com", "enabled"},
+		{&backend.Webhook{Target: "https://el.old.example.com", Stale: true, SkipVerify: true}, "https://el.old.example.com (stale)", "disabled"},
+	}
+
+	for _, tt := range testcases {
+		if got := webhookTarget(tt.webhook); got != tt.wantTarget {
+			t.Errorf("webhookTarget() got %q, want %q", got, tt.wantTarget)
+		}
+		if got := sslVerification(tt.webhook); got != tt.wantSSL {
+			t.Errorf("sslVerification() got %q, want %q", got, tt.wantSSL)
+		}
+	}
+}
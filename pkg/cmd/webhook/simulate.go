@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/simulator"
+)
+
+const simulateRecommendedCommandName = "simulate"
+
+var (
+	simulateExample = ktemplates.Examples(`	# Simulate the delivery of a GitHub push event to the EventListener
+	%[1]s --payload push.json --headers "X-GitHub-Event: push"`)
+)
+
+type simulateOptions struct {
+	pipelinesFolderPath string
+	payloadPath         string
+	headers             []string
+	header              http.Header
+}
+
+// Complete completes simulateOptions after they've been created
+func (o *simulateOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	o.header = http.Header{}
+	for _, h := range o.headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("invalid header %q, headers must be in the form 'Name: value'", h)
+		}
+		o.header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return nil
+}
+
+// Validate validates the simulateOptions based on completed values
+func (o *simulateOptions) Validate() error {
+	if o.payloadPath == "" {
+		return errors.New("the payload of the event must be provided with --payload")
+	}
+	return nil
+}
+
+// Run contains the logic for the kam command
+func (o *simulateOptions) Run() error {
+	body, err := ioutil.ReadFile(o.payloadPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the payload: %w", err)
+	}
+	results, err := pipelines.SimulateWebhook(&pipelines.SimulateWebhookOptions{
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		Event:               simulator.Event{Header: o.header, Body: body},
+	}, ioutils.NewFilesystem())
+	if err != nil {
+		return fmt.Errorf("unable to simulate the webhook: %w", err)
+	}
+
+	if log.IsJSON() {
+		genericclioptions.OutputSuccess(results)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "TRIGGER\tRESULT")
+	fmt.Fprintln(w, "=======\t======")
+	matched := []*simulator.Result{}
+	for _, r := range results {
+		if r.Matched {
+			matched = append(matched, r)
+			fmt.Fprintf(w, "%s\tmatched\n", r.Trigger)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", r.Trigger, r.Reason)
+	}
+	w.Flush()
+	if len(matched) == 0 {
+		log.Warning("No trigger matched the event, no PipelineRuns would be created")
+		return nil
+	}
+	for _, r := range matched {
+		fmt.Printf("\nTrigger %s:\n\n", r.Trigger)
+		w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "PARAM\tVALUE")
+		fmt.Fprintln(w, "=====\t=====")
+		for _, p := range r.Params {
+			fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Value)
+		}
+		w.Flush()
+		for _, res := range r.Resources {
+			out, err := yaml.JSONToYAML(res)
+			if err != nil {
+				return fmt.Errorf("failed to render the resources for trigger %s: %w", r.Trigger, err)
+			}
+			fmt.Printf("\n---\n%s", out)
+		}
+	}
+	return nil
+}
+
+func newCmdSimulate(name, fullName string) *cobra.Command {
+	o := &simulateOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Simulate the delivery of a webhook event.",
+		Long:    "Process a webhook event with the triggers built from the manifest, without a cluster, and report the triggers that match, their params, and the resources that would be created. The signature of the event is not validated.",
+		Example: fmt.Sprintf(simulateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVar(&o.payloadPath, "payload", "", "Path to a file with the JSON payload of the event")
+	command.Flags().StringArrayVar(&o.headers, "headers", nil, "Header of the event in the form 'Name: value', this can be repeated e.g. --headers 'X-GitHub-Event: push'")
+	return command
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompleteForSimulate(t *testing.T) {
+	testcases := []struct {
+		desc    string
+		headers []string
+		want    http.Header
+		errMsg  string
+	}{
+		{
+			"no headers",
+			nil,
+			http.Header{},
+			"",
+		},
+		{
+			"repeated headers",
+			[]string{"X-GitHub-Event: push", "x-custom:a", "X-Custom: b"},
+			http.Header{"X-Github-Event": []string{"push"}, "X-Custom": []string{"a", "b"}},
+			"",
+		},
+		{
+			"invalid header",
+			[]string{"X-GitHub-Event push"},
+			nil,
+			"invalid header \"X-GitHub-Event push\"",
+		},
+		{
+			"missing name",
+			[]string{": push"},
+			nil,
+			"headers must be in the form 'Name: value'",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.desc, func(t *testing.T) {
+			o := &simulateOptions{headers: tt.headers}
+			err := o.Complete("simulate", nil, nil)
+			if !matchError(t, tt.errMsg, err) {
+				t.Fatalf("Complete() failed to match error: got %s, want %s", err, tt.errMsg)
+			}
+			if tt.errMsg != "" {
+				return
+			}
+			if diff := cmp.Diff(tt.want, o.header); diff != "" {
+				t.Fatalf("Complete() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateForSimulate(t *testing.T) {
+	testcases := []struct {
+		options *simulateOptions
+		errMsg  string
+	}{
+		{&simulateOptions{}, "the payload of the event must be provided with --payload"},
+		{&simulateOptions{payloadPath: "push.json"}, ""},
+	}
+
+	for _, tt := range testcases {
+		err := tt.options.Validate()
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() failed to match error: got %s, want %s", err, tt.errMsg)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+const syncRecommendedCommandName = "sync"
+
+var (
+	syncExample = ktemplates.Examples(`	# Show the changes needed to the webhooks of the repositories in the manifest
+	%[1]s --dry-run
+
+	# Create and fix the webhooks, and delete the webhooks that aren't needed
+	%[1]s --prune
+
+	# Replace the webhooks to a previous address of the EventListener
+	%[1]s --fix-stale`)
+)
+
+type syncOptions struct {
+	accessToken         string
+	pipelinesFolderPath string
+	dryRun              bool
+	prune               bool
+	fixStale            bool
+}
+
+// Complete completes syncOptions after they've been created
+func (o *syncOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the syncOptions based on completed values
+func (o *syncOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the kam command
+func (o *syncOptions) Run() error {
+	plan, err := backend.PlanSync(&backend.SyncOptions{
+		AccessToken:         o.accessToken,
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		Prune:               o.prune,
+		FixStale:            o.fixStale,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to plan the webhook changes: %v", err)
+	}
+
+	if log.IsJSON() {
+		if !o.dryRun {
+			if err := plan.Apply(); err != nil {
+				return fmt.Errorf("unable to sync webhooks: %v", err)
+			}
+		}
+		genericclioptions.OutputSuccess(plan.Changes)
+		return nil
+	}
+	printChanges(plan.Changes)
+	if !plan.HasChanges() {
+		log.Success("The webhooks are up to date")
+		return nil
+	}
+	if o.dryRun {
+		return nil
+	}
+	if err := plan.Apply(); err != nil {
+		return fmt.Errorf("unable to sync webhooks: %v", err)
+	}
+	log.Success("Synced the webhooks")
+	return nil
+}
+
+func printChanges(changes []*backend.Change) {
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "ACTION\tTARGET\tREPOSITORY\tID\tREASON")
+	fmt.Fprintln(w, "======\t======\t==========\t==\t======")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Action, valueOrDash(c.Target), c.Repository, valueOrDash(c.ID), valueOrDash(c.Reason))
+	}
+	w.Flush()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func newCmdSync(name, fullName string) *cobra.Command {
+	o := &syncOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Sync the webhooks of the repositories in the manifest.",
+		Long:    "Create the missing webhooks for the GitOps repository and the source repositories of the services in the manifest, and fix the webhooks with the wrong events. Webhooks for an EventListener with a different target are reported, as they may belong to another cluster, they are replaced with --fix-stale, and deleted with --prune. The planned changes are printed before they are made.",
+		Example: fmt.Sprintf(syncExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used for all the Git repositories, by default the token for each repository is read from the keyring or environment")
+	command.Flags().BoolVar(&o.dryRun, "dry-run", false, "Print the planned changes without making them")
+	command.Flags().BoolVar(&o.prune, "prune", false, "Delete the webhooks to the EventListener that aren't needed, including webhooks with a different target")
+	command.Flags().BoolVar(&o.fixStale, "fix-stale", false, "Replace the webhooks for an EventListener with a different target with webhooks to the EventListener, use this when the EventListener address has changed")
+	return command
+}
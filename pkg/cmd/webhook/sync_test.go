@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"testing"
+)
+
+func TestSyncCommandFlags(t *testing.T) {
+	command := newCmdSync("sync", "kam webhook sync")
+
+	flagTests := []struct {
+		name string
+		want string
+	}{
+		{"pipelines-folder", "."},
+		{"git-host-access-token", ""},
+		{"dry-run", "false"},
+		{"prune", "false"},
+	}
+
+	for _, tt := range flagTests {
+		f := command.Flags().Lookup(tt.name)
+		if f == nil {
+			t.Errorf("flag %s is missing", tt.name)
+			continue
+		}
+		if f.DefValue != tt.want {
+			t.Errorf("flag %s default got %q, want %q", tt.name, f.DefValue, tt.want)
+		}
+	}
+}
+
+func TestValueOrDash(t *testing.T) {
+	if got := valueOrDash(""); got != "-" {
+		t.Errorf("valueOrDash(\"\") got %q, want \"-\"", got)
+	}
+	if got := valueOrDash("dev/api"); got != "dev/api" {
+		t.Errorf("valueOrDash(\"dev/api\") got %q, want \"dev/api\"", got)
+	}
+}
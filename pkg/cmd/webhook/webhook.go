@@ -15,16 +15,20 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	createCmd := newCmdCreate(createRecommendedCommandName, utility.GetFullName(fullName, createRecommendedCommandName))
 	deleteCmd := newCmdDelete(deleteRecommendedCommandName, utility.GetFullName(fullName, deleteRecommendedCommandName))
 	listCmd := newCmdList(listRecommendedCommandName, utility.GetFullName(fullName, listRecommendedCommandName))
+	syncCmd := newCmdSync(syncRecommendedCommandName, utility.GetFullName(fullName, syncRecommendedCommandName))
+	simulateCmd := newCmdSimulate(simulateRecommendedCommandName, utility.GetFullName(fullName, simulateRecommendedCommandName))
 
 	var webhookCmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage Git repository webhooks",
-		Long:  "Add/Delete/list Git repository webhooks that trigger CI/CD pipeline runs.",
-		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+		Long:  "Add/Delete/list/sync Git repository webhooks that trigger CI/CD pipeline runs, and simulate the events that they deliver.",
+		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
 			fullName,
 			createRecommendedCommandName,
 			deleteRecommendedCommandName,
-			listRecommendedCommandName),
+			listRecommendedCommandName,
+			syncRecommendedCommandName,
+			simulateRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
@@ -32,6 +36,8 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	webhookCmd.AddCommand(createCmd)
 	webhookCmd.AddCommand(deleteCmd)
 	webhookCmd.AddCommand(listCmd)
+	webhookCmd.AddCommand(syncCmd)
+	webhookCmd.AddCommand(simulateCmd)
 
 	webhookCmd.Annotations = map[string]string{"command": "main"}
 	return webhookCmd
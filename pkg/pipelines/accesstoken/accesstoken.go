@@ -2,11 +2,12 @@ package accesstoken
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
 	"github.com/zalando/go-keyring"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 )
 
 // KeyringServiceName refers to service name used to set the accesstoken in the keyring
@@ -31,10 +32,13 @@ func GetAccessToken(gitRepoURL string) (string, error) {
 
 // HostFromURL extracts the hostname from the url passed
 func HostFromURL(s string) (string, error) {
-	p, err := url.Parse(s)
+	p, err := giturl.Parse(s)
 	if err != nil {
 		return "", err
 	}
+	if giturl.IsSSH(s) {
+		return strings.ToLower(p.Hostname()), nil
+	}
 	return strings.ToLower(p.Host), nil
 }
 
@@ -0,0 +1,67 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// AddApplicationOptions control the application that is added to an
+// environment, the configuration for the application is maintained in an
+// external config repository.
+type AddApplicationOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+	AppName             string
+	ConfigRepoURL       string
+	Path                string
+	TargetRevision      string
+}
+
+// AddApplication is the entry-point from the CLI for adding applications
+// backed by an external config repository.
+//
+// The application is added to the manifest, and the environment, Argo CD and
+// CI/CD resources are regenerated.
+func AddApplication(o *AddApplicationOptions, appFs afero.Fs) error {
+	if err := normalizeGitURLs(&o.ConfigRepoURL); err != nil {
+		return err
+	}
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	app := &config.Application{
+		Name: o.AppName,
+		ConfigRepo: &config.Repository{
+			URL:            o.ConfigRepoURL,
+			Path:           o.Path,
+			TargetRevision: o.TargetRevision,
+		},
+	}
+	if err := m.AddApplication(o.EnvName, app); err != nil {
+		return err
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	files := res.Resources{pipelinesFile: m}
+	built, err := buildResources(appFs, o.PipelinesFolderPath, m)
+	if err != nil {
+		return fmt.Errorf("failed to build resources: %v", err)
+	}
+	files = res.Merge(built, files)
+	if _, err := yaml.WriteResources(appFs, o.PipelinesFolderPath, files); err != nil {
+		return err
+	}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		base := filepath.ToSlash(filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base"))
+		return updateKustomization(appFs, base)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	argo "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestAddApplication(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := AddApplication(&AddApplicationOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+		AppName:             "app-config",
+		ConfigRepoURL:       "https://github.com/org/config.git",
+		Path:                "deploy/dev",
+		TargetRevision:      "main",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := &config.Application{
+		Name: "app-config",
+		ConfigRepo: &config.Repository{
+			URL:            "https://github.com/org/config.git",
+			Path:           "deploy/dev",
+			TargetRevision: "main",
+		},
+	}
+	if diff := cmp.Diff(want, m.GetApplication("tst-dev", "app-config")); diff != "" {
+		t.Fatalf("application was not added to the manifest:\n%s", diff)
+	}
+
+	data, err := afero.ReadFile(fakeFs, "/gitops/config/argocd/tst-dev-app-config-app.yaml")
+	assertNoError(t, err)
+	app := &argo.Application{}
+	assertNoError(t, yaml.Unmarshal(data, app))
+	wantSource := argo.ApplicationSource{
+		RepoURL:        "https://github.com/org/config.git",
+		Path:           "deploy/dev",
+		TargetRevision: "main",
+	}
+	if diff := cmp.Diff(wantSource, app.Spec.Source); diff != "" {
+		t.Fatalf("Argo CD application source incorrect:\n%s", diff)
+	}
+	assertExists(t, fakeFs, "/gitops/config/argocd/tst-dev-app-http-api-app.yaml")
+}
+
+func TestAddApplicationUpdatesCICDKustomization(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	m.GetEnvironment("tst-dev").Pipelines.Tests = []*config.TestStep{{Name: "unit", Image: "golang:1.16", Script: "go test ./..."}}
+	b, err := yaml.Marshal(m)
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", b, 0644))
+
+	err = AddApplication(&AddApplicationOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+		AppName:             "app-config",
+		ConfigRepoURL:       "https://github.com/org/config.git",
+		Path:                "deploy/dev",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	assertExists(t, fakeFs, "/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-tst-dev-http-api.yaml")
+	k := struct {
+		Resources []string `json:"resources"`
+	}{}
+	data, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml")
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(data, &k))
+	if !containsString(k.Resources, "04-pipelines/app-ci-pipeline-tst-dev-http-api.yaml") {
+		t.Fatalf("the CI/CD kustomization doesn't include the generated pipeline: %v", k.Resources)
+	}
+}
+
+func TestAddApplicationErrors(t *testing.T) {
+	errTests := []struct {
+		name    string
+		opts    *AddApplicationOptions
+		wantErr string
+	}{
+		{"existing application", &AddApplicationOptions{EnvName: "tst-dev", AppName: "app-http-api", ConfigRepoURL: "https://github.com/org/config.git", Path: "deploy"}, "application app-http-api already exists in environment tst-dev"},
+		{"unknown environment", &AddApplicationOptions{EnvName: "unknown", AppName: "app-config", ConfigRepoURL: "https://github.com/org/config.git", Path: "deploy"}, "environment unknown does not exist"},
+		{"missing path", &AddApplicationOptions{EnvName: "tst-dev", AppName: "app-config", ConfigRepoURL: "https://github.com/org/config.git"}, `missing field\(s\) "path": environments.tst-dev.apps.app-config.config_repo`},
+	}
+
+	for _, tt := range errTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := bootstrapForRemoval(rt)
+			tt.opts.PipelinesFolderPath = "/gitops"
+			err := AddApplication(tt.opts, fakeFs)
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package pipelines
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -21,7 +20,9 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
+	"github.com/redhat-developer/kam/pkg/pipelines/ingresses"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
@@ -47,12 +48,14 @@ const (
 	argocdAdminRolePath   = "02-rolebindings/argocd-admin.yaml"
 	gitopsTasksPath       = "03-tasks/deploy-from-source-task.yaml"
 	commitStatusTaskPath  = "03-tasks/set-commit-status-task.yaml"
+	updateGitOpsTaskPath  = "03-tasks/update-gitops-task.yaml"
 	ciPipelinesPath       = "04-pipelines/ci-dryrun-from-push-pipeline.yaml"
 	appCiPipelinesPath    = "04-pipelines/app-ci-pipeline.yaml"
 	pushTemplatePath      = "06-templates/ci-dryrun-from-push-template.yaml"
 	appCIPushTemplatePath = "06-templates/app-ci-build-from-push-template.yaml"
 	eventListenerPath     = "07-eventlisteners/cicd-event-listener.yaml"
 	routePath             = "08-routes/gitops-webhook-event-listener.yaml"
+	ingressPath           = "08-ingresses/gitops-webhook-event-listener.yaml"
 
 	dockerSecretName = "regcred"
 
@@ -84,6 +87,15 @@ type BootstrapOptions struct {
 	ServiceWebhookSecret     string // This is the secret for authenticating hooks from your app source.
 	PrivateRepoDriver        string // Records the type of the GitOpsRepoURL driver if not a well-known host.
 	PushToGit                bool   // If true, gitops repository is pushed to remote git repository.
+	SealedSecretsCert        string // If provided, generated secrets are sealed with the key from this certificate.
+	SecretsMode              string // One of raw, sealed or external, this controls how generated secrets are written.
+	SecretStoreName          string // The SecretStore that ExternalSecrets are read from in external mode.
+	SecretStoreKind          string // Either SecretStore or ClusterSecretStore.
+	SecretsKeyPath           string // The path in the secret store that external secrets are read from.
+	TektonAPI                string // Either v1beta1 or v1, the version of the Tekton APIs that resources are generated for.
+	Platform                 string // Either openshift or kubernetes, this controls whether Services are exposed with Routes or Ingresses.
+	IngressClass             string // The IngressClass of generated Ingresses on the kubernetes platform.
+	IngressHost              string // The template for the hosts of generated Ingresses on the kubernetes platform.
 }
 
 // PolicyRules to be bound to service account
@@ -120,6 +132,9 @@ var (
 // Bootstrap is the entry-point from the CLI for bootstrapping the GitOps
 // configuration.
 func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
+	if err := normalizeGitURLs(&o.GitOpsRepoURL, &o.ServiceRepoURL); err != nil {
+		return err
+	}
 	err := checkPipelinesFileExists(appFs, o.OutputPath, o.Overwrite, o.PushToGit)
 	if err != nil {
 		return err
@@ -135,7 +150,7 @@ func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
 	}
 
 	m := bootstrapped[pipelinesFile].(*config.Manifest)
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, o.OutputPath, m)
 	if err != nil {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
@@ -184,9 +199,12 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	// No image repo was supplied so create the default OS internal image registry
 	if o.ImageRepo == "" {
+		if o.Platform == config.PlatformKubernetes {
+			return nil, nil, errors.New("failed to find an image repository: --image-repo is required on the kubernetes platform")
+		}
 		o.ImageRepo = ns["cicd"] + "/" + repoName
 	}
-	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(o.ImageRepo)
+	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepoForPlatform(o.ImageRepo, o.Platform)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -206,8 +224,12 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	if err != nil {
 		return nil, nil, err
 	}
+	secretsOut, err := newSecretOutputs(appFs, bootstrapSecretsConfig(o), o.SealedSecretsCert)
+	if err != nil {
+		return nil, nil, err
+	}
 	bootstrapped, otherResources, err := createInitialFiles(
-		appFs, gitOpsRepo, o)
+		appFs, gitOpsRepo, o, secretsOut)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -225,6 +247,10 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 		}
 		configEnv.Git = &config.GitConfig{Drivers: map[string]string{host: o.PrivateRepoDriver}}
 	}
+	configEnv.Secrets = secretsOut.config()
+	configEnv.Pipelines.TektonAPI = o.TektonAPI
+	configEnv.Platform = o.Platform
+	configEnv.Ingress = bootstrapIngressConfig(o)
 	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
 
 	devEnv := m.GetEnvironment(ns["dev"])
@@ -236,7 +262,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	if app == nil {
 		return nil, nil, errors.New("unable to bootstrap without application")
 	}
-	svcFiles, err := bootstrapServiceDeployment(devEnv, app)
+	svcFiles, err := bootstrapServiceDeployment(m, devEnv, app)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create bootstrap service: %w", err)
 	}
@@ -252,8 +278,12 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	if cfg == nil {
 		return nil, nil, errors.New("failed to find a pipeline configuration - unable to continue bootstrap")
 	}
-	secretFilename := filepath.ToSlash(filepath.Join("secrets", secretName+".yaml"))
-	otherResources[secretFilename] = opaqueSecret
+	secretFiles := res.Resources{}
+	secretFilename, err := secretsOut.add(secretFiles, otherResources, secretName+".yaml", opaqueSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	bootstrapped = res.Merge(addPrefixToResources(pipelinesPath(m.Config), secretFiles), bootstrapped)
 	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(cfg, devEnv, appName, serviceName, imageRepo, !isInternalRegistry)
 	bootstrapped = res.Merge(svcImageBinding, bootstrapped)
 
@@ -282,13 +312,36 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	bootstrapped[pipelinesFile] = m
 
 	k.AddResources(imageRepoBindingFilename)
+	if secretFilename != "" {
+		k.AddResources(secretFilename)
+	}
 	bootstrapped[kustomizePath] = k
 
+	if len(secretsOut.remoteKeys) > 0 {
+		otherResources[externalSecretKeysPath] = secretsOut.remoteKeys
+		logRemoteKeys(secretsOut.remoteKeys)
+	}
+
 	bootstrapped = res.Merge(svcFiles, bootstrapped)
 	return bootstrapped, otherResources, nil
 }
 
-func bootstrapServiceDeployment(dev *config.Environment, app *config.Application) (res.Resources, error) {
+func bootstrapSecretsConfig(o *BootstrapOptions) *config.SecretsConfig {
+	cfg := &config.SecretsConfig{Mode: o.SecretsMode, KeyPath: o.SecretsKeyPath}
+	if o.SecretStoreName != "" {
+		cfg.Store = &config.SecretStoreRef{Name: o.SecretStoreName, Kind: o.SecretStoreKind}
+	}
+	return cfg
+}
+
+func bootstrapIngressConfig(o *BootstrapOptions) *config.IngressConfig {
+	if o.IngressClass == "" && o.IngressHost == "" {
+		return nil
+	}
+	return &config.IngressConfig{Class: o.IngressClass, Host: o.IngressHost}
+}
+
+func bootstrapServiceDeployment(m *config.Manifest, dev *config.Environment, app *config.Application) (res.Resources, error) {
 	svc := dev.Apps[0].Services[0]
 	svcBase := filepath.Join(config.PathForService(app, dev, svc.Name), "base", "config")
 	resources := res.Resources{}
@@ -297,20 +350,42 @@ func bootstrapServiceDeployment(dev *config.Environment, app *config.Application
 	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.Name, svc.Name, bootstrapImage, deployment.ContainerPort(8080))
 	containerSvc := createBootstrapService(app.Name, dev.Name, svc.Name)
 	resources[filepath.Join(svcBase, "200-service.yaml")] = containerSvc
-	r, err := routes.NewFromService(containerSvc)
+	exposed, err := exposeService(m, containerSvc)
 	if err != nil {
 		return nil, err
 	}
-	resources[filepath.Join(svcBase, "300-route.yaml")] = r
+	exposedFilename := "300-route.yaml"
+	if m.GetPlatform() == config.PlatformKubernetes {
+		exposedFilename = "300-ingress.yaml"
+	}
+	resources[filepath.Join(svcBase, exposedFilename)] = exposed
 	resources[filepath.Join(svcBase, "kustomization.yaml")] = &res.Kustomization{
 		Resources: []string{
 			"100-deployment.yaml",
 			"200-service.yaml",
-			"300-route.yaml",
+			exposedFilename,
 		}}
 	return resources, nil
 }
 
+// exposeService creates a Route for the Service, or an Ingress on the
+// kubernetes platform.
+func exposeService(m *config.Manifest, svc *corev1.Service) (interface{}, error) {
+	if m.GetPlatform() != config.PlatformKubernetes {
+		return routes.NewFromService(svc)
+	}
+	cfg := m.GetIngressConfig()
+	host, err := cfg.HostFor(svc.Name, svc.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var class string
+	if cfg != nil {
+		class = cfg.Class
+	}
+	return ingresses.NewFromService(svc, class, host)
+}
+
 func bootstrapEnvironments(repo scm.Repository, prefix, secretName string, ns map[string]string) ([]*config.Environment, *config.Config, error) {
 	envs := []*config.Environment{}
 	var pipelinesConfig *config.PipelinesConfig
@@ -369,7 +444,7 @@ func applicationFromRepo(repoURL string, service *config.Service) (*config.Appli
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
@@ -378,12 +453,11 @@ func repoFromURL(raw string) (string, error) {
 }
 
 func orgRepoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	orgRepo := strings.Join(parts[len(parts)-2:], "/")
+	orgRepo := strings.Trim(u.Path, "/")
 	return strings.TrimSuffix(orgRepo, ".git"), nil
 }
 
@@ -456,14 +530,14 @@ func errorIfFileExists(appFs afero.Fs, outputPath string, files ...string) error
 	return nil
 }
 
-func createInitialFiles(fs afero.Fs, repo scm.Repository, o *BootstrapOptions) (res.Resources, res.Resources, error) {
-	cicd := &config.PipelinesConfig{Name: o.Prefix + "cicd"}
+func createInitialFiles(fs afero.Fs, repo scm.Repository, o *BootstrapOptions, secretsOut *secretOutputs) (res.Resources, res.Resources, error) {
+	cicd := &config.PipelinesConfig{Name: o.Prefix + "cicd", TektonAPI: o.TektonAPI}
 	pipelineConfig := &config.Config{Pipelines: cicd}
 	manifest := createManifest(repo.URL(), pipelineConfig)
 	initialFiles := res.Resources{
 		pipelinesFile: manifest,
 	}
-	resources, otherResources, err := createCICDResources(fs, repo, cicd, o)
+	resources, otherResources, err := createCICDResources(fs, repo, cicd, o, secretsOut)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -503,7 +577,7 @@ func createDockerSecret(fs afero.Fs, dockerConfigJSONFilename, secretNS string)
 }
 
 // createCICDResources creates resources for OpenShift pipelines.
-func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *config.PipelinesConfig, o *BootstrapOptions) (res.Resources, res.Resources, error) {
+func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *config.PipelinesConfig, o *BootstrapOptions, secretsOut *secretOutputs) (res.Resources, res.Resources, error) {
 	cicdNamespace := pipelineConfig.Name
 	// key: path of the resource
 	// value: YAML content of the resource
@@ -513,8 +587,9 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate GitHub Webhook Secret: %w", err)
 	}
-	unEncSecretPath := filepath.Join("secrets", "gitops-webhook-secret.yaml")
-	otherOutputs[unEncSecretPath] = githubSecret
+	if _, err := secretsOut.add(outputs, otherOutputs, "gitops-webhook-secret.yaml", githubSecret); err != nil {
+		return nil, nil, err
+	}
 	outputs[namespacesPath] = namespaces.Create(cicdNamespace, o.GitOpsRepoURL)
 	outputs[rolesPath] = roles.CreateClusterRole(meta.NamespacedName("", roles.ClusterRoleName), Rules)
 
@@ -526,14 +601,18 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 			return nil, nil, err
 		}
 		if dockerUnencryptedSecret != nil {
-			otherOutputs[filepath.Join("secrets", "docker-config.yaml")] = dockerUnencryptedSecret
-			log.Success("Authentication tokens for docker config not sealed in secrets")
+			if _, err := secretsOut.add(outputs, otherOutputs, "docker-config.yaml", dockerUnencryptedSecret); err != nil {
+				return nil, nil, err
+			}
+			if secretsOut.isRaw() {
+				log.Success("Authentication tokens for docker config not sealed in secrets")
+			}
 		}
 		outputs[serviceAccountPath] = roles.AddSecretToSA(sa, dockerSecretName)
 	}
 
 	if o.GitHostAccessToken != "" {
-		err := generateSecrets(outputs, otherOutputs, sa, cicdNamespace, o)
+		err := generateSecrets(outputs, otherOutputs, sa, cicdNamespace, o, secretsOut)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -548,14 +627,35 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	}
 	outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script)
 	outputs[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cicdNamespace)
+	catalogTasks, err := createCatalogTasks(pipelineConfig, defaultCatalogTasks...)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs = res.Merge(catalogTasks, outputs)
 	outputs[ciPipelinesPath] = pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace)
 	outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline"))
 	pushBinding, pushBindingName := repo.CreatePushBinding(cicdNamespace)
 	outputs[filepath.ToSlash(filepath.Join("05-bindings", pushBindingName+".yaml"))] = pushBinding
+	prBinding, prBindingName := repo.CreatePullRequestBinding(cicdNamespace)
+	outputs[filepath.ToSlash(filepath.Join("05-bindings", prBindingName+".yaml"))] = prBinding
 	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName)
 	outputs[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cicdNamespace, saName)
 	outputs[eventListenerPath] = eventlisteners.Generate(repo, cicdNamespace, saName, eventlisteners.GitOpsWebhookSecret)
 	log.Success("OpenShift Pipelines resources created")
+	if o.Platform == config.PlatformKubernetes {
+		ingressCfg := bootstrapIngressConfig(o)
+		host, err := ingressCfg.HostFor(eventlisteners.GitOpsWebhookEventListenerIngressName, cicdNamespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		ingress, err := eventlisteners.GenerateIngress(cicdNamespace, o.IngressClass, host)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs[ingressPath] = ingress
+		log.Success("Ingress for EventListener created")
+		return outputs, otherOutputs, nil
+	}
 	route, err := eventlisteners.GenerateRoute(cicdNamespace)
 	if err != nil {
 		return nil, nil, err
@@ -606,13 +706,15 @@ func getResourceFiles(r res.Resources) []string {
 	return files
 }
 
-func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *corev1.ServiceAccount, ns string, o *BootstrapOptions) error {
+func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *corev1.ServiceAccount, ns string, o *BootstrapOptions, secretsOut *secretOutputs) error {
 	tokenSecret, err := secrets.CreateUnsealedSecret(meta.NamespacedName(
 		ns, authTokenSecretName), o.GitHostAccessToken, "token")
 	if err != nil {
 		return fmt.Errorf("failed to generate Secret: %w", err)
 	}
-	otherOutputs[filepath.Join("secrets", "git-host-access-token.yaml")] = tokenSecret
+	if _, err := secretsOut.add(outputs, otherOutputs, "git-host-access-token.yaml", tokenSecret); err != nil {
+		return err
+	}
 	outputs[serviceAccountPath] = roles.AddSecretToSA(sa, tokenSecret.Name)
 
 	// basic auth token is used by Tekton pipelines to access private repositories
@@ -624,7 +726,9 @@ func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *core
 		ns, basicAuthTokenName), o.GitHostAccessToken, meta.AddAnnotations(map[string]string{
 		"tekton.dev/git-0": secretTargetHost,
 	}))
-	otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")] = basicAuthSecret
+	if _, err := secretsOut.add(outputs, otherOutputs, basicAuthTokenName+".yaml", basicAuthSecret); err != nil {
+		return err
+	}
 	outputs[serviceAccountPath] = roles.AddSecretToSA(sa, basicAuthSecret.Name)
 	return nil
 }
@@ -1,11 +1,14 @@
 package pipelines
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
@@ -17,7 +20,12 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/redhat-developer/kam/test"
+	"github.com/spf13/afero"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -135,10 +143,13 @@ func TestBootstrapManifest(t *testing.T) {
 		"02-rolebindings/pipeline-service-account.yaml",
 		"02-rolebindings/pipeline-service-role.yaml",
 		"02-rolebindings/pipeline-service-rolebinding.yaml",
+		"03-tasks/buildah-task.yaml",
 		"03-tasks/deploy-from-source-task.yaml",
+		"03-tasks/git-clone-task.yaml",
 		"03-tasks/set-commit-status-task.yaml",
 		"04-pipelines/app-ci-pipeline.yaml",
 		"04-pipelines/ci-dryrun-from-push-pipeline.yaml",
+		"05-bindings/github-pull-request-binding.yaml",
 		"05-bindings/github-push-binding.yaml",
 		"05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
 		"06-templates/app-ci-build-from-push-template.yaml",
@@ -152,6 +163,152 @@ func TestBootstrapManifest(t *testing.T) {
 	}
 }
 
+func TestBootstrapManifestWithSealedSecrets(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	cert, err := ioutil.ReadFile("testdata/sealed-secrets-cert.pem")
+	fatalIfError(t, err)
+	fatalIfError(t, fakeFs.WriteFile("/tmp/cert.pem", cert, 0644))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		SealedSecretsCert:    "/tmp/cert.pem",
+	}
+	r, otherResources, err := bootstrapResources(params, fakeFs)
+	fatalIfError(t, err)
+
+	if diff := cmp.Diff(res.Resources{}, otherResources); diff != "" {
+		t.Fatalf("unsealed secrets were generated:\n%s", diff)
+	}
+	m := r[pipelinesFile].(*config.Manifest)
+	wantConfig := &config.SecretsConfig{Mode: config.SecretsModeSealed, SealedSecretsCert: "/tmp/cert.pem"}
+	if diff := cmp.Diff(wantConfig, m.GetSecretsConfig()); diff != "" {
+		t.Fatalf("secrets config failed:\n%s", diff)
+	}
+	wantSecrets := []string{
+		"09-secrets/git-host-access-token.yaml",
+		"09-secrets/git-host-basic-auth-token.yaml",
+		"09-secrets/gitops-webhook-secret.yaml",
+		"09-secrets/webhook-secret-tst-dev-http-api.yaml",
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range wantSecrets {
+		sealed, ok := r[filepath.Join("config/tst-cicd/base", v)].(*secrets.SealedSecret)
+		if !ok {
+			t.Fatalf("failed to find a sealed secret at %s", v)
+		}
+		if sealed.Namespace != "tst-cicd" {
+			t.Errorf("sealed secret %s has namespace %q, want %q", v, sealed.Namespace, "tst-cicd")
+		}
+		if !containsString(k.Resources, v) {
+			t.Errorf("base kustomization does not include %s", v)
+		}
+	}
+}
+
+func TestBootstrapManifestWithExternalSecrets(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		SecretsMode:          config.SecretsModeExternal,
+		SecretStoreName:      "vault-backend",
+		SecretStoreKind:      "ClusterSecretStore",
+		SecretsKeyPath:       "kam",
+	}
+	r, otherResources, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	wantKeys := []secrets.RemoteKey{
+		{Key: "kam/tst-cicd/gitops-webhook-secret", Property: "webhook-secret-key"},
+		{Key: "kam/tst-cicd/git-host-access-token", Property: "token"},
+		{Key: "kam/tst-cicd/git-host-basic-auth-token", Property: "password"},
+		{Key: "kam/tst-cicd/git-host-basic-auth-token", Property: "username"},
+		{Key: "kam/tst-cicd/webhook-secret-tst-dev-http-api", Property: "webhook-secret-key"},
+	}
+	if diff := cmp.Diff(res.Resources{"secrets/external-secret-keys.yaml": wantKeys}, otherResources); diff != "" {
+		t.Fatalf("external secret keys failed:\n%s", diff)
+	}
+	m := r[pipelinesFile].(*config.Manifest)
+	wantConfig := &config.SecretsConfig{
+		Mode:    config.SecretsModeExternal,
+		Store:   &config.SecretStoreRef{Name: "vault-backend", Kind: "ClusterSecretStore"},
+		KeyPath: "kam",
+	}
+	if diff := cmp.Diff(wantConfig, m.GetSecretsConfig()); diff != "" {
+		t.Fatalf("secrets config failed:\n%s", diff)
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range []string{"09-secrets/gitops-webhook-secret.yaml", "09-secrets/webhook-secret-tst-dev-http-api.yaml"} {
+		if _, ok := r[filepath.Join("config/tst-cicd/base", v)].(*secrets.ExternalSecret); !ok {
+			t.Errorf("failed to find an external secret at %s", v)
+		}
+		if !containsString(k.Resources, v) {
+			t.Errorf("base kustomization does not include %s", v)
+		}
+	}
+}
+
+func TestBootstrapManifestWithGitea(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("gitea.example.com", "gitea"))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitea.example.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitea.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		PrivateRepoDriver:    "gitea",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if diff := cmp.Diff(map[string]string{"gitea.example.com": "gitea"}, m.Config.Git.Drivers); diff != "" {
+		t.Fatalf("git drivers failed:\n%s", diff)
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range []string{"05-bindings/gitea-push-binding.yaml", "05-bindings/gitea-pull-request-binding.yaml"} {
+		if !containsString(k.Resources, v) {
+			t.Errorf("kustomization does not include %s", v)
+		}
+	}
+}
+
+func TestBootstrapWithMissingSealedSecretsCert(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		SealedSecretsCert:    "/tmp/missing.pem",
+	}
+	_, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	test.AssertErrorMatch(t, "failed to read sealed secrets certificate", err)
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
 func TestBootstrapCreatesRepository(t *testing.T) {
 	params := &BootstrapOptions{
 		Prefix:               "tst-",
@@ -166,12 +323,165 @@ func TestBootstrapCreatesRepository(t *testing.T) {
 	fatalIfError(t, err)
 }
 
-func TestOrgRepoFromURL(t *testing.T) {
-	want := "my-org/gitops"
-	got, err := orgRepoFromURL(testGitOpsRepo)
+func TestBootstrapWithSSHURLs(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	err := Bootstrap(&BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "git@github.com:my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "ssh://git@github.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+	}, fakeFs)
 	fatalIfError(t, err)
-	if got != want {
-		t.Fatalf("orgRepFromURL(%s) got %s, want %s", testGitOpsRepo, got, want)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if m.GitOpsURL != testGitOpsRepo {
+		t.Fatalf("GitOpsURL got %s, want %s", m.GitOpsURL, testGitOpsRepo)
+	}
+	if svc := m.GetEnvironment("tst-dev").Apps[0].Services[0]; svc.SourceURL != testSvcRepo {
+		t.Fatalf("SourceURL got %s, want %s", svc.SourceURL, testSvcRepo)
+	}
+}
+
+func TestBootstrapWithTektonV1(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	err := Bootstrap(&BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+		TektonAPI:            config.TektonAPIV1,
+	}, fakeFs)
+	fatalIfError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if api := m.GetPipelinesConfig().GetTektonAPI(); api != config.TektonAPIV1 {
+		t.Fatalf("GetTektonAPI() got %s, want %s", api, config.TektonAPIV1)
+	}
+
+	wantAPIVersions := map[string]string{
+		"Pipeline":        "tekton.dev/v1",
+		"Task":            "tekton.dev/v1",
+		"EventListener":   "triggers.tekton.dev/v1beta1",
+		"TriggerBinding":  "triggers.tekton.dev/v1beta1",
+		"TriggerTemplate": "triggers.tekton.dev/v1beta1",
+	}
+	found := map[string]bool{}
+	err = afero.Walk(fakeFs, "/gitops/config/tst-cicd/base", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		var typeMeta metav1.TypeMeta
+		if err := yaml.UnmarshalItemFromFile(fakeFs, path, &typeMeta); err != nil {
+			return err
+		}
+		want, ok := wantAPIVersions[typeMeta.Kind]
+		if !ok {
+			return nil
+		}
+		found[typeMeta.Kind] = true
+		if typeMeta.APIVersion != want {
+			t.Errorf("%s got apiVersion %s, want %s", path, typeMeta.APIVersion, want)
+		}
+		return nil
+	})
+	fatalIfError(t, err)
+	if len(found) != len(wantAPIVersions) {
+		t.Fatalf("found kinds %v, want %v", found, wantAPIVersions)
+	}
+}
+
+func TestBootstrapWithKubernetesPlatform(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	err := Bootstrap(&BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "quay.io/my-org/http-api",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+		Platform:             config.PlatformKubernetes,
+		IngressClass:         "nginx",
+		IngressHost:          "{{.Name}}-{{.Namespace}}.apps.example.com",
+	}, fakeFs)
+	fatalIfError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if platform := m.GetPlatform(); platform != config.PlatformKubernetes {
+		t.Fatalf("GetPlatform() got %s, want %s", platform, config.PlatformKubernetes)
+	}
+
+	hosts := map[string]string{
+		"/gitops/config/tst-cicd/base/08-ingresses/gitops-webhook-event-listener.yaml":                  "gitops-webhook-event-listener-tst-cicd.apps.example.com",
+		"/gitops/environments/tst-dev/apps/app-http-api/services/http-api/base/config/300-ingress.yaml": "http-api-tst-dev.apps.example.com",
+	}
+	for path, want := range hosts {
+		var ingress networkingv1.Ingress
+		fatalIfError(t, yaml.UnmarshalItemFromFile(fakeFs, path, &ingress))
+		if got := ingress.Spec.Rules[0].Host; got != want {
+			t.Errorf("%s got host %s, want %s", path, got, want)
+		}
+		if class := ingress.Spec.IngressClassName; class == nil || *class != "nginx" {
+			t.Errorf("%s got IngressClass %v, want nginx", path, class)
+		}
+	}
+	for _, path := range []string{
+		"/gitops/config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml",
+		"/gitops/environments/tst-dev/apps/app-http-api/services/http-api/base/config/300-route.yaml",
+	} {
+		if exists, _ := ioutils.IsExisting(fakeFs, path); exists {
+			t.Errorf("%s exists on the kubernetes platform", path)
+		}
+	}
+}
+
+func TestBootstrapWithKubernetesPlatformAndInternalRegistry(t *testing.T) {
+	imageRepoTests := []struct {
+		imageRepo string
+		wantErr   string
+	}{
+		{"", "--image-repo is required on the kubernetes platform"},
+		{"image/repo", "the internal registry is only available on OpenShift"},
+	}
+
+	for _, tt := range imageRepoTests {
+		_, _, err := bootstrapResources(&BootstrapOptions{
+			Prefix:               "tst-",
+			GitOpsRepoURL:        testGitOpsRepo,
+			ImageRepo:            tt.imageRepo,
+			GitOpsWebhookSecret:  "123",
+			ServiceRepoURL:       testSvcRepo,
+			ServiceWebhookSecret: "456",
+			Platform:             config.PlatformKubernetes,
+		}, ioutils.NewMemoryFilesystem())
+		test.AssertErrorMatch(t, tt.wantErr, err)
+	}
+}
+
+func TestOrgRepoFromURL(t *testing.T) {
+	urlTests := []struct {
+		url  string
+		want string
+	}{
+		{testGitOpsRepo, "my-org/gitops"},
+		{"https://gitlab.com/group/subgroup/team/gitops.git", "group/subgroup/team/gitops"},
+	}
+
+	for _, tt := range urlTests {
+		got, err := orgRepoFromURL(tt.url)
+		fatalIfError(t, err)
+		if got != tt.want {
+			t.Fatalf("orgRepFromURL(%s) got %s, want %s", tt.url, got, tt.want)
+		}
 	}
 }
 
@@ -267,13 +577,13 @@ func TestInitialFiles(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	repo, err := scm.NewRepository(gitOpsURL)
 	assertNoError(t, err)
-	got, _, err := createInitialFiles(fakeFs, repo, &o)
+	got, _, err := createInitialFiles(fakeFs, repo, &o, &secretOutputs{mode: config.SecretsModeRaw})
 	assertNoError(t, err)
 
 	want := res.Resources{
 		pipelinesFile: createManifest(gitOpsURL, &config.Config{Pipelines: testpipelineConfig}),
 	}
-	resources, _, err := createCICDResources(fakeFs, repo, testpipelineConfig, &o)
+	resources, _, err := createCICDResources(fakeFs, repo, testpipelineConfig, &o, &secretOutputs{mode: config.SecretsModeRaw})
 	if err != nil {
 		t.Fatalf("CreatePipelineResources() failed due to :%s\n", err)
 	}
@@ -312,7 +622,7 @@ func TestGenerateSecrets(t *testing.T) {
 		ServiceRepoURL:     "https://gl.example.com/my-org/my-project.git",
 	}
 
-	err := generateSecrets(outputs, otherOutputs, sa, ns, o)
+	err := generateSecrets(outputs, otherOutputs, sa, ns, o, &secretOutputs{mode: config.SecretsModeRaw})
 	fatalIfError(t, err)
 
 	wantSA := &corev1.ServiceAccount{
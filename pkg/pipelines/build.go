@@ -1,10 +1,14 @@
 package pipelines
 
 import (
+	"path/filepath"
+	"reflect"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/environments"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
 )
@@ -22,15 +26,35 @@ func BuildResources(o *BuildParameters, appFs afero.Fs) error {
 	if err != nil {
 		return err
 	}
-	resources, err := buildResources(appFs, m)
+	recorded := recordedCatalogTasks(m)
+	resources, err := buildResources(appFs, o.PipelinesFolderPath, m)
 	if err != nil {
 		return err
 	}
 	_, err = yaml.WriteResources(appFs, o.OutputPath, resources)
-	return err
+	if err != nil {
+		return err
+	}
+	// Catalog Tasks for newly used build strategies are created at the pinned
+	// versions, these are recorded in the manifest so that later builds keep
+	// them.
+	if cfg := m.GetPipelinesConfig(); cfg != nil && !reflect.DeepEqual(recorded, cfg.CatalogTasks) {
+		if _, err := yaml.WriteResources(appFs, o.PipelinesFolderPath, res.Resources{pipelinesFile: m}); err != nil {
+			return err
+		}
+	}
+	// The AppCIPipelines for the build strategies in use are generated, so the
+	// CI/CD kustomization must be updated to include them.
+	if cfg := m.GetPipelinesConfig(); cfg != nil && m.GitOpsURL != "" {
+		base := filepath.ToSlash(filepath.Join(o.OutputPath, config.PathForPipelines(cfg), "base"))
+		return updateKustomization(appFs, base)
+	}
+	return nil
 }
 
-func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
+// buildResources builds the resources for the manifest, existing resources are
+// read from the GitOps repository in root.
+func buildResources(fs afero.Fs, root string, m *config.Manifest) (res.Resources, error) {
 	resources := res.Resources{}
 
 	argoCD := m.GetArgoCDConfig()
@@ -56,5 +80,12 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 		return nil, err
 	}
 	resources = res.Merge(argoApps, resources)
-	return resources, nil
+	if m.GetPipelinesConfig().GetTektonAPI() != config.TektonAPIV1 {
+		return resources, nil
+	}
+	v1Files, err := buildTektonV1Resources(fs, root, m)
+	if err != nil {
+		return nil, err
+	}
+	return tekton.ConvertResources(res.Merge(v1Files, resources))
 }
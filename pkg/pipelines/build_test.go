@@ -0,0 +1,68 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	tektonpipelines "github.com/redhat-developer/kam/pkg/pipelines/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildResourcesRegeneratesAppCIResources(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	base := "/gitops/config/tst-cicd/base"
+
+	// GitOps repositories bootstrapped by earlier releases have a
+	// TriggerTemplate and AppCIPipeline without the build parameters.
+	template := triggers.CreateDevCIBuildPRTemplate("tst-cicd", saName)
+	for i, p := range template.Spec.Params {
+		if p.Name == triggers.ContextPath {
+			template.Spec.Params = template.Spec.Params[:i]
+			break
+		}
+	}
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join(base, appCIPushTemplatePath), template))
+	pipeline := tektonpipelines.CreateAppCIPipeline(meta.NamespacedName("tst-cicd", "app-ci-pipeline"))
+	pipeline.Spec.Params = pipeline.Spec.Params[:len(pipeline.Spec.Params)-3]
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join(base, appCiPipelinesPath), pipeline))
+
+	err := BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs)
+	assertNoError(t, err)
+
+	var gotTemplate triggersv1.TriggerTemplate
+	assertNoError(t, yaml.UnmarshalItemFromFile(fakeFs, filepath.Join(base, appCIPushTemplatePath), &gotTemplate))
+	if diff := cmp.Diff(triggers.CreateDevCIBuildPRTemplate("tst-cicd", saName).Spec.Params, gotTemplate.Spec.Params); diff != "" {
+		t.Fatalf("the TriggerTemplate was not regenerated:\n%s", diff)
+	}
+	var gotPipeline pipelinev1.Pipeline
+	assertNoError(t, yaml.UnmarshalItemFromFile(fakeFs, filepath.Join(base, appCiPipelinesPath), &gotPipeline))
+	if diff := cmp.Diff(tektonpipelines.CreateAppCIPipeline(meta.NamespacedName("tst-cicd", "app-ci-pipeline")).Spec.Params, gotPipeline.Spec.Params); diff != "" {
+		t.Fatalf("the AppCIPipeline was not regenerated:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesConvertsExistingTriggerBindings(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	m.Config.Pipelines.TektonAPI = config.TektonAPIV1
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join("/gitops", pipelinesFile), m))
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs)
+	assertNoError(t, err)
+
+	// The binding for the image of the service is not generated from the
+	// manifest, so it must be read from the GitOps repository to be converted.
+	var typeMeta metav1.TypeMeta
+	assertNoError(t, yaml.UnmarshalItemFromFile(fakeFs, "/gitops/config/tst-cicd/base/05-bindings/tst-dev-app-http-api-http-api-binding.yaml", &typeMeta))
+	if typeMeta.APIVersion != "triggers.tekton.dev/v1beta1" {
+		t.Fatalf("the TriggerBinding was not converted, got apiVersion %s", typeMeta.APIVersion)
+	}
+}
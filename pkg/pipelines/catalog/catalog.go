@@ -0,0 +1,76 @@
+// Package catalog provides pinned copies of the Tasks from the Tekton catalog
+// that the generated Pipelines use.
+//
+// The Tasks are written to the GitOps repository as namespaced Tasks, rather
+// than relying on the ClusterTasks that are installed in the cluster, whose
+// versions vary between clusters.
+package catalog
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// The Tasks are stored in the same layout as the Tekton catalog,
+// tasks/<name>/<version>/<name>.yaml.
+//
+//go:embed tasks
+var tasks embed.FS
+
+// These are the Tasks that the generated Pipelines use.
+const (
+	GitClone   = "git-clone"
+	Buildah    = "buildah"
+	S2I        = "s2i"
+	Buildpacks = "buildpacks"
+	Kaniko     = "kaniko"
+)
+
+// pinnedVersions are the versions of the Tasks that are written when no
+// version is recorded in the manifest, and that the Tasks are updated to.
+var pinnedVersions = map[string]string{
+	GitClone:   "0.4",
+	Buildah:    "0.2",
+	S2I:        "0.2",
+	Buildpacks: "0.3",
+	Kaniko:     "0.5",
+}
+
+// Names returns the names of the Tasks in the catalog, sorted by name.
+func Names() []string {
+	names := make([]string, 0, len(pinnedVersions))
+	for k := range pinnedVersions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PinnedVersion returns the pinned version of a Task, or "" if the Task is not
+// in the catalog.
+func PinnedVersion(name string) string {
+	return pinnedVersions[name]
+}
+
+// Task returns a version of a Task from the catalog, in the provided
+// namespace.
+func Task(name, version, namespace string) (*pipelinev1.Task, error) {
+	if _, ok := pinnedVersions[name]; !ok {
+		return nil, fmt.Errorf("task %s is not in the catalog", name)
+	}
+	data, err := tasks.ReadFile(path.Join("tasks", name, version, name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("version %q of task %s is not in the catalog", version, name)
+	}
+	var task pipelinev1.Task
+	if err := yaml.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task %s version %s: %w", name, version, err)
+	}
+	task.Namespace = namespace
+	return &task, nil
+}
@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestTask(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(rt *testing.T) {
+			task, err := Task(name, PinnedVersion(name), "cicd")
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if task.Name != name {
+				rt.Errorf("Task() got name %s, want %s", task.Name, name)
+			}
+			if task.Namespace != "cicd" {
+				rt.Errorf("Task() got namespace %s, want cicd", task.Namespace)
+			}
+			if v := task.Labels["app.kubernetes.io/version"]; v != PinnedVersion(name) {
+				rt.Errorf("Task() got version label %s, want %s", v, PinnedVersion(name))
+			}
+			if len(task.Spec.Steps) == 0 {
+				rt.Error("Task() has no steps")
+			}
+		})
+	}
+}
+
+func TestTaskWithUnknownVersion(t *testing.T) {
+	_, err := Task(GitClone, "0.1", "cicd")
+	test.AssertErrorMatch(t, `version "0.1" of task git-clone is not in the catalog`, err)
+}
+
+func TestTaskWithUnknownTask(t *testing.T) {
+	_, err := Task("golang-test", "0.1", "cicd")
+	test.AssertErrorMatch(t, "task golang-test is not in the catalog", err)
+}
+
+func TestNames(t *testing.T) {
+	want := []string{"buildah", "buildpacks", "git-clone", "kaniko", "s2i"}
+	if diff := cmp.Diff(want, Names()); diff != "" {
+		t.Fatalf("Names() failed:\n%s", diff)
+	}
+}
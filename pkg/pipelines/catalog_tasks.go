@@ -0,0 +1,134 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/catalog"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// defaultCatalogTasks are the Tasks from the Tekton catalog that are used by
+// the Pipelines created at bootstrap.
+var defaultCatalogTasks = []string{catalog.GitClone, catalog.Buildah}
+
+// strategyCatalogTasks are the Tasks from the Tekton catalog that build images
+// for each build strategy.
+var strategyCatalogTasks = map[string]string{
+	config.BuildStrategyBuildah:    catalog.Buildah,
+	config.BuildStrategyS2I:        catalog.S2I,
+	config.BuildStrategyBuildpacks: catalog.Buildpacks,
+	config.BuildStrategyKaniko:     catalog.Kaniko,
+}
+
+// UpdateCatalogTasksOptions control which catalog Tasks are updated.
+type UpdateCatalogTasksOptions struct {
+	PipelinesFolderPath string
+	Tasks               []string // The names of the Tasks to update, if empty, all the recorded Tasks are updated.
+}
+
+// CatalogTaskUpdate records the change in version of a catalog Task.
+type CatalogTaskUpdate struct {
+	Name        string
+	FromVersion string
+	ToVersion   string
+}
+
+// UpdateCatalogTasks is the entry-point from the CLI for updating the Tasks
+// from the Tekton catalog to the versions pinned by this release.
+//
+// The new versions are recorded in the manifest, and the Tasks in the CI/CD
+// environment are rewritten, the Tasks that were updated are returned.
+func UpdateCatalogTasks(o *UpdateCatalogTasksOptions, appFs afero.Fs) ([]CatalogTaskUpdate, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a pipelines configuration in %s", o.PipelinesFolderPath)
+	}
+	names := o.Tasks
+	if len(names) == 0 {
+		for k := range cfg.CatalogTasks {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+	}
+	updates := []CatalogTaskUpdate{}
+	for _, name := range names {
+		pinned := catalog.PinnedVersion(name)
+		if pinned == "" {
+			return nil, fmt.Errorf("task %s is not in the catalog", name)
+		}
+		if cfg.CatalogTasks[name] == pinned {
+			continue
+		}
+		updates = append(updates, CatalogTaskUpdate{Name: name, FromVersion: cfg.CatalogTasks[name], ToVersion: pinned})
+		if cfg.CatalogTasks == nil {
+			cfg.CatalogTasks = map[string]string{}
+		}
+		cfg.CatalogTasks[name] = pinned
+	}
+	files := res.Resources{pipelinesFile: m}
+	built, err := buildResources(appFs, o.PipelinesFolderPath, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resources: %v", err)
+	}
+	files = res.Merge(built, files)
+	if _, err := yaml.WriteResources(appFs, o.PipelinesFolderPath, files); err != nil {
+		return nil, err
+	}
+	base := filepath.ToSlash(filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base"))
+	return updates, updateKustomization(appFs, base)
+}
+
+// createCatalogTasks creates the named Tasks from the Tekton catalog, at the
+// versions recorded in the configuration, the paths are relative to the base
+// of the CI/CD environment.
+//
+// Tasks without a recorded version are created at the pinned version, which
+// is recorded in the configuration, so that the version is kept when the
+// manifest is written.
+func createCatalogTasks(cfg *config.PipelinesConfig, names ...string) (res.Resources, error) {
+	files := res.Resources{}
+	for _, name := range names {
+		version := cfg.CatalogTasks[name]
+		if version == "" {
+			version = catalog.PinnedVersion(name)
+			if cfg.CatalogTasks == nil {
+				cfg.CatalogTasks = map[string]string{}
+			}
+			cfg.CatalogTasks[name] = version
+		}
+		task, err := catalog.Task(name, version, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		files[catalogTaskPath(name)] = task
+	}
+	return files, nil
+}
+
+// recordedCatalogTasks returns a copy of the versions of the catalog Tasks
+// recorded in the manifest.
+func recordedCatalogTasks(m *config.Manifest) map[string]string {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || cfg.CatalogTasks == nil {
+		return nil
+	}
+	recorded := map[string]string{}
+	for k, v := range cfg.CatalogTasks {
+		recorded[k] = v
+	}
+	return recorded
+}
+
+func catalogTaskPath(name string) string {
+	return filepath.ToSlash(filepath.Join("03-tasks", name+"-task.yaml"))
+}
@@ -0,0 +1,98 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/environments"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestBootstrapRecordsCatalogTasks(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := map[string]string{"buildah": "0.2", "git-clone": "0.4"}
+	if diff := cmp.Diff(want, m.GetPipelinesConfig().CatalogTasks); diff != "" {
+		t.Fatalf("catalog tasks didn't match:\n%s", diff)
+	}
+	assertExists(t, fakeFs,
+		"/gitops/config/tst-cicd/base/03-tasks/buildah-task.yaml",
+		"/gitops/config/tst-cicd/base/03-tasks/git-clone-task.yaml")
+}
+
+func TestBuildRecordsCatalogTasks(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	m.GetEnvironment("tst-dev").Apps[0].Services[0].Build = &config.Build{Strategy: config.BuildStrategyKaniko}
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, "/gitops/pipelines.yaml", m))
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs)
+	assertNoError(t, err)
+
+	m, err = config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := map[string]string{"buildah": "0.2", "git-clone": "0.4", "kaniko": "0.5"}
+	if diff := cmp.Diff(want, m.GetPipelinesConfig().CatalogTasks); diff != "" {
+		t.Fatalf("catalog tasks didn't match:\n%s", diff)
+	}
+	assertExists(t, fakeFs, "/gitops/config/tst-cicd/base/03-tasks/kaniko-task.yaml")
+}
+
+func TestUpdateCatalogTasks(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	updates, err := UpdateCatalogTasks(&UpdateCatalogTasksOptions{
+		PipelinesFolderPath: "/gitops",
+		Tasks:               []string{"kaniko", "git-clone"},
+	}, fakeFs)
+	assertNoError(t, err)
+
+	wantUpdates := []CatalogTaskUpdate{{Name: "kaniko", ToVersion: "0.5"}}
+	if diff := cmp.Diff(wantUpdates, updates); diff != "" {
+		t.Fatalf("UpdateCatalogTasks() failed:\n%s", diff)
+	}
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := map[string]string{"buildah": "0.2", "git-clone": "0.4", "kaniko": "0.5"}
+	if diff := cmp.Diff(want, m.GetPipelinesConfig().CatalogTasks); diff != "" {
+		t.Fatalf("catalog tasks didn't match:\n%s", diff)
+	}
+	files, err := environments.ListFiles(fakeFs, "/gitops/config/tst-cicd/base")
+	assertNoError(t, err)
+	if !files["03-tasks/kaniko-task.yaml"] {
+		t.Fatalf("kaniko task was not written: %v", files.Items())
+	}
+	var k res.Kustomization
+	assertNoError(t, yaml.UnmarshalItemFromFile(fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml", &k))
+	if diff := cmp.Diff(files.Items(), k.Resources); diff != "" {
+		t.Fatalf("kustomization didn't match:\n%s", diff)
+	}
+}
+
+func TestUpdateCatalogTasksWithNoChanges(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	updates, err := UpdateCatalogTasks(&UpdateCatalogTasksOptions{PipelinesFolderPath: "/gitops"}, fakeFs)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff([]CatalogTaskUpdate{}, updates); diff != "" {
+		t.Fatalf("UpdateCatalogTasks() failed:\n%s", diff)
+	}
+}
+
+func TestUpdateCatalogTasksWithUnknownTask(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	_, err := UpdateCatalogTasks(&UpdateCatalogTasksOptions{
+		PipelinesFolderPath: "/gitops",
+		Tasks:               []string{"golang-test"},
+	}, fakeFs)
+	test.AssertErrorMatch(t, "task golang-test is not in the catalog", err)
+}
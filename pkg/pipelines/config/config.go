@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
+	"text/template"
 )
 
 const (
@@ -36,6 +39,20 @@ func PathForArgoCD() string {
 	return filepath.Join("config", "argocd")
 }
 
+// CleanSourcePath returns the source path of a service in a canonical form,
+// relative to the root of the repository without a trailing "/", the root of
+// the repository is represented by "".
+func CleanSourcePath(p string) string {
+	if p == "" {
+		return ""
+	}
+	cleaned := strings.TrimPrefix(path.Clean(filepath.ToSlash(p)), "/")
+	if cleaned == "." {
+		return ""
+	}
+	return cleaned
+}
+
 // Manifest describes a set of environments, apps and services for deployment.
 type Manifest struct {
 	GitOpsURL    string         `json:"gitops_url,omitempty"`
@@ -85,6 +102,56 @@ func (m *Manifest) AddService(envName, appName string, svc *Service) error {
 	return nil
 }
 
+// AddApplication adds a new application to a specific environment.
+func (m *Manifest) AddApplication(envName string, app *Application) error {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return fmt.Errorf("environment %s does not exist", envName)
+	}
+	if m.GetApplication(envName, app.Name) != nil {
+		return fmt.Errorf("application %s already exists in environment %s", app.Name, envName)
+	}
+	env.Apps = append(env.Apps, app)
+	return nil
+}
+
+// RemoveService removes a service from an environment, it returns the
+// application that the service was removed from.
+//
+// If the application has no remaining services, it's also removed from the
+// environment.
+func (m *Manifest) RemoveService(envName, serviceName string) (*Application, error) {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return nil, fmt.Errorf("environment %s does not exist", envName)
+	}
+	for i, app := range env.Apps {
+		for j, svc := range app.Services {
+			if svc.Name != serviceName {
+				continue
+			}
+			app.Services = append(app.Services[:j], app.Services[j+1:]...)
+			if len(app.Services) == 0 && app.ConfigRepo == nil {
+				env.Apps = append(env.Apps[:i], env.Apps[i+1:]...)
+			}
+			return app, nil
+		}
+	}
+	return nil, fmt.Errorf("service %s does not exist in environment %s", serviceName, envName)
+}
+
+// RemoveEnvironment removes a named environment, returning the removed
+// environment.
+func (m *Manifest) RemoveEnvironment(envName string) (*Environment, error) {
+	for i, env := range m.Environments {
+		if env.Name == envName {
+			m.Environments = append(m.Environments[:i], m.Environments[i+1:]...)
+			return env, nil
+		}
+	}
+	return nil, fmt.Errorf("environment %s does not exist", envName)
+}
+
 // GetPipelinesConfig returns the global Pipelines configuration, if one exists.
 func (m *Manifest) GetPipelinesConfig() *PipelinesConfig {
 	if m.Config != nil {
@@ -93,6 +160,30 @@ func (m *Manifest) GetPipelinesConfig() *PipelinesConfig {
 	return nil
 }
 
+// GetSecretsConfig returns the global Secrets configuration, if one exists.
+func (m *Manifest) GetSecretsConfig() *SecretsConfig {
+	if m.Config != nil {
+		return m.Config.Secrets
+	}
+	return nil
+}
+
+// GetPlatform returns the configured platform, defaulting to "openshift".
+func (m *Manifest) GetPlatform() string {
+	if m.Config == nil || m.Config.Platform == "" {
+		return PlatformOpenShift
+	}
+	return m.Config.Platform
+}
+
+// GetIngressConfig returns the global Ingress configuration, if one exists.
+func (m *Manifest) GetIngressConfig() *IngressConfig {
+	if m.Config != nil {
+		return m.Config.Ingress
+	}
+	return nil
+}
+
 // GetArgoCDConfig returns the global ArgoCD configuration, if one exists.
 func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 	if m.Config != nil {
@@ -115,11 +206,82 @@ type Config struct {
 	Pipelines *PipelinesConfig `json:"pipelines,omitempty"`
 	ArgoCD    *ArgoCDConfig    `json:"argocd,omitempty"`
 	Git       *GitConfig       `json:"git,omitempty"`
+	Secrets   *SecretsConfig   `json:"secrets,omitempty"`
+	// Platform is the platform that the resources are generated for, one of
+	// "openshift" or "kubernetes", if omitted, "openshift" is used.
+	Platform string `json:"platform,omitempty"`
+	// Ingress configures the Ingresses that expose Services on the
+	// "kubernetes" platform.
+	Ingress *IngressConfig `json:"ingress,omitempty"`
+}
+
+// These are the supported platforms.
+const (
+	// PlatformOpenShift exposes Services with Routes, and can push images to
+	// the internal registry.
+	PlatformOpenShift = "openshift"
+	// PlatformKubernetes exposes Services with networking.k8s.io/v1
+	// Ingresses.
+	PlatformKubernetes = "kubernetes"
+)
+
+// IngressConfig provides configuration for the generated Ingresses.
+type IngressConfig struct {
+	// Class is the name of the IngressClass of the Ingresses.
+	Class string `json:"class,omitempty"`
+	// Host is a template for the host of each Ingress, executed with the
+	// .Name and .Namespace of the Ingress e.g.
+	// "{{.Name}}-{{.Namespace}}.apps.example.com", if omitted, the Ingresses
+	// match all hosts.
+	Host string `json:"host,omitempty"`
+}
+
+// HostFor returns the host of the named Ingress, it's empty if no host
+// template is configured.
+func (i *IngressConfig) HostFor(name, namespace string) (string, error) {
+	if i == nil || i.Host == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("host").Option("missingkey=error").Parse(i.Host)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the ingress host template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, struct{ Name, Namespace string }{name, namespace}); err != nil {
+		return "", fmt.Errorf("failed to execute the ingress host template: %w", err)
+	}
+	return b.String(), nil
 }
 
 // PipelinesConfig provides configuration for the CI/CD pipelines.
 type PipelinesConfig struct {
 	Name string `json:"name,omitempty"`
+	// TektonAPI is the level of the Tekton APIs that the pipelines are
+	// generated for, one of "v1beta1" or "v1", if omitted, "v1beta1" is used.
+	TektonAPI string `json:"tekton_api,omitempty"`
+	// CatalogTasks records the versions of the Tasks from the Tekton catalog
+	// that are written to the CI/CD environment, keyed by the name of the
+	// Task.
+	CatalogTasks map[string]string `json:"catalog_tasks,omitempty"`
+}
+
+// These are the supported levels of the Tekton APIs.
+const (
+	// TektonAPIV1Beta1 generates tekton.dev/v1beta1 Pipelines and Tasks, and
+	// triggers.tekton.dev/v1alpha1 Triggers, with PipelineResources.
+	TektonAPIV1Beta1 = "v1beta1"
+	// TektonAPIV1 generates tekton.dev/v1 Pipelines and Tasks, and
+	// triggers.tekton.dev/v1beta1 Triggers, with workspaces.
+	TektonAPIV1 = "v1"
+)
+
+// GetTektonAPI returns the configured Tekton API level, defaulting to
+// "v1beta1".
+func (p *PipelinesConfig) GetTektonAPI() string {
+	if p == nil || p.TektonAPI == "" {
+		return TektonAPIV1Beta1
+	}
+	return p.TektonAPI
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
@@ -127,6 +289,46 @@ type ArgoCDConfig struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// These are the supported modes for generating secrets.
+const (
+	// SecretsModeRaw writes plain secrets outside of the GitOps repository.
+	SecretsModeRaw = "raw"
+	// SecretsModeSealed writes SealedSecrets to the GitOps repository.
+	SecretsModeSealed = "sealed"
+	// SecretsModeExternal writes ExternalSecrets that reference values in an
+	// external secret store to the GitOps repository.
+	SecretsModeExternal = "external"
+)
+
+// SecretsConfig configures how the secrets needed by the pipelines are
+// generated.
+type SecretsConfig struct {
+	// Mode is one of "raw", "sealed" or "external", if omitted, "raw" is used.
+	Mode string `json:"mode,omitempty"`
+	// Store is the SecretStore that ExternalSecrets read values from.
+	Store *SecretStoreRef `json:"store,omitempty"`
+	// KeyPath is the path in the external secret store under which values are
+	// read.
+	KeyPath string `json:"key_path,omitempty"`
+	// SealedSecretsCert is the path to the Sealed Secrets controller
+	// certificate that secrets are sealed with in the "sealed" mode.
+	SealedSecretsCert string `json:"sealed_secrets_cert,omitempty"`
+}
+
+// SecretStoreRef refers to a SecretStore or ClusterSecretStore.
+type SecretStoreRef struct {
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// GetMode returns the configured secrets mode, defaulting to "raw".
+func (s *SecretsConfig) GetMode() string {
+	if s == nil || s.Mode == "" {
+		return SecretsModeRaw
+	}
+	return s.Mode
+}
+
 // GitConfig configures the git drivers.
 type GitConfig struct {
 	Drivers map[string]string `json:"drivers,omitempty"`
@@ -148,10 +350,75 @@ type Application struct {
 
 // Service has an upstream source.
 type Service struct {
-	Name      string     `json:"name,omitempty"`
-	Webhook   *Webhook   `json:"webhook,omitempty"`
-	SourceURL string     `json:"source_url,omitempty"`
-	Pipelines *Pipelines `json:"pipelines,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Webhook   *Webhook `json:"webhook,omitempty"`
+	SourceURL string   `json:"source_url,omitempty"`
+	// SourcePath is the directory within the source repository that the
+	// service is built from, this allows multiple services to be built from
+	// the same repository.
+	SourcePath string     `json:"source_path,omitempty"`
+	Pipelines  *Pipelines `json:"pipelines,omitempty"`
+	Build      *Build     `json:"build,omitempty"`
+	// Triggers filters the pushes that build the service, by the pushed
+	// branch or tag.
+	Triggers *Triggers `json:"triggers,omitempty"`
+}
+
+// Triggers filters the pushes that trigger a build, if omitted, all pushes
+// trigger a build.
+type Triggers struct {
+	Branches *BranchFilter `json:"branches,omitempty"`
+	// Tags is a glob pattern of the tags that are built, if omitted, pushed
+	// tags are only built when there is no filter for the branches.
+	Tags string `json:"tags,omitempty"`
+}
+
+// BranchFilter filters the pushed branches with glob patterns, "*" matches
+// within a path segment, and "**" matches across segments e.g. "release/**".
+type BranchFilter struct {
+	// Include are the branches that are built, if empty, all branches are
+	// built.
+	Include []string `json:"include,omitempty"`
+	// Exclude are the branches that are not built, this takes precedence over
+	// Include.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// GetBuildStrategy returns the strategy used to build the image for the
+// service, defaulting to BuildStrategyBuildah.
+func (s *Service) GetBuildStrategy() string {
+	if s.Build == nil || s.Build.Strategy == "" {
+		return BuildStrategyBuildah
+	}
+	return s.Build.Strategy
+}
+
+const (
+	// BuildStrategyBuildah builds images from a Dockerfile with buildah, this
+	// is the default.
+	BuildStrategyBuildah = "buildah"
+	// BuildStrategyS2I builds images from source with a Source-to-Image
+	// builder image, no Dockerfile is needed.
+	BuildStrategyS2I = "s2i"
+	// BuildStrategyBuildpacks builds images from source with a Cloud Native
+	// Buildpacks builder image, no Dockerfile is needed.
+	BuildStrategyBuildpacks = "buildpacks"
+	// BuildStrategyKaniko builds images from a Dockerfile with kaniko.
+	BuildStrategyKaniko = "kaniko"
+)
+
+// Build configures how the image for a service is built.
+type Build struct {
+	// Strategy is one of buildah, s2i, buildpacks or kaniko.
+	Strategy string `json:"strategy,omitempty"`
+	// Dockerfile is the path to the Dockerfile, relative to the source path,
+	// for the buildah and kaniko strategies.
+	Dockerfile string `json:"dockerfile,omitempty"`
+	// BuilderImage is the builder image for the s2i and buildpacks strategies.
+	BuilderImage string `json:"builder_image,omitempty"`
+	// BuildArgs are passed as build arguments for the buildah and kaniko
+	// strategies.
+	BuildArgs map[string]string `json:"build_args,omitempty"`
 }
 
 // Webhook provides Github webhook secret for eventlisteners
@@ -180,6 +447,65 @@ type Repository struct {
 // These pipelines will be executed with a Git clone URL and commit SHA.
 type Pipelines struct {
 	Integration *TemplateBinding `json:"integration,omitempty"`
+	// Tests are run in order in the CI pipeline, after the source is cloned
+	// and before the image is built, a failing test fails the pipeline.
+	Tests []*TestStep `json:"tests,omitempty"`
+	// UpdateGitOps updates the image of the service in the GitOps repository
+	// after the image is built from a push.
+	UpdateGitOps *UpdateGitOps `json:"update_gitops,omitempty"`
+	// Workspaces configures the storage for the workspaces of the CI
+	// pipeline.
+	Workspaces *Workspaces `json:"workspaces,omitempty"`
+}
+
+// Workspaces configures the storage for the workspaces of the CI pipeline.
+type Workspaces struct {
+	// Source is the workspace that the source is cloned into, if omitted, a
+	// 1Gi ReadWriteOnce volume is claimed for each PipelineRun.
+	Source *WorkspaceStorage `json:"source,omitempty"`
+	// Cache is a workspace that is shared between PipelineRuns, for caching
+	// dependencies in tests and builds, it must be an existing
+	// PersistentVolumeClaim.
+	Cache *WorkspaceStorage `json:"cache,omitempty"`
+}
+
+// WorkspaceStorage is the storage for a workspace, this is either a volume
+// that is claimed for each PipelineRun, or an existing PersistentVolumeClaim.
+type WorkspaceStorage struct {
+	// Size is the size of the claimed volume, this defaults to 1Gi.
+	Size string `json:"size,omitempty"`
+	// StorageClass is the storage class of the claimed volume, if omitted,
+	// the default storage class is used.
+	StorageClass string `json:"storage_class,omitempty"`
+	// AccessMode is the access mode of the claimed volume, this defaults to
+	// ReadWriteOnce.
+	AccessMode string `json:"access_mode,omitempty"`
+	// ClaimName is the name of an existing PersistentVolumeClaim in the CI/CD
+	// namespace.
+	ClaimName string `json:"claim_name,omitempty"`
+	// EmptyDir is not supported, an emptyDir is only available within a
+	// single Task, and is not shared between the Tasks of a Pipeline.
+	EmptyDir bool `json:"empty_dir,omitempty"`
+}
+
+// UpdateGitOps configures how the GitOps repository is updated with newly
+// built images.
+type UpdateGitOps struct {
+	// Branch is the branch of the GitOps repository to update, this defaults
+	// to "main".
+	Branch string `json:"branch,omitempty"`
+	// PullRequest opens a pull request against the Branch, rather than
+	// pushing to it.
+	PullRequest bool `json:"pull_request,omitempty"`
+}
+
+// TestStep is a test, or lint, step in the CI pipeline, the script is run in
+// the image with the cloned source as the working directory.
+type TestStep struct {
+	Name   string            `json:"name,omitempty"`
+	Image  string            `json:"image,omitempty"`
+	Script string            `json:"script,omitempty"`
+	Env    map[string]string `json:"env,omitempty"`
 }
 
 // TemplateBinding is a combination of the template and binding to be used for a
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -202,6 +203,156 @@ func TestGetEnvironment(t *testing.T) {
 		t.Fatalf("found an unknown env: %#v", unknown)
 	}
 }
+
+func TestAddApplication(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{Name: "dev", Apps: []*Application{{Name: "app-1", Services: []*Service{{Name: "svc-1"}}}}},
+		},
+	}
+	app := &Application{Name: "app-2", ConfigRepo: &Repository{URL: "https://github.com/org/config.git", Path: "deploy"}}
+
+	if err := m.AddApplication("dev", app); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(app, m.GetApplication("dev", "app-2")); diff != "" {
+		t.Fatalf("AddApplication() failed:\n%s", diff)
+	}
+
+	errTests := []struct {
+		env     string
+		app     string
+		wantErr string
+	}{
+		{"dev", "app-1", "application app-1 already exists in environment dev"},
+		{"unknown", "app-3", "environment unknown does not exist"},
+	}
+	for _, tt := range errTests {
+		err := m.AddApplication(tt.env, &Application{Name: tt.app})
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("AddApplication(%q, %q) got error %v, want %q", tt.env, tt.app, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRemoveService(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "dev",
+				Apps: []*Application{
+					{Name: "app-1", Services: []*Service{{Name: "svc-1"}, {Name: "svc-2"}}},
+					{Name: "app-2", Services: []*Service{{Name: "svc-3"}}},
+				},
+			},
+		},
+	}
+
+	app, err := m.RemoveService("dev", "svc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Name != "app-1" {
+		t.Fatalf("got the wrong application back: %#v", app)
+	}
+	if _, err := m.RemoveService("dev", "svc-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Application{{Name: "app-1", Services: []*Service{{Name: "svc-2"}}}}
+	if diff := cmp.Diff(want, m.GetEnvironment("dev").Apps); diff != "" {
+		t.Fatalf("RemoveService() failed:\n%s", diff)
+	}
+	_, err = m.RemoveService("dev", "unknown")
+	if err == nil || err.Error() != "service unknown does not exist in environment dev" {
+		t.Fatalf("got error %v removing an unknown service", err)
+	}
+}
+
+func TestRemoveEnvironment(t *testing.T) {
+	m := &Manifest{Environments: makeEnvs([]testEnv{{name: "prod"}, {name: "testing"}})}
+	env, err := m.RemoveEnvironment("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Name != "prod" {
+		t.Fatalf("got the wrong environment back: %#v", env)
+	}
+	if diff := cmp.Diff(makeEnvs([]testEnv{{name: "testing"}}), m.Environments); diff != "" {
+		t.Fatalf("RemoveEnvironment() failed:\n%s", diff)
+	}
+	if _, err := m.RemoveEnvironment("prod"); err == nil {
+		t.Fatal("expected an error removing an unknown environment")
+	}
+}
+
+func TestCleanSourcePath(t *testing.T) {
+	pathTests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{".", ""},
+		{"./", ""},
+		{"services/api", "services/api"},
+		{"./services/api/", "services/api"},
+		{"services//api", "services/api"},
+	}
+
+	for _, tt := range pathTests {
+		if got := CleanSourcePath(tt.path); got != tt.want {
+			t.Errorf("CleanSourcePath(%q) got %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGetPlatform(t *testing.T) {
+	platformTests := []struct {
+		manifest *Manifest
+		want     string
+	}{
+		{&Manifest{}, PlatformOpenShift},
+		{&Manifest{Config: &Config{}}, PlatformOpenShift},
+		{&Manifest{Config: &Config{Platform: PlatformKubernetes}}, PlatformKubernetes},
+	}
+
+	for _, tt := range platformTests {
+		if got := tt.manifest.GetPlatform(); got != tt.want {
+			t.Errorf("GetPlatform() got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestIngressHostFor(t *testing.T) {
+	hostTests := []struct {
+		cfg     *IngressConfig
+		want    string
+		wantErr string
+	}{
+		{nil, "", ""},
+		{&IngressConfig{Class: "nginx"}, "", ""},
+		{&IngressConfig{Host: "{{.Name}}-{{.Namespace}}.apps.example.com"}, "api-dev.apps.example.com", ""},
+		{&IngressConfig{Host: "{{.Name}.example.com"}, "", "failed to parse the ingress host template"},
+		{&IngressConfig{Host: "{{.Cluster}}.example.com"}, "", "failed to execute the ingress host template"},
+	}
+
+	for _, tt := range hostTests {
+		got, err := tt.cfg.HostFor("api", "dev")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("HostFor() got error %v, want %s", err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("HostFor() got %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func makeEnvs(ns []testEnv) []*Environment {
 	n := make([]*Environment, len(ns))
 	for i, v := range ns {
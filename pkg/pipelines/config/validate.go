@@ -3,10 +3,13 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mkmik/multierror"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"knative.dev/pkg/apis"
 )
@@ -14,6 +17,8 @@ import (
 const (
 	longServiceName  = "a service name cannot exceed 47 characters"
 	serviceNameLimit = 47
+	longTestName     = "a test name cannot exceed 47 characters"
+	testNameLimit    = 47
 )
 
 type validateVisitor struct {
@@ -22,9 +27,17 @@ type validateVisitor struct {
 	appNames     map[string]bool
 	serviceNames map[string]bool
 	serviceURLs  map[string][]string
+	sources      map[serviceSource][]string
 	configNames  map[string]bool
 }
 
+// serviceSource identifies the source for a service, services can share a
+// repository if they are built from different paths.
+type serviceSource struct {
+	url  string
+	path string
+}
+
 // Validate validates the Manifest, returning a multi-error representing all the
 // errors that were detected.
 func (m *Manifest) Validate() error {
@@ -34,6 +47,7 @@ func (m *Manifest) Validate() error {
 		appNames:     map[string]bool{},
 		serviceNames: map[string]bool{},
 		serviceURLs:  map[string][]string{},
+		sources:      map[serviceSource][]string{},
 		configNames:  map[string]bool{},
 	}
 
@@ -73,10 +87,39 @@ func (vv *validateVisitor) validateServiceURLs(gitOpsURL string) []error {
 				errs = append(errs, inconsistentGitTypeError(gitType, url, paths))
 			}
 		}
+	}
+	for source, paths := range vv.sources {
 		if len(paths) > 1 {
-			errs = append(errs, duplicateSourceError(url, paths))
+			if source.path == "" {
+				errs = append(errs, duplicateSourceError(source.url, paths))
+				continue
+			}
+			errs = append(errs, duplicateSourcePathError(source.url, source.path, paths))
 		}
 	}
+	errs = append(errs, vv.validateRootSources()...)
+	return errs
+}
+
+// validateRootSources rejects services built from the root of a repository
+// that is shared with services built from a sub-path, the root service would
+// be triggered by every change to the sub-path services.
+func (vv *validateVisitor) validateRootSources() []error {
+	errs := []error{}
+	subPaths := map[string][]string{}
+	for source, paths := range vv.sources {
+		if source.path != "" {
+			subPaths[source.url] = append(subPaths[source.url], paths...)
+		}
+	}
+	for url, paths := range subPaths {
+		rootPaths, ok := vv.sources[serviceSource{url: url}]
+		if !ok {
+			continue
+		}
+		sort.Strings(paths)
+		errs = append(errs, rootSourcePathError(url, append(append([]string{}, rootPaths...), paths...)))
+	}
 	return errs
 }
 
@@ -137,6 +180,11 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 		}
 		previous = append(previous, svcPath)
 		vv.serviceURLs[svc.SourceURL] = previous
+		source := serviceSource{url: svc.SourceURL, path: CleanSourcePath(svc.SourcePath)}
+		vv.sources[source] = append(vv.sources[source], svcPath)
+	}
+	if err := validateSourcePath(svc.SourcePath, yamlJoin(svcPath, "source_path")); err != nil {
+		vv.errs = append(vv.errs, err)
 	}
 	if err := checkDuplicateService(svc.Name, svcPath, svcRelativePath, vv.serviceNames); err != nil {
 		vv.errs = append(vv.errs, err)
@@ -154,6 +202,12 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 	if err := validatePipelines(svc.Pipelines, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if err := validateBuild(svc.Build, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateTriggers(svc.Triggers, yamlJoin(svcPath, "triggers")); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
 	vv.serviceNames[svc.Name] = true
 	return nil
 }
@@ -173,6 +227,82 @@ func validateConfigRepo(repo *Repository, path string) []error {
 	return errs
 }
 
+func validateSourcePath(sourcePath, path string) *apis.FieldError {
+	if sourcePath == "" {
+		return nil
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(sourcePath))
+	if filepath.IsAbs(sourcePath) || strings.HasPrefix(sourcePath, "/") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return apis.ErrInvalidValue(sourcePath, path)
+	}
+	return nil
+}
+
+func validateBuild(build *Build, path string) []error {
+	if build == nil {
+		return nil
+	}
+	buildPath := yamlJoin(path, "build")
+	switch build.Strategy {
+	case "", BuildStrategyBuildah, BuildStrategyKaniko:
+		if build.BuilderImage != "" {
+			return list(unsupportedBuildFieldError("builder_image", build.Strategy, buildPath))
+		}
+	case BuildStrategyS2I, BuildStrategyBuildpacks:
+		errs := []error{}
+		if build.BuilderImage == "" {
+			errs = append(errs, missingFieldsError([]string{"builder_image"}, []string{buildPath}))
+		}
+		if build.Dockerfile != "" {
+			errs = append(errs, unsupportedBuildFieldError("dockerfile", build.Strategy, buildPath))
+		}
+		if len(build.BuildArgs) > 0 {
+			errs = append(errs, unsupportedBuildFieldError("build_args", build.Strategy, buildPath))
+		}
+		return errs
+	default:
+		return list(apis.ErrInvalidValue(build.Strategy, yamlJoin(buildPath, "strategy")))
+	}
+	return nil
+}
+
+func validateTriggers(t *Triggers, path string) []error {
+	if t == nil {
+		return nil
+	}
+	errs := []error{}
+	if t.Branches != nil {
+		for i, p := range t.Branches.Include {
+			if err := validateRefPattern(p, fmt.Sprintf("%s[%d]", yamlJoin(path, "branches", "include"), i)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for i, p := range t.Branches.Exclude {
+			if err := validateRefPattern(p, fmt.Sprintf("%s[%d]", yamlJoin(path, "branches", "exclude"), i)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if t.Tags != "" {
+		if err := validateRefPattern(t.Tags, yamlJoin(path, "tags")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateRefPattern checks that a glob pattern for a branch or tag is
+// well-formed, and doesn't contain characters that can't appear in a Git ref.
+func validateRefPattern(pattern, path string) *apis.FieldError {
+	if pattern == "" || strings.ContainsAny(pattern, " ~^:\\'\"") || strings.HasPrefix(pattern, "/") || strings.HasSuffix(pattern, "/") || strings.Contains(pattern, "..") {
+		return apis.ErrInvalidValue(pattern, path)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return apis.ErrInvalidValue(pattern, path)
+	}
+	return nil
+}
+
 func validateWebhook(hook *Webhook, path string) []error {
 	errs := []error{}
 	if hook == nil {
@@ -195,16 +325,104 @@ func validatePipelines(pipelines *Pipelines, path string) []error {
 	if pipelines == nil {
 		return nil
 	}
-	if pipelines.Integration == nil {
+	if pipelines.Integration == nil && len(pipelines.Tests) == 0 && pipelines.UpdateGitOps == nil && pipelines.Workspaces == nil {
 		return list(missingFieldsError([]string{"integration"}, []string{yamlJoin(path, "pipelines")}))
 	}
-	for _, name := range pipelines.Integration.Bindings {
-		if err := validateName(name, yamlJoin(path, "pipelines", "integration", "binding")); err != nil {
+	if pipelines.Integration != nil {
+		for _, name := range pipelines.Integration.Bindings {
+			if err := validateName(name, yamlJoin(path, "pipelines", "integration", "binding")); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	errs = append(errs, validatePipelineTests(pipelines.Tests, yamlJoin(path, "pipelines", "tests"))...)
+	return append(errs, validateWorkspaces(pipelines.Workspaces, yamlJoin(path, "pipelines", "workspaces"))...)
+}
+
+func validateWorkspaces(ws *Workspaces, path string) []error {
+	if ws == nil {
+		return nil
+	}
+	errs := []error{}
+	if ws.Source != nil {
+		sourcePath := yamlJoin(path, "source")
+		if ws.Source.EmptyDir {
+			errs = append(errs, emptyDirSourceError(sourcePath))
+		}
+		errs = append(errs, validateWorkspaceStorage(ws.Source, sourcePath)...)
+	}
+	if ws.Cache != nil {
+		cachePath := yamlJoin(path, "cache")
+		if ws.Cache.ClaimName == "" {
+			errs = append(errs, missingFieldsError([]string{"claim_name"}, []string{cachePath}))
+		}
+		errs = append(errs, validateWorkspaceStorage(ws.Cache, cachePath)...)
+	}
+	return errs
+}
+
+func validateWorkspaceStorage(storage *WorkspaceStorage, path string) []error {
+	if storage.ClaimName != "" && storage.EmptyDir {
+		return list(apis.ErrMultipleOneOf(yamlJoin(path, "claim_name"), yamlJoin(path, "empty_dir")))
+	}
+	errs := []error{}
+	if storage.ClaimName != "" || storage.EmptyDir {
+		fields := map[string]string{"size": storage.Size, "storage_class": storage.StorageClass, "access_mode": storage.AccessMode}
+		for _, field := range []string{"size", "storage_class", "access_mode"} {
+			if fields[field] != "" {
+				errs = append(errs, unsupportedWorkspaceFieldError(field, path))
+			}
+		}
+		return errs
+	}
+	if storage.Size != "" {
+		if _, err := resource.ParseQuantity(storage.Size); err != nil {
+			errs = append(errs, apis.ErrInvalidValue(storage.Size, yamlJoin(path, "size")))
+		}
+	}
+	switch corev1.PersistentVolumeAccessMode(storage.AccessMode) {
+	case "", corev1.ReadWriteOnce, corev1.ReadWriteMany, corev1.ReadOnlyMany:
+	default:
+		errs = append(errs, apis.ErrInvalidValue(storage.AccessMode, yamlJoin(path, "access_mode")))
+	}
+	return errs
+}
+
+func validatePipelineTests(tests []*TestStep, path string) []error {
+	errs := []error{}
+	names := map[string]bool{}
+	for i, test := range tests {
+		testPath := fmt.Sprintf("%s[%d]", path, i)
+		missingFields := []string{}
+		if test.Name == "" {
+			missingFields = append(missingFields, "name")
+		}
+		if test.Image == "" {
+			missingFields = append(missingFields, "image")
+		}
+		if test.Script == "" {
+			missingFields = append(missingFields, "script")
+		}
+		if len(missingFields) > 0 {
+			errs = append(errs, missingFieldsError(missingFields, []string{testPath}))
+		}
+		if test.Name == "" {
+			continue
+		}
+		if err := validateName(test.Name, yamlJoin(testPath, "name")); err != nil {
 			errs = append(errs, err)
 		}
+		if len(test.Name) > testNameLimit {
+			errs = append(errs, invalidNameError(test.Name, longTestName, []string{yamlJoin(testPath, "name")}))
+		}
+		if names[test.Name] {
+			errs = append(errs, duplicateFieldsError([]string{test.Name}, []string{testPath}))
+		}
+		names[test.Name] = true
 	}
 	return errs
 }
+
 func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 	errs := []error{}
 	if manifest.Config != nil {
@@ -219,11 +437,53 @@ func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 				errs = append(errs, err)
 			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
+			if api := manifest.Config.Pipelines.GetTektonAPI(); api != TektonAPIV1Beta1 && api != TektonAPIV1 {
+				errs = append(errs, apis.ErrInvalidValue(api, "config.pipelines.tekton_api"))
+			}
 		}
+		if manifest.Config.Secrets != nil {
+			errs = append(errs, validateSecretsConfig(manifest.Config.Secrets, "config.secrets")...)
+		}
+		errs = append(errs, validatePlatform(manifest.Config)...)
 	}
 	return errs
 }
 
+func validatePlatform(cfg *Config) []error {
+	switch cfg.Platform {
+	case "", PlatformOpenShift:
+		if cfg.Ingress != nil {
+			return list(apis.ErrDisallowedFields("config.ingress"))
+		}
+		return nil
+	case PlatformKubernetes:
+		if cfg.Ingress == nil {
+			return nil
+		}
+		if _, err := cfg.Ingress.HostFor("service", "namespace"); err != nil {
+			return list(apis.ErrInvalidValue(cfg.Ingress.Host, "config.ingress.host"))
+		}
+		return nil
+	}
+	return list(apis.ErrInvalidValue(cfg.Platform, "config.platform"))
+}
+
+func validateSecretsConfig(cfg *SecretsConfig, path string) []error {
+	switch cfg.GetMode() {
+	case SecretsModeRaw, SecretsModeSealed:
+		return nil
+	case SecretsModeExternal:
+		if cfg.Store == nil || cfg.Store.Name == "" {
+			return list(missingFieldsError([]string{"store.name"}, []string{path}))
+		}
+		if cfg.Store.Kind != "" && cfg.Store.Kind != "SecretStore" && cfg.Store.Kind != "ClusterSecretStore" {
+			return list(apis.ErrInvalidValue(cfg.Store.Kind, yamlJoin(path, "store", "kind")))
+		}
+		return nil
+	}
+	return list(apis.ErrInvalidValue(cfg.Mode, yamlJoin(path, "mode")))
+}
+
 func validateName(name, path string) *apis.FieldError {
 	err := validation.NameIsDNS1035Label(name, true)
 	if len(err) > 0 {
@@ -291,6 +551,44 @@ func duplicateSourceError(url string, paths []string) *apis.FieldError {
 	}
 }
 
+func duplicateSourcePathError(url, sourcePath string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("duplicate source detected, multiple services cannot share the same source path %q in repository: %s", sourcePath, url),
+		Paths:   paths,
+	}
+}
+
+func rootSourcePathError(url string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("services built from the root of a repository cannot share the repository with services built from a source path: %s", url),
+		Paths:   paths,
+	}
+}
+
+func unsupportedBuildFieldError(field, strategy, path string) *apis.FieldError {
+	if strategy == "" {
+		strategy = BuildStrategyBuildah
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("field %q is not supported by the %s build strategy", field, strategy),
+		Paths:   []string{path},
+	}
+}
+
+func unsupportedWorkspaceFieldError(field, path string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("field %q is only supported for claimed volumes", field),
+		Paths:   []string{path},
+	}
+}
+
+func emptyDirSourceError(path string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: "the source workspace can't be an emptyDir, as it is not shared between the Tasks of a Pipeline",
+		Paths:   []string{yamlJoin(path, "empty_dir")},
+	}
+}
+
 func inconsistentGitTypeError(gitType, serviceURL string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("service URL must be a %s repository: %v", gitType, serviceURL),
@@ -120,6 +120,159 @@ var validateTests = []struct {
 			},
 		),
 	},
+	{
+		"duplicate source path",
+		"testdata/duplicate_source_path.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("../outside", "environments.monorepo.apps.my-app.services.outside-service.source_path"),
+				duplicateSourcePathError("https://github.com/testing/monorepo.git", "services/api", []string{
+					"environments.monorepo.apps.my-app.services.api-service",
+					"environments.monorepo.apps.my-app.services.api-copy-service"}),
+			},
+		),
+	},
+	{
+		"service built from the root of a repository shared with source paths",
+		"testdata/root_source_path.yaml",
+		multierror.Join(
+			[]error{
+				rootSourcePathError("https://github.com/testing/monorepo.git", []string{
+					"environments.monorepo.apps.my-app.services.root-service",
+					"environments.monorepo.apps.my-app.services.api-service",
+					"environments.monorepo.apps.my-app.services.web-service"}),
+			},
+		),
+	},
+	{
+		"services sharing a repository with different source paths",
+		"testdata/monorepo_services.yaml",
+		nil,
+	},
+	{
+		"invalid build strategies",
+		"testdata/invalid_build_strategies.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("docker", "environments.builds.apps.my-app.services.unknown-service.build.strategy"),
+				missingFieldsError([]string{"builder_image"}, []string{"environments.builds.apps.my-app.services.s2i-service.build"}),
+				unsupportedBuildFieldError("dockerfile", "s2i", "environments.builds.apps.my-app.services.s2i-service.build"),
+				unsupportedBuildFieldError("builder_image", "", "environments.builds.apps.my-app.services.buildah-service.build"),
+			},
+		),
+	},
+	{
+		"valid build strategies",
+		"testdata/build_strategies.yaml",
+		nil,
+	},
+	{
+		"valid pipeline tests",
+		"testdata/pipeline_tests.yaml",
+		nil,
+	},
+	{
+		"invalid pipeline tests",
+		"testdata/invalid_pipeline_tests.yaml",
+		multierror.Join(
+			[]error{
+				missingFieldsError([]string{"image"}, []string{"environments.development.apps.my-app.services.api.pipelines.tests[0]"}),
+				invalidNameError("Lint", DNS1035Error, []string{"environments.development.apps.my-app.services.api.pipelines.tests[1].name"}),
+				duplicateFieldsError([]string{"unit"}, []string{"environments.development.apps.my-app.services.api.pipelines.tests[2]"}),
+				invalidNameError("an-incredibly-long-name-for-a-test-that-fails-validation", longTestName,
+					[]string{"environments.development.apps.my-app.services.api.pipelines.tests[3].name"}),
+			},
+		),
+	},
+	{
+		"valid pipeline workspaces",
+		"testdata/pipeline_workspaces.yaml",
+		nil,
+	},
+	{
+		"invalid pipeline workspaces",
+		"testdata/invalid_pipeline_workspaces.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("lots", "environments.development.apps.my-app.services.api.pipelines.workspaces.source.size"),
+				apis.ErrInvalidValue("ReadWriteSometimes", "environments.development.apps.my-app.services.api.pipelines.workspaces.source.access_mode"),
+				emptyDirSourceError("environments.development.apps.my-app.services.web.pipelines.workspaces.source"),
+				apis.ErrMultipleOneOf("environments.development.apps.my-app.services.web.pipelines.workspaces.source.claim_name", "environments.development.apps.my-app.services.web.pipelines.workspaces.source.empty_dir"),
+				unsupportedWorkspaceFieldError("storage_class", "environments.development.apps.my-app.services.docs.pipelines.workspaces.source"),
+				emptyDirSourceError("environments.development.apps.my-app.services.site.pipelines.workspaces.source"),
+				missingFieldsError([]string{"claim_name"}, []string{"environments.development.pipelines.workspaces.cache"}),
+			},
+		),
+	},
+	{
+		"service triggers",
+		"testdata/service_triggers.yaml",
+		nil,
+	},
+	{
+		"invalid service triggers",
+		"testdata/invalid_service_triggers.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("release/[0-9", "environments.development.apps.my-app.services.api.triggers.branches.include[1]"),
+				apis.ErrInvalidValue("", "environments.development.apps.my-app.services.api.triggers.branches.exclude[0]"),
+				apis.ErrInvalidValue("feature/*~1", "environments.development.apps.my-app.services.web.triggers.branches.include[0]"),
+				apis.ErrInvalidValue("v*:latest", "environments.development.apps.my-app.services.web.triggers.tags"),
+			},
+		),
+	},
+	{
+		"external secrets mode without a store",
+		"testdata/invalid_secrets_config.yaml",
+		multierror.Join([]error{
+			missingFieldsError([]string{"store.name"}, []string{"config.secrets"}),
+		}),
+	},
+	{
+		"unknown secrets mode",
+		"testdata/unknown_secrets_mode.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("encrypted", "config.secrets.mode"),
+		}),
+	},
+	{
+		"unknown Tekton API",
+		"testdata/unknown_tekton_api.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("v1alpha1", "config.pipelines.tekton_api"),
+		}),
+	},
+	{
+		"unknown platform",
+		"testdata/invalid_platform.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("openstack", "config.platform"),
+		}),
+	},
+	{
+		"invalid ingress host template",
+		"testdata/invalid_ingress_config.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("{{.Name}-{{.Namespace}}.apps.example.com", "config.ingress.host"),
+		}),
+	},
+	{
+		"ingress on the openshift platform",
+		"testdata/openshift_ingress_config.yaml",
+		multierror.Join([]error{
+			apis.ErrDisallowedFields("config.ingress"),
+		}),
+	},
+	{
+		"kubernetes platform with ingress",
+		"testdata/kubernetes_platform.yaml",
+		nil,
+	},
+	{
+		"external secrets mode with a store",
+		"testdata/external_secrets_config.yaml",
+		nil,
+	},
 	{
 		"service with pipeline with no template",
 		"testdata/service_with_bindings_no_template.yaml",
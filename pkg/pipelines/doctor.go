@@ -0,0 +1,445 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// CheckStatus is the result of a check of the cluster.
+type CheckStatus string
+
+const (
+	// CheckPass is used for checks that found the expected resources.
+	CheckPass CheckStatus = "pass"
+	// CheckFail is used for checks that found missing or broken resources.
+	CheckFail CheckStatus = "fail"
+)
+
+var applicationsGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+
+// DoctorOptions provides the manifest to check the cluster against.
+type DoctorOptions struct {
+	PipelinesFolderPath string
+}
+
+// DoctorClients are the clients used to read the resources in the cluster.
+type DoctorClients struct {
+	KubeClient    kubernetes.Interface
+	RouteClient   routeclientset.RouteV1Interface
+	DynamicClient dynamic.Interface
+}
+
+// Check is the result of checking a resource in the cluster, failed checks
+// have a hint to fix them.
+type Check struct {
+	Name        string      `json:"name"`
+	Status      CheckStatus `json:"status"`
+	Message     string      `json:"message"`
+	Remediation string      `json:"remediation,omitempty"`
+}
+
+// DoctorReport is the result of all the checks of the cluster.
+type DoctorReport struct {
+	Checks []*Check `json:"checks"`
+}
+
+// Failed returns the number of checks that failed.
+func (r *DoctorReport) Failed() int {
+	failed := 0
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// NewDoctorClients creates the clients for the current cluster.
+func NewDoctorClients() (*DoctorClients, error) {
+	clientConfig, err := clientconfig.GetRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	routeClient, err := routeclientset.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &DoctorClients{KubeClient: kubeClient, RouteClient: routeClient, DynamicClient: dynamicClient}, nil
+}
+
+// Doctor is the entry-point from the CLI for checking the resources in the
+// cluster against the manifest.
+//
+// An error is only returned if the checks can't be made, the results of the
+// checks are in the report.
+func Doctor(o *DoctorOptions, appFs afero.Fs, c *DoctorClients) (*DoctorReport, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a pipelines configuration in the manifest")
+	}
+	sa, err := repositoryServiceAccount(appFs, filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base", serviceAccountPath))
+	if err != nil {
+		return nil, err
+	}
+	return diagnose(m, sa, c)
+}
+
+// repositoryServiceAccount reads the pipeline ServiceAccount from the GitOps
+// repository, nil is returned if the file doesn't exist, bootstrap only writes
+// it when secrets are added to the ServiceAccount.
+func repositoryServiceAccount(appFs afero.Fs, filename string) (*corev1.ServiceAccount, error) {
+	exists, err := afero.Exists(appFs, filename)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var sa corev1.ServiceAccount
+	if err := yaml.UnmarshalItemFromFile(appFs, filename, &sa); err != nil {
+		return nil, fmt.Errorf("failed to read the ServiceAccount from %s: %w", filename, err)
+	}
+	return &sa, nil
+}
+
+// diagnose checks the cluster against the manifest, sa is the pipeline
+// ServiceAccount from the GitOps repository if it exists, and lists the
+// secrets that must be linked to the ServiceAccount in the cluster.
+func diagnose(m *config.Manifest, sa *corev1.ServiceAccount, c *DoctorClients) (*DoctorReport, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a pipelines configuration in the manifest")
+	}
+	d := &doctor{clients: c, report: &DoctorReport{Checks: []*Check{}}, cli: "oc"}
+	if m.GetPlatform() == config.PlatformKubernetes {
+		d.cli = "kubectl"
+	}
+	d.checkNamespaces(m, cfg.Name)
+	d.checkServiceAccount(cfg.Name, sa)
+	if err := d.checkWebhookSecrets(m, cfg.Name); err != nil {
+		return nil, err
+	}
+	d.checkEventListener(m, cfg.Name)
+	d.checkTasks(cfg)
+	if err := d.checkApplications(m); err != nil {
+		return nil, err
+	}
+	return d.report, nil
+}
+
+type doctor struct {
+	clients *DoctorClients
+	report  *DoctorReport
+	// cli is the command used in remediations for the platform.
+	cli string
+}
+
+func (d *doctor) pass(name, message string) {
+	d.report.Checks = append(d.report.Checks, &Check{Name: name, Status: CheckPass, Message: message})
+}
+
+func (d *doctor) fail(name, message, remediation string) {
+	d.report.Checks = append(d.report.Checks, &Check{Name: name, Status: CheckFail, Message: message, Remediation: remediation})
+}
+
+// failed records a failed check for the error from getting a resource, the
+// remediation is only useful if the resource is missing.
+func (d *doctor) failed(name string, err error, remediation string) {
+	if !apierrors.IsNotFound(err) {
+		remediation = "Check that you are logged in to the cluster, and can read the resource"
+	}
+	d.fail(name, err.Error(), remediation)
+}
+
+// checkNamespaces checks the namespaces of the CI/CD environment, and the
+// environments in this cluster, environments with a cluster are deployed
+// elsewhere.
+func (d *doctor) checkNamespaces(m *config.Manifest, cicdNamespace string) {
+	names := []string{cicdNamespace}
+	for _, env := range m.Environments {
+		if env.Cluster == "" {
+			names = append(names, env.Name)
+		}
+	}
+	for _, ns := range names {
+		name := fmt.Sprintf("namespace %s", ns)
+		if _, err := d.clients.KubeClient.CoreV1().Namespaces().Get(context.Background(), ns, metav1.GetOptions{}); err != nil {
+			d.failed(name, err, "Sync the environment with Argo CD, or apply the resources for the environment from the GitOps repository")
+			continue
+		}
+		d.pass(name, "exists")
+	}
+}
+
+// checkServiceAccount checks that the secrets of the pipeline ServiceAccount in
+// the GitOps repository exist, and are linked to it in the cluster.
+//
+// Bootstrap only adds secrets for the options that were provided, so without
+// the ServiceAccount from the GitOps repository, only the secrets that are
+// linked in the cluster are checked.
+func (d *doctor) checkServiceAccount(cicdNamespace string, repoSA *corev1.ServiceAccount) {
+	name := fmt.Sprintf("serviceaccount %s/%s", cicdNamespace, saName)
+	sa, err := d.clients.KubeClient.CoreV1().ServiceAccounts(cicdNamespace).Get(context.Background(), saName, metav1.GetOptions{})
+	if err != nil {
+		d.failed(name, err, "Apply the resources for the CI/CD environment from the GitOps repository")
+		return
+	}
+	d.pass(name, "exists")
+
+	linked := map[string]bool{}
+	for _, s := range sa.Secrets {
+		linked[s.Name] = true
+	}
+	required := []string{}
+	if repoSA != nil {
+		for _, s := range repoSA.Secrets {
+			required = append(required, s.Name)
+		}
+	} else {
+		for _, secretName := range []string{authTokenSecretName, basicAuthTokenName, dockerSecretName} {
+			if linked[secretName] {
+				required = append(required, secretName)
+			}
+		}
+	}
+	for _, secretName := range required {
+		secretCheck := fmt.Sprintf("serviceaccount secret %s/%s", cicdNamespace, secretName)
+		if !linked[secretName] {
+			d.fail(secretCheck, fmt.Sprintf("not in the secrets of the %s ServiceAccount", saName),
+				fmt.Sprintf("Add the secret to the %s ServiceAccount with: %s", saName, d.linkSecretCommand(cicdNamespace, secretName)))
+			continue
+		}
+		if _, err := d.clients.KubeClient.CoreV1().Secrets(cicdNamespace).Get(context.Background(), secretName, metav1.GetOptions{}); err != nil {
+			d.failed(secretCheck, err, "Apply the secret that was generated at bootstrap, or create it in the cluster")
+			continue
+		}
+		d.pass(secretCheck, "exists")
+	}
+}
+
+// linkSecretCommand returns the command to add a secret to the pipeline
+// ServiceAccount.
+func (d *doctor) linkSecretCommand(cicdNamespace, secretName string) string {
+	if d.cli == "kubectl" {
+		return fmt.Sprintf(`kubectl patch serviceaccount %s -n %s -p '{"secrets":[{"name":"%s"}]}'`, saName, cicdNamespace, secretName)
+	}
+	return fmt.Sprintf("oc secrets link %s %s -n %s", saName, secretName, cicdNamespace)
+}
+
+// checkWebhookSecrets checks the secrets that authenticate the webhook events
+// for the GitOps repository, and the services with a source repository.
+func (d *doctor) checkWebhookSecrets(m *config.Manifest, cicdNamespace string) error {
+	secretNames := []types.NamespacedName{}
+	if m.GitOpsURL != "" {
+		secretNames = append(secretNames, meta.NamespacedName(cicdNamespace, eventlisteners.GitOpsWebhookSecret))
+	}
+	v := &webhookSecretVisitor{cicdNamespace: cicdNamespace}
+	if err := m.Walk(v); err != nil {
+		return err
+	}
+	for _, secretName := range append(secretNames, v.secrets...) {
+		name := fmt.Sprintf("webhook secret %s", secretName)
+		secret, err := d.clients.KubeClient.CoreV1().Secrets(secretName.Namespace).Get(context.Background(), secretName.Name, metav1.GetOptions{})
+		if err != nil {
+			d.failed(name, err, "Apply the secret that was generated for the webhook, or regenerate it with kam secrets rotate")
+			continue
+		}
+		if !hasSecretKey(secret, eventlisteners.WebhookSecretKey) {
+			d.fail(name, fmt.Sprintf("missing the %q key", eventlisteners.WebhookSecretKey), "Regenerate the secret with kam secrets rotate")
+			continue
+		}
+		d.pass(name, "exists")
+	}
+	return nil
+}
+
+type webhookSecretVisitor struct {
+	cicdNamespace string
+	secrets       []types.NamespacedName
+}
+
+func (v *webhookSecretVisitor) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
+	if svc.SourceURL == "" {
+		return nil
+	}
+	secretName := meta.NamespacedName(v.cicdNamespace, secrets.MakeServiceWebhookSecretName(env.Name, svc.Name))
+	if svc.Webhook != nil && svc.Webhook.Secret != nil {
+		secretName = meta.NamespacedName(svc.Webhook.Secret.Namespace, svc.Webhook.Secret.Name)
+	}
+	v.secrets = append(v.secrets, secretName)
+	return nil
+}
+
+func hasSecretKey(s *corev1.Secret, key string) bool {
+	if _, ok := s.Data[key]; ok {
+		return true
+	}
+	_, ok := s.StringData[key]
+	return ok
+}
+
+// checkEventListener checks that the EventListener is exposed, by an
+// admitted Route, or on the kubernetes platform, an Ingress that an Ingress
+// controller has admitted, and given a load balancer address.
+func (d *doctor) checkEventListener(m *config.Manifest, cicdNamespace string) {
+	if m.GetPlatform() == config.PlatformKubernetes {
+		name := fmt.Sprintf("ingress %s/%s", cicdNamespace, eventlisteners.GitOpsWebhookEventListenerIngressName)
+		ingress, err := d.clients.KubeClient.NetworkingV1().Ingresses(cicdNamespace).Get(context.Background(), eventlisteners.GitOpsWebhookEventListenerIngressName, metav1.GetOptions{})
+		if err != nil {
+			d.failed(name, err, "Apply the resources for the CI/CD environment from the GitOps repository")
+			return
+		}
+		if len(ingress.Status.LoadBalancer.Ingress) > 0 {
+			d.pass(name, "has a load balancer address")
+			return
+		}
+		d.fail(name, "not admitted, it has no load balancer address", fmt.Sprintf("Check that an Ingress controller serves the IngressClass of the Ingress with: kubectl describe ingress %s -n %s", eventlisteners.GitOpsWebhookEventListenerIngressName, cicdNamespace))
+		return
+	}
+
+	name := fmt.Sprintf("route %s/%s", cicdNamespace, eventlisteners.GitOpsWebhookEventListenerRouteName)
+	route, err := d.clients.RouteClient.Routes(cicdNamespace).Get(context.Background(), eventlisteners.GitOpsWebhookEventListenerRouteName, metav1.GetOptions{})
+	if err != nil {
+		d.failed(name, err, "Apply the resources for the CI/CD environment from the GitOps repository")
+		return
+	}
+	for _, ingress := range route.Status.Ingress {
+		for _, c := range ingress.Conditions {
+			if c.Type == routev1.RouteAdmitted && c.Status == corev1.ConditionTrue {
+				d.pass(name, fmt.Sprintf("admitted by router %s", ingress.RouterName))
+				return
+			}
+		}
+	}
+	d.fail(name, "not admitted", fmt.Sprintf("Check the status of the route with: oc describe route %s -n %s", eventlisteners.GitOpsWebhookEventListenerRouteName, cicdNamespace))
+}
+
+// checkTasks checks the Tasks that the generated Pipelines refer to, they are
+// namespaced Tasks in the CI/CD environment, and the ClusterTasks that
+// Pipelines generated by earlier releases refer to.
+func (d *doctor) checkTasks(cfg *config.PipelinesConfig) {
+	names := []string{"deploy-from-source-task", "set-commit-status"}
+	catalogTasks := []string{}
+	for k := range cfg.CatalogTasks {
+		catalogTasks = append(catalogTasks, k)
+	}
+	sort.Strings(catalogTasks)
+	names = append(names, catalogTasks...)
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: cfg.GetTektonAPI(), Resource: "tasks"}
+	for _, taskName := range names {
+		name := fmt.Sprintf("task %s/%s", cfg.Name, taskName)
+		if _, err := d.clients.DynamicClient.Resource(gvr).Namespace(cfg.Name).Get(context.Background(), taskName, metav1.GetOptions{}); err != nil {
+			d.failed(name, err, "Run kam build, and apply the Tasks for the CI/CD environment from the GitOps repository")
+			continue
+		}
+		d.pass(name, "exists")
+	}
+	if cfg.GetTektonAPI() == config.TektonAPIV1 {
+		return
+	}
+	d.checkClusterTasks(cfg.Name)
+}
+
+// checkClusterTasks checks the ClusterTasks that the Pipelines in the CI/CD
+// environment refer to, Pipelines that were generated before the catalog
+// Tasks were written to the GitOps repository use the ClusterTasks that are
+// installed with OpenShift Pipelines.
+func (d *doctor) checkClusterTasks(cicdNamespace string) {
+	pipelinesGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	list, err := d.clients.DynamicClient.Resource(pipelinesGVR).Namespace(cicdNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		d.failed(fmt.Sprintf("pipelines %s", cicdNamespace), err, "Apply the resources for the CI/CD environment from the GitOps repository")
+		return
+	}
+	referenced := map[string]bool{}
+	for _, item := range list.Items {
+		var pipeline pipelinev1.Pipeline
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pipeline); err != nil {
+			d.fail(fmt.Sprintf("pipeline %s/%s", cicdNamespace, item.GetName()), fmt.Sprintf("failed to read the Pipeline: %s", err), "Apply the resources for the CI/CD environment from the GitOps repository")
+			continue
+		}
+		for _, t := range append(pipeline.Spec.Tasks, pipeline.Spec.Finally...) {
+			if t.TaskRef != nil && t.TaskRef.Kind == pipelinev1.ClusterTaskKind {
+				referenced[t.TaskRef.Name] = true
+			}
+		}
+	}
+	names := []string{}
+	for k := range referenced {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}
+	for _, taskName := range names {
+		name := fmt.Sprintf("clustertask %s", taskName)
+		if _, err := d.clients.DynamicClient.Resource(gvr).Get(context.Background(), taskName, metav1.GetOptions{}); err != nil {
+			d.failed(name, err, "Install OpenShift Pipelines, which provides the ClusterTask, or install it from the Tekton catalog")
+			continue
+		}
+		d.pass(name, "exists")
+	}
+}
+
+// checkApplications checks the Argo CD Applications that are generated from
+// the manifest.
+func (d *doctor) checkApplications(m *config.Manifest) error {
+	// The Applications are built in the same namespaces as kam build writes
+	// them to.
+	files, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m)
+	if err != nil {
+		return err
+	}
+	apps := []*argoappv1.Application{}
+	for _, v := range files {
+		if app, ok := v.(*argoappv1.Application); ok {
+			apps = append(apps, app)
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+	for _, app := range apps {
+		name := fmt.Sprintf("application %s/%s", app.Namespace, app.Name)
+		if _, err := d.clients.DynamicClient.Resource(applicationsGVR).Namespace(app.Namespace).Get(context.Background(), app.Name, metav1.GetOptions{}); err != nil {
+			d.failed(name, err, fmt.Sprintf("Apply the Argo CD configuration from the GitOps repository with: %s apply -k config/argocd", d.cli))
+			continue
+		}
+		d.pass(name, "exists")
+	}
+	return nil
+}
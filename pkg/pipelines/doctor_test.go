@@ -0,0 +1,402 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	fakeRouteClientset "github.com/openshift/client-go/route/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeDynamicClient "k8s.io/client-go/dynamic/fake"
+	fakeKubeClientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestDiagnose(t *testing.T) {
+	clients := &DoctorClients{
+		KubeClient: fakeKubeClientset.NewSimpleClientset(
+			doctorNamespace("cicd"), doctorNamespace("dev"),
+			doctorServiceAccount(authTokenSecretName, basicAuthTokenName, dockerSecretName),
+			doctorSecret(authTokenSecretName, "token"),
+			doctorSecret(basicAuthTokenName, "password"),
+			doctorSecret(dockerSecretName, ".dockerconfigjson"),
+			doctorSecret(eventlisteners.GitOpsWebhookSecret, eventlisteners.WebhookSecretKey),
+			doctorSecret("webhook-secret-dev-api", eventlisteners.WebhookSecretKey),
+		),
+		RouteClient:   fakeRouteClientset.NewSimpleClientset(doctorRoute(corev1.ConditionTrue)).RouteV1(),
+		DynamicClient: doctorDynamicClient(doctorClusterObjects()...),
+	}
+
+	report, err := diagnose(doctorManifest(""), doctorServiceAccount(authTokenSecretName, basicAuthTokenName, dockerSecretName), clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Check{
+		{Name: "namespace cicd", Status: CheckPass, Message: "exists"},
+		{Name: "namespace dev", Status: CheckPass, Message: "exists"},
+		{Name: "serviceaccount cicd/pipeline", Status: CheckPass, Message: "exists"},
+		{Name: "serviceaccount secret cicd/git-host-access-token", Status: CheckPass, Message: "exists"},
+		{Name: "serviceaccount secret cicd/git-host-basic-auth-token", Status: CheckPass, Message: "exists"},
+		{Name: "serviceaccount secret cicd/regcred", Status: CheckPass, Message: "exists"},
+		{Name: "webhook secret cicd/gitops-webhook-secret", Status: CheckPass, Message: "exists"},
+		{Name: "webhook secret cicd/webhook-secret-dev-api", Status: CheckPass, Message: "exists"},
+		{Name: "route cicd/gitops-webhook-event-listener-route", Status: CheckPass, Message: "admitted by router default"},
+		{Name: "task cicd/deploy-from-source-task", Status: CheckPass, Message: "exists"},
+		{Name: "task cicd/set-commit-status", Status: CheckPass, Message: "exists"},
+		{Name: "task cicd/buildah", Status: CheckPass, Message: "exists"},
+		{Name: "task cicd/git-clone", Status: CheckPass, Message: "exists"},
+		{Name: "application argocd/argo-app", Status: CheckPass, Message: "exists"},
+		{Name: "application argocd/cicd-app", Status: CheckPass, Message: "exists"},
+		{Name: "application openshift-gitops/dev-env", Status: CheckPass, Message: "exists"},
+		{Name: "application openshift-gitops/dev-taxi", Status: CheckPass, Message: "exists"},
+		{Name: "application openshift-gitops/stage-env", Status: CheckPass, Message: "exists"},
+	}
+	if diff := cmp.Diff(want, report.Checks); diff != "" {
+		t.Fatalf("diagnose() failed:\n%s", diff)
+	}
+	if report.Failed() != 0 {
+		t.Fatalf("Failed() got %d, want 0", report.Failed())
+	}
+}
+
+func TestDiagnoseWithMissingResources(t *testing.T) {
+	clients := &DoctorClients{
+		KubeClient: fakeKubeClientset.NewSimpleClientset(
+			doctorNamespace("cicd"),
+			doctorServiceAccount(authTokenSecretName),
+			doctorSecret(eventlisteners.GitOpsWebhookSecret, "token"),
+		),
+		RouteClient:   fakeRouteClientset.NewSimpleClientset(doctorRoute(corev1.ConditionFalse)).RouteV1(),
+		DynamicClient: doctorDynamicClient(),
+	}
+
+	report, err := diagnose(doctorManifest(""), doctorServiceAccount(authTokenSecretName, basicAuthTokenName), clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := map[string]*Check{}
+	for _, c := range report.Checks {
+		if c.Status == CheckFail {
+			failed[c.Name] = c
+		}
+	}
+	wantFailed := map[string]string{
+		"namespace dev": `namespaces "dev" not found`,
+		"serviceaccount secret cicd/git-host-access-token":     `secrets "git-host-access-token" not found`,
+		"serviceaccount secret cicd/git-host-basic-auth-token": "not in the secrets of the pipeline ServiceAccount",
+		"webhook secret cicd/gitops-webhook-secret":            `missing the "webhook-secret-key" key`,
+		"webhook secret cicd/webhook-secret-dev-api":           `secrets "webhook-secret-dev-api" not found`,
+		"route cicd/gitops-webhook-event-listener-route":       "not admitted",
+		"task cicd/deploy-from-source-task":                    `tasks.tekton.dev "deploy-from-source-task" not found`,
+		"task cicd/set-commit-status":                          `tasks.tekton.dev "set-commit-status" not found`,
+		"task cicd/buildah":                                    `tasks.tekton.dev "buildah" not found`,
+		"task cicd/git-clone":                                  `tasks.tekton.dev "git-clone" not found`,
+		"application argocd/argo-app":                          `applications.argoproj.io "argo-app" not found`,
+		"application argocd/cicd-app":                          `applications.argoproj.io "cicd-app" not found`,
+		"application openshift-gitops/dev-env":                 `applications.argoproj.io "dev-env" not found`,
+		"application openshift-gitops/dev-taxi":                `applications.argoproj.io "dev-taxi" not found`,
+		"application openshift-gitops/stage-env":               `applications.argoproj.io "stage-env" not found`,
+	}
+	got := map[string]string{}
+	for name, c := range failed {
+		got[name] = c.Message
+		if c.Remediation == "" {
+			t.Errorf("check %q failed without a remediation", name)
+		}
+	}
+	if diff := cmp.Diff(wantFailed, got); diff != "" {
+		t.Fatalf("diagnose() failed:\n%s", diff)
+	}
+	if report.Failed() != len(wantFailed) {
+		t.Fatalf("Failed() got %d, want %d", report.Failed(), len(wantFailed))
+	}
+}
+
+func TestDiagnoseServiceAccountSecrets(t *testing.T) {
+	secretTests := []struct {
+		name      string
+		platform  string
+		clusterSA *corev1.ServiceAccount
+		repoSA    *corev1.ServiceAccount
+		want      []*Check
+	}{
+		{
+			"bootstrapped without a token",
+			"",
+			doctorServiceAccount(),
+			doctorServiceAccount(),
+			[]*Check{
+				{Name: "serviceaccount cicd/pipeline", Status: CheckPass, Message: "exists"},
+			},
+		},
+		{
+			"without the ServiceAccount from the repository",
+			"",
+			doctorServiceAccount(dockerSecretName),
+			nil,
+			[]*Check{
+				{Name: "serviceaccount cicd/pipeline", Status: CheckPass, Message: "exists"},
+				{Name: "serviceaccount secret cicd/regcred", Status: CheckPass, Message: "exists"},
+			},
+		},
+		{
+			"secret not linked",
+			"",
+			doctorServiceAccount(),
+			doctorServiceAccount(dockerSecretName),
+			[]*Check{
+				{Name: "serviceaccount cicd/pipeline", Status: CheckPass, Message: "exists"},
+				{Name: "serviceaccount secret cicd/regcred", Status: CheckFail, Message: "not in the secrets of the pipeline ServiceAccount",
+					Remediation: "Add the secret to the pipeline ServiceAccount with: oc secrets link pipeline regcred -n cicd"},
+			},
+		},
+		{
+			"secret not linked on kubernetes",
+			config.PlatformKubernetes,
+			doctorServiceAccount(),
+			doctorServiceAccount(dockerSecretName),
+			[]*Check{
+				{Name: "serviceaccount cicd/pipeline", Status: CheckPass, Message: "exists"},
+				{Name: "serviceaccount secret cicd/regcred", Status: CheckFail, Message: "not in the secrets of the pipeline ServiceAccount",
+					Remediation: `Add the secret to the pipeline ServiceAccount with: kubectl patch serviceaccount pipeline -n cicd -p '{"secrets":[{"name":"regcred"}]}'`},
+			},
+		},
+	}
+
+	for _, tt := range secretTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			clients := &DoctorClients{
+				KubeClient:    fakeKubeClientset.NewSimpleClientset(tt.clusterSA, doctorSecret(dockerSecretName, ".dockerconfigjson")),
+				RouteClient:   fakeRouteClientset.NewSimpleClientset().RouteV1(),
+				DynamicClient: doctorDynamicClient(),
+			}
+			report, err := diagnose(doctorManifest(tt.platform), tt.repoSA, clients)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			got := []*Check{}
+			for _, c := range report.Checks {
+				if strings.HasPrefix(c.Name, "serviceaccount ") {
+					got = append(got, c)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("diagnose() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepositoryServiceAccount(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	filename := filepath.Join("/gitops/config/tst-cicd/base", serviceAccountPath)
+
+	// The repository was bootstrapped without a token or docker config, so
+	// no secrets were added to the ServiceAccount, and it wasn't written.
+	sa, err := repositoryServiceAccount(fakeFs, filename)
+	assertNoError(t, err)
+	if sa != nil {
+		t.Fatalf("repositoryServiceAccount() got %#v, want nil", sa)
+	}
+
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filename, doctorServiceAccount(dockerSecretName)))
+	sa, err = repositoryServiceAccount(fakeFs, filename)
+	assertNoError(t, err)
+	if diff := cmp.Diff(doctorServiceAccount(dockerSecretName).Secrets, sa.Secrets); diff != "" {
+		t.Fatalf("repositoryServiceAccount() failed:\n%s", diff)
+	}
+}
+
+func TestDiagnoseClusterTasks(t *testing.T) {
+	// Pipelines generated by earlier releases refer to ClusterTasks, and no
+	// catalog Tasks are recorded in the manifest.
+	m := doctorManifest("")
+	m.Config.Pipelines.CatalogTasks = nil
+	pipeline := doctorObject("tekton.dev/v1beta1", "Pipeline", "cicd", "app-ci-pipeline")
+	pipeline.Object["spec"] = map[string]interface{}{
+		"tasks": []interface{}{
+			map[string]interface{}{"name": "clone-source", "taskRef": map[string]interface{}{"name": "git-clone", "kind": "ClusterTask"}},
+			map[string]interface{}{"name": "build-image", "taskRef": map[string]interface{}{"name": "buildah", "kind": "ClusterTask"}},
+			map[string]interface{}{"name": "deploy", "taskRef": map[string]interface{}{"name": "deploy-from-source-task", "kind": "Task"}},
+		},
+	}
+	d := &doctor{
+		clients: &DoctorClients{DynamicClient: doctorDynamicClient(pipeline,
+			doctorObject("tekton.dev/v1beta1", "Task", "cicd", "deploy-from-source-task"),
+			doctorObject("tekton.dev/v1beta1", "Task", "cicd", "set-commit-status"),
+			doctorObject("tekton.dev/v1beta1", "ClusterTask", "", "git-clone"))},
+		report: &DoctorReport{},
+	}
+
+	d.checkTasks(m.Config.Pipelines)
+
+	want := []*Check{
+		{Name: "task cicd/deploy-from-source-task", Status: CheckPass, Message: "exists"},
+		{Name: "task cicd/set-commit-status", Status: CheckPass, Message: "exists"},
+		{Name: "clustertask buildah", Status: CheckFail, Message: `clustertasks.tekton.dev "buildah" not found`,
+			Remediation: "Install OpenShift Pipelines, which provides the ClusterTask, or install it from the Tekton catalog"},
+		{Name: "clustertask git-clone", Status: CheckPass, Message: "exists"},
+	}
+	if diff := cmp.Diff(want, d.report.Checks); diff != "" {
+		t.Fatalf("checkTasks() failed:\n%s", diff)
+	}
+}
+
+func TestDiagnoseEventListenerIngress(t *testing.T) {
+	ingressTests := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		want    *Check
+	}{
+		{
+			"admitted ingress",
+			doctorIngress(networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "el.example.com"}}},
+				corev1.LoadBalancerIngress{IP: "192.0.2.10"}),
+			&Check{Name: "ingress cicd/gitops-webhook-event-listener", Status: CheckPass, Message: "has a load balancer address"},
+		},
+		{
+			"ingress with a host that is not admitted",
+			doctorIngress(networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "el.example.com"}}}),
+			&Check{Name: "ingress cicd/gitops-webhook-event-listener", Status: CheckFail, Message: "not admitted, it has no load balancer address",
+				Remediation: "Check that an Ingress controller serves the IngressClass of the Ingress with: kubectl describe ingress gitops-webhook-event-listener -n cicd"},
+		},
+		{
+			"ingress without an address",
+			doctorIngress(networkingv1.IngressSpec{}),
+			&Check{Name: "ingress cicd/gitops-webhook-event-listener", Status: CheckFail, Message: "not admitted, it has no load balancer address",
+				Remediation: "Check that an Ingress controller serves the IngressClass of the Ingress with: kubectl describe ingress gitops-webhook-event-listener -n cicd"},
+		},
+	}
+
+	for _, tt := range ingressTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			d := &doctor{
+				clients: &DoctorClients{KubeClient: fakeKubeClientset.NewSimpleClientset(tt.ingress)},
+				report:  &DoctorReport{},
+			}
+			d.checkEventListener(doctorManifest(config.PlatformKubernetes), "cicd")
+			if diff := cmp.Diff([]*Check{tt.want}, d.report.Checks); diff != "" {
+				rt.Fatalf("checkEventListener() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnoseWithoutPipelinesConfig(t *testing.T) {
+	_, err := diagnose(&config.Manifest{}, nil, &DoctorClients{})
+	test.AssertErrorMatch(t, "failed to find a pipelines configuration", err)
+}
+
+func doctorManifest(platform string) *config.Manifest {
+	return &config.Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Config: &config.Config{
+			Platform:  platform,
+			Pipelines: &config.PipelinesConfig{Name: "cicd", CatalogTasks: map[string]string{"buildah": "0.2", "git-clone": "0.4"}},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "taxi",
+						Services: []*config.Service{
+							{Name: "api", SourceURL: "https://github.com/org/api.git"},
+							{Name: "worker"},
+						},
+					},
+				},
+			},
+			{Name: "stage", Cluster: "https://stage.example.com"},
+		},
+	}
+}
+
+func doctorNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func doctorServiceAccount(secretNames ...string) *corev1.ServiceAccount {
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: saName, Namespace: "cicd"}}
+	for _, n := range secretNames {
+		sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: n})
+	}
+	return sa
+}
+
+func doctorSecret(name, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cicd"},
+		Data:       map[string][]byte{key: []byte("testing")},
+	}
+}
+
+func doctorRoute(admitted corev1.ConditionStatus) *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: eventlisteners.GitOpsWebhookEventListenerRouteName, Namespace: "cicd"},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					RouterName: "default",
+					Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: admitted}},
+				},
+			},
+		},
+	}
+}
+
+func doctorIngress(spec networkingv1.IngressSpec, lbs ...corev1.LoadBalancerIngress) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: eventlisteners.GitOpsWebhookEventListenerIngressName, Namespace: "cicd"},
+		Spec:       spec,
+		Status:     networkingv1.IngressStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: lbs}},
+	}
+}
+
+func doctorDynamicClient(objs ...runtime.Object) *fakeDynamicClient.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}: "PipelineList",
+	}
+	return fakeDynamicClient.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objs...)
+}
+
+func doctorClusterObjects() []runtime.Object {
+	objs := []runtime.Object{}
+	for _, name := range []string{"deploy-from-source-task", "set-commit-status", "buildah", "git-clone"} {
+		objs = append(objs, doctorObject("tekton.dev/v1beta1", "Task", "cicd", name))
+	}
+	for _, name := range []string{"argo-app", "cicd-app"} {
+		objs = append(objs, doctorObject("argoproj.io/v1alpha1", "Application", "argocd", name))
+	}
+	for _, name := range []string{"dev-env", "dev-taxi", "stage-env"} {
+		objs = append(objs, doctorObject("argoproj.io/v1alpha1", "Application", "openshift-gitops", name))
+	}
+	return objs
+}
+
+func doctorObject(apiVersion, kind, ns, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": ns,
+			},
+		},
+	}
+}
@@ -2,7 +2,6 @@ package environments
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,10 +9,12 @@ import (
 
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
 	v1 "k8s.io/api/rbac/v1"
 )
@@ -49,7 +50,7 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 	files := res.Resources{}
 	cfg := m.GetPipelinesConfig()
 
-	parsed, err := url.Parse(m.GitOpsURL)
+	parsed, err := giturl.Parse(m.GitOpsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse GitOpsURL %q: %w", m.GitOpsURL, err)
 	}
@@ -79,7 +80,7 @@ func (b *envBuilder) Application(env *config.Environment, app *config.Applicatio
 
 func (b *envBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
 	svcPath := config.PathForService(app, env, svc.Name)
-	svcFiles, err := filesForService(svcPath)
+	svcFiles, err := filesForService(b.fs, svcPath)
 	if err != nil {
 		return err
 	}
@@ -185,7 +186,7 @@ func createRoleBinding(env *config.Environment, cicdNS, saName string) *v1.RoleB
 	return roles.CreateRoleBinding(meta.NamespacedName(env.Name, fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
 }
 
-func filesForService(svcPath string) (res.Resources, error) {
+func filesForService(fs afero.Fs, svcPath string) (res.Resources, error) {
 	envFiles := res.Resources{}
 	basePath := filepath.ToSlash(filepath.Join(svcPath, "base"))
 	overlaysPath := filepath.ToSlash(filepath.Join(svcPath, "overlays"))
@@ -196,11 +197,29 @@ func filesForService(svcPath string) (res.Resources, error) {
 	}
 	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Bases: []string{"overlays"}}
 	envFiles[filepath.ToSlash(filepath.Join(svcPath, "base", kustomization))] = &res.Kustomization{Bases: []string{"./config"}}
-	envFiles[overlaysFile] = &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}}
+	images, err := overlayImages(fs, overlaysFile)
+	if err != nil {
+		return nil, err
+	}
+	envFiles[overlaysFile] = &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}, Images: images}
 
 	return envFiles, nil
 }
 
+// overlayImages returns the images set in an existing overlay, these are set
+// when images are promoted, and must be kept when the overlay is regenerated.
+func overlayImages(fs afero.Fs, overlaysFile string) ([]res.Image, error) {
+	exists, err := afero.Exists(fs, overlaysFile)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var existing res.Kustomization
+	if err := yaml.UnmarshalItemFromFile(fs, overlaysFile, &existing); err != nil {
+		return nil, err
+	}
+	return existing.Images, nil
+}
+
 // StringSet is a set of strings.
 type StringSet map[string]bool
 
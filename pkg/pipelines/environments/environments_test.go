@@ -169,6 +169,30 @@ func TestBuildEnvironmentsAddsKustomizedFiles(t *testing.T) {
 	}
 }
 
+func TestBuildEnvironmentsKeepsServiceImages(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	overlay := "environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml"
+	mustWriteFile(t, appFs, overlay, []byte(`bases:
+- ../base
+images:
+- name: quay.io/example/service-http
+  newTag: main-a1b2c3
+`), 0644)
+
+	files, err := Build(appFs, buildManifestWithCICD(), "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{
+		Bases:  []string{"../base"},
+		Images: []res.Image{{Name: "quay.io/example/service-http", NewTag: "main-a1b2c3"}},
+	}
+	if diff := cmp.Diff(want, files[overlay]); diff != "" {
+		t.Fatalf("overlay didn't match: %s\n", diff)
+	}
+}
+
 func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 	var appFs = ioutils.NewMemoryFilesystem()
 	m := buildManifest()
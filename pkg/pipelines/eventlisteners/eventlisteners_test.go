@@ -35,7 +35,9 @@ func TestGenerateEventListener(t *testing.T) {
 							CEL: &triggersv1.CELInterceptor{
 								Filter: "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == 'org/test')",
 								Overlays: []triggersv1.CELOverlay{
-									{Key: "ref", Expression: "body.ref.split('/')[2]"},
+									{Key: "ref", Expression: "(body.ref.startsWith('refs/heads/') ? body.ref.substring(11) : body.ref.startsWith('refs/tags/') ? body.ref.substring(10) : body.ref)"},
+									{Key: "ref_type", Expression: "body.ref.startsWith('refs/tags/') ? 'tag' : 'branch'"},
+									{Key: "image_tag", Expression: "('/' + (body.ref.startsWith('refs/heads/') ? body.ref.substring(11) : body.ref.startsWith('refs/tags/') ? body.ref.substring(10) : body.ref).replace('_', '_5f').replace('/', '__').replace('!', '_21').replace('\"', '_22').replace('#', '_23').replace('$', '_24').replace('%', '_25').replace('&', '_26').replace('\\'', '_27').replace('(', '_28').replace(')', '_29').replace('+', '_2b').replace(',', '_2c').replace(';', '_3b').replace('<', '_3c').replace('=', '_3d').replace('>', '_3e').replace('@', '_40').replace(']', '_5d').replace('`', '_60').replace('{', '_7b').replace('|', '_7c').replace('}', '_7d')).replace('/-', '/_2d').substring(1).truncate(87)"},
 								},
 							},
 						},
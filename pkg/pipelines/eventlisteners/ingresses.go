@@ -0,0 +1,16 @@
+package eventlisteners
+
+import (
+	"github.com/redhat-developer/kam/pkg/pipelines/ingresses"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// GitOpsWebhookEventListenerIngressName is the Ingress name for the GitOps
+// Webhook Listener on the kubernetes platform.
+const GitOpsWebhookEventListenerIngressName = "gitops-webhook-event-listener"
+
+// GenerateIngress generates an Ingress for the EventListener, with the
+// IngressClass and host, either can be empty.
+func GenerateIngress(ns, class, host string) (interface{}, error) {
+	return ingresses.New(meta.NamespacedName(ns, GitOpsWebhookEventListenerIngressName), "el-cicd-event-listener", defaultRoutePortName, class, host)
+}
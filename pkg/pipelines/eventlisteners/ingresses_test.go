@@ -0,0 +1,49 @@
+package eventlisteners
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateIngress(t *testing.T) {
+	want := map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]interface{}{
+			"creationTimestamp": nil,
+			"name":              GitOpsWebhookEventListenerIngressName,
+			"namespace":         "cicd",
+		},
+		"spec": map[string]interface{}{
+			"ingressClassName": "nginx",
+			"rules": []interface{}{
+				map[string]interface{}{
+					"host": "el.example.com",
+					"http": map[string]interface{}{
+						"paths": []interface{}{
+							map[string]interface{}{
+								"path":     "/",
+								"pathType": "Prefix",
+								"backend": map[string]interface{}{
+									"service": map[string]interface{}{
+										"name": "el-cicd-event-listener",
+										"port": map[string]interface{}{"name": "http-listener"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ingress, err := GenerateIngress("cicd", "nginx", "el.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, ingress); diff != "" {
+		t.Fatalf("GenerateIngress() failed:\n%s", diff)
+	}
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// isBitbucketServer returns true if the driver name is one of the names that
+// go-scm accepts for Bitbucket Server.
+func isBitbucketServer(driver string) bool {
+	return driver == "stash" || driver == "bitbucketserver"
+}
+
+// splitBitbucketServerURL returns the server URL, and the repository name of
+// the form <PROJECT>/<repo>.
+//
+// Bitbucket Server can be hosted with a context path, and repositories are
+// referenced with either clone URLs e.g. https://example.com/scm/proj/repo.git
+// or browse URLs e.g. https://example.com/projects/PROJ/repos/repo/browse.
+func splitBitbucketServerURL(u *url.URL) (string, string, error) {
+	var components []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			components = append(components, s)
+		}
+	}
+	for i, c := range components {
+		var project, slug string
+		switch {
+		case c == "scm" && len(components) == i+3:
+			project, slug = components[i+1], components[i+2]
+		case c == "projects" && len(components) > i+3 && components[i+2] == "repos":
+			project, slug = components[i+1], components[i+3]
+		default:
+			continue
+		}
+		server := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + strings.Join(components[:i], "/")}
+		return server.String(), strings.ToUpper(project) + "/" + strings.ToLower(strings.TrimSuffix(slug, ".git")), nil
+	}
+	return "", "", fmt.Errorf("failed to get Bitbucket Server repository from %s", u.Path)
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/jenkins-x/go-scm/scm/factory"
+)
+
+func TestSplitBitbucketServerURL(t *testing.T) {
+	urlTests := []struct {
+		url        string
+		wantServer string
+		wantRepo   string
+	}{
+		{"https://bitbucket.example.com/scm/proj/repo.git", "https://bitbucket.example.com/", "PROJ/repo"},
+		{"https://bitbucket.example.com/projects/PROJ/repos/repo/browse", "https://bitbucket.example.com/", "PROJ/repo"},
+		{"https://example.com/bitbucket/scm/proj/Repo.git", "https://example.com/bitbucket", "PROJ/repo"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server, repo, err := splitBitbucketServerURL(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if server != tt.wantServer {
+				t.Errorf("server got %s, want %s", server, tt.wantServer)
+			}
+			if repo != tt.wantRepo {
+				t.Errorf("repo got %s, want %s", repo, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestSplitBitbucketServerURLWithInvalidPath(t *testing.T) {
+	u, err := url.Parse("https://bitbucket.example.com/proj/repo.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = splitBitbucketServerURL(u)
+	if err == nil || err.Error() != "failed to get Bitbucket Server repository from /proj/repo.git" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestBitbucketServerWebhooks(t *testing.T) {
+	defer gock.Off()
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("bitbucket.example.com", "bitbucketserver"))
+
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/PROJ/repos/repo/webhooks").
+		Reply(200).
+		Type("application/json").
+		File("testdata/stash-hooks.json")
+	gock.New("https://bitbucket.example.com").
+		Post("/rest/api/1.0/projects/PROJ/repos/repo/webhooks").
+		MatchType("json").
+		JSON(map[string]interface{}{
+			"name":          "kam-event-listener",
+			"url":           "http://example.com/webhook",
+			"active":        true,
+			"configuration": map[string]string{"secret": "mysecret"},
+			"events":        []string{"repo:refs_changed", "pr:declined", "pr:modified", "pr:deleted", "pr:opened", "pr:merged", "pr:from_ref_updated"},
+		}).
+		Reply(201).
+		Type("application/json").
+		File("testdata/stash-hook.json")
+
+	repo, err := NewRepository("https://bitbucket.example.com/scm/proj/repo.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("got webhook ids %v, want [1]", ids)
+	}
+
+	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != "1" {
+		t.Errorf("failed to create webhook, got %q, want %q", created, "1")
+	}
+	if !gock.IsDone() {
+		t.Fatal("not all Bitbucket Server requests were made")
+	}
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// githubHookResponse is the last response to a delivery of a GitHub webhook,
+// go-scm doesn't provide it.
+type githubHookResponse struct {
+	ID           int `json:"id"`
+	LastResponse struct {
+		Code    *int   `json:"code"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"last_response"`
+}
+
+// LastDeliveries returns the status of the last delivery of each webhook in
+// this repository, by webhook ID.
+//
+// Only GitHub reports the status of deliveries with the webhooks, for other
+// drivers no statuses are returned.
+func (r *Repository) LastDeliveries() (map[string]string, error) {
+	deliveries := map[string]string{}
+	if r.Client.Driver != scm.DriverGithub {
+		return deliveries, nil
+	}
+	for page := 1; page != 0; {
+		hooks, next, err := r.githubHooks(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hooks {
+			status := h.LastResponse.Status
+			if h.LastResponse.Code != nil {
+				status = fmt.Sprintf("%d %s", *h.LastResponse.Code, h.LastResponse.Message)
+			}
+			deliveries[strconv.Itoa(h.ID)] = status
+		}
+		page = next
+	}
+	return deliveries, nil
+}
+
+// githubHooks returns a page of the webhooks in this repository, and the
+// number of the next page, which is 0 for the last page.
+func (r *Repository) githubHooks(page int) ([]githubHookResponse, int, error) {
+	res, err := r.Client.Do(context.Background(), &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("repos/%s/hooks?per_page=%d&page=%d", r.name, hooksPageSize, page),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	if res.Status >= 300 {
+		return nil, 0, fmt.Errorf("failed to get the webhook deliveries: %s", http.StatusText(res.Status))
+	}
+	hooks := []githubHookResponse{}
+	if err := json.NewDecoder(res.Body).Decode(&hooks); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse the webhook deliveries: %w", err)
+	}
+	return hooks, res.Page.Next, nil
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+	"github.com/jenkins-x/go-scm/scm/factory"
+)
+
+func TestLastDeliveries(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`[
+  {"id": 1, "last_response": {"code": 200, "status": "active", "message": "OK"}},
+  {"id": 2, "last_response": {"code": 503, "status": "active", "message": "Service Unavailable"}},
+  {"id": 3, "last_response": {"code": null, "status": "unused", "message": null}}
+]`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := repo.LastDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"1": "200 OK",
+		"2": "503 Service Unavailable",
+		"3": "unused",
+	}
+	if diff := cmp.Diff(want, deliveries); diff != "" {
+		t.Fatalf("deliveries mismatch got\n%s", diff)
+	}
+}
+
+func TestLastDeliveriesWithError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(404).
+		SetHeaders(mockHeaders)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.LastDeliveries()
+	if err == nil || err.Error() != "failed to get the webhook deliveries: Not Found" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestLastDeliveriesWithPages(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		MatchParam("page", "1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		SetHeader("Link", `<https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="last"`).
+		BodyString(`[{"id": 1, "last_response": {"code": 200, "status": "active", "message": "OK"}}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		MatchParam("page", "2").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`[{"id": 2, "last_response": {"code": 503, "status": "active", "message": "Service Unavailable"}}]`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := repo.LastDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"1": "200 OK",
+		"2": "503 Service Unavailable",
+	}
+	if diff := cmp.Diff(want, deliveries); diff != "" {
+		t.Fatalf("deliveries mismatch got\n%s", diff)
+	}
+	if !gock.IsDone() {
+		t.Fatal("not all the pages were requested")
+	}
+}
+
+func TestLastDeliveriesWithRedirect(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(300).
+		SetHeaders(mockHeaders)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.LastDeliveries()
+	if err == nil || err.Error() != "failed to get the webhook deliveries: Multiple Choices" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestLastDeliveriesWithoutSupport(t *testing.T) {
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+	repo, err := NewRepository("https://fake.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := repo.LastDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("got %d deliveries, want 0", len(deliveries))
+	}
+}
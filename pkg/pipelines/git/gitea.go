@@ -0,0 +1,29 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const giteaDriver = "gitea"
+
+// splitGiteaURL returns the server URL, and the repository name of the form
+// <owner>/<repo>.
+//
+// Gitea can be hosted with a sub-path e.g. https://example.com/gitea, the
+// repository is the last two elements of the path.
+func splitGiteaURL(u *url.URL) (string, string, error) {
+	var components []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			components = append(components, s)
+		}
+	}
+	if len(components) < 2 {
+		return "", "", fmt.Errorf("failed to get Gitea repository from %s", u.Path)
+	}
+	n := len(components)
+	server := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + strings.Join(components[:n-2], "/")}
+	return server.String(), components[n-2] + "/" + strings.TrimSuffix(components[n-1], ".git"), nil
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestGiteaWebhooks(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "testing")
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+
+	repo, err := NewRepository(gitea.URL+"/org/test.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerURL := "http://example.com/webhook"
+	id, err := repo.CreateWebhook(listenerURL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{id}, ids); diff != "" {
+		t.Fatalf("created id mismatch got\n%s", diff)
+	}
+	hook := gitea.Hooks["org/test"][0]
+	if hook.Config["secret"] != "secret" || hook.Config["content_type"] != "json" {
+		t.Fatalf("hook created with incorrect configuration: %#v", hook.Config)
+	}
+
+	deleted, err := repo.DeleteWebhooks(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ids, deleted); diff != "" {
+		t.Fatalf("deleted ids mismatch got\n%s", diff)
+	}
+	if l := len(gitea.Hooks["org/test"]); l != 0 {
+		t.Fatalf("got %d hooks after deletion, want 0", l)
+	}
+}
+
+func TestGiteaWebhooksWithSubPath(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "testing")
+	gitea.Prefix = "/gitea"
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+
+	repo, err := NewRepository(gitea.URL+"/gitea/org/test.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateWebhook("http://example.com/webhook", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if l := len(gitea.Hooks["org/test"]); l != 1 {
+		t.Fatalf("got %d hooks, want 1", l)
+	}
+}
+
+func TestSplitGiteaURL(t *testing.T) {
+	urlTests := []struct {
+		rawURL     string
+		wantServer string
+		wantName   string
+		wantErr    string
+	}{
+		{"https://gitea.example.com/org/repo.git", "https://gitea.example.com/", "org/repo", ""},
+		{"https://example.com/gitea/org/repo.git", "https://example.com/gitea", "org/repo", ""},
+		{"https://example.com/repo.git", "", "", "failed to get Gitea repository from /repo.git"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.rawURL, func(rt *testing.T) {
+			u, err := url.Parse(tt.rawURL)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			server, name, err := splitGiteaURL(u)
+			if !test.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if server != tt.wantServer || name != tt.wantName {
+				rt.Fatalf("splitGiteaURL() got (%q, %q), want (%q, %q)", server, name, tt.wantServer, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestHookTarget(t *testing.T) {
+	targetTests := []struct {
+		target string
+		want   string
+	}{
+		{"http://example.com/webhook", "http://example.com/webhook"},
+		{"http://example.com/webhook?secret=test", "http://example.com/webhook"},
+		{"http://example.com/webhook?a=b&secret=test", "http://example.com/webhook?a=b"},
+	}
+
+	for _, tt := range targetTests {
+		if got := hookTarget(tt.target); got != tt.want {
+			t.Errorf("hookTarget(%q) got %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -9,8 +9,14 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 )
 
+// WebhookName is the name given to created webhooks, on drivers that support
+// naming hooks.
+const WebhookName = "kam-event-listener"
+
 // Repository represent a Git repository ofa specific Git repository URL
 type Repository struct {
 	*scm.Client
@@ -21,10 +27,29 @@ type Repository struct {
 
 // NewRepository creates a new Git repository object
 func NewRepository(rawURL, token string) (*Repository, error) {
-	parsed, err := url.Parse(rawURL)
+	normalized, err := giturl.Normalize(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(normalized)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
 	}
+	driver, err := factory.DefaultIdentifier.Identify(parsed.Host)
+	if err != nil {
+		return nil, err
+	}
+	if HasBasePath(driver) {
+		serverURL, repoName, err := SplitServerURL(parsed, driver)
+		if err != nil {
+			return nil, err
+		}
+		client, err := factory.NewClient(driver, serverURL, token)
+		if err != nil {
+			return nil, err
+		}
+		return &Repository{name: repoName, Client: client}, nil
+	}
 	parsed.User = url.UserPassword("", token)
 	client, err := factory.FromRepoURL(parsed.String())
 	if err != nil {
@@ -38,16 +63,36 @@ func NewRepository(rawURL, token string) (*Repository, error) {
 	return &Repository{name: repoName, Client: client}, nil
 }
 
+// HasBasePath returns true if the driver can be hosted on a base path of the
+// host e.g. https://example.com/gitea, the base path is part of the server URL
+// and not of the repository name.
+func HasBasePath(driver string) bool {
+	return isBitbucketServer(driver) || driver == giteaDriver
+}
+
+// SplitServerURL returns the server URL, including the base path, and the
+// repository name of the form <namespace>/<repo>, for the drivers that can be
+// hosted on a base path.
+func SplitServerURL(u *url.URL, driver string) (string, string, error) {
+	switch {
+	case isBitbucketServer(driver):
+		return splitBitbucketServerURL(u)
+	case driver == giteaDriver:
+		return splitGiteaURL(u)
+	}
+	return "", "", fmt.Errorf("failed to get the server URL from %s, %s is not hosted on a base path", u, driver)
+}
+
 // ListWebhooks returns a list of webhook IDs of the given listener in this repository
 func (r *Repository) ListWebhooks(listenerURL string) ([]string, error) {
-	hooks, _, err := r.Client.Repositories.ListHooks(context.Background(), r.name, scm.ListOptions{})
+	hooks, err := r.listHooks()
 	if err != nil {
 		return nil, err
 	}
 
 	ids := []string{}
 	for _, hook := range hooks {
-		if hook.Target == listenerURL {
+		if hookTarget(hook.Target) == listenerURL {
 			ids = append(ids, hook.ID)
 		}
 	}
@@ -73,6 +118,7 @@ func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 // It returns ID of the created webhook
 func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	in := &scm.HookInput{
+		Name:   WebhookName,
 		Target: listenerURL,
 		Secret: secret,
 		Events: scm.HookEvents{
@@ -82,13 +128,99 @@ func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	}
 
 	created, _, err := r.Client.Repositories.CreateHook(context.Background(), r.name, in)
-	return created.ID, err
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// Webhooks returns all the webhooks in this repository, the targets of the
+// webhooks don't include the secret.
+func (r *Repository) Webhooks() ([]*scm.Hook, error) {
+	hooks, err := r.listHooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Target = hookTarget(hook.Target)
+	}
+	return hooks, nil
+}
+
+// hooksPageSize is the number of webhooks requested in each page, this is the
+// maximum that GitHub allows.
+const hooksPageSize = 100
+
+// listHooks returns all the webhooks in this repository, reading every page
+// that the Git host returns.
+func (r *Repository) listHooks() ([]*scm.Hook, error) {
+	hooks := []*scm.Hook{}
+	for page := 1; page != 0; {
+		pageHooks, res, err := r.Client.Repositories.ListHooks(context.Background(), r.name, scm.ListOptions{Page: page, Size: hooksPageSize})
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, pageHooks...)
+		page = 0
+		if res != nil {
+			page = res.Page.Next
+		}
+	}
+	return hooks, nil
+}
+
+// HasWebhookEvents returns true if the webhook delivers the push and pull
+// request events that are processed by the EventListener.
+//
+// Webhooks that don't report their events, and webhooks on drivers with
+// unknown events, are assumed to deliver the events.
+func (r *Repository) HasWebhookEvents(hook *scm.Hook) bool {
+	required, ok := webhookEvents[r.Client.Driver]
+	if !ok || len(hook.Events) == 0 {
+		return true
+	}
+	events := map[string]bool{}
+	for _, e := range hook.Events {
+		events[e] = true
+	}
+	for _, e := range required {
+		if !events[e] {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookEvents are the native events, by driver, that are delivered by the
+// webhooks that are created by CreateWebhook.
+var webhookEvents = map[scm.Driver][]string{
+	scm.DriverGithub: {"push", "pull_request"},
+	scm.DriverGitea:  {"push", "pull_request"},
+	scm.DriverGitlab: {"push", "merge"},
+	scm.DriverStash:  {"repo:refs_changed", "pr:opened", "pr:from_ref_updated"},
+}
+
+// hookTarget returns the hook's target URL without the secret, the go-scm
+// Gitea driver adds the secret to the target as a query parameter.
+func hookTarget(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	q := u.Query()
+	if _, ok := q["secret"]; !ok {
+		return target
+	}
+	q.Del("secret")
+	u.RawQuery = q.Encode()
+	return u.String()
 }
 
-// TODO: this likely won't work for GitLab projects because it assumes that the
-// path is always composed of two elements.
 // GetRepoName takes a URL of the form https://github.com/my-org/my-repo.git and
 // attempts to determine the name of the repo from this, i.e. "my-org/my-repo".
+//
+// GitLab projects in nested subgroups keep all the path elements, i.e.
+// "group/subgroup/my-repo".
 func GetRepoName(u *url.URL) (string, error) {
 	var components []string
 	for _, s := range strings.Split(u.Path, "/") {
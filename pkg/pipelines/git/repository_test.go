@@ -1,11 +1,13 @@
 package git
 
 import (
+	"fmt"
 	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h2non/gock"
+	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
 )
 
@@ -104,6 +106,102 @@ func TestListWebHooks(t *testing.T) {
 	}
 }
 
+func TestWebhooks(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/hooks.json")
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := repo.Webhooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*scm.Hook{
+		{ID: "1", Target: "http://example.com/webhook", Events: []string{"push", "pull_request"}, Active: true},
+	}
+	if diff := cmp.Diff(want, hooks); diff != "" {
+		t.Errorf("hooks mismatch got\n%s", diff)
+	}
+}
+
+func TestWebhooksWithPages(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		MatchParam("page", "1").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		SetHeader("Link", `<https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="last"`).
+		BodyString(`[{"id": 1, "active": true, "events": ["push"], "config": {"url": "http://example.com/webhook"}}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		MatchParam("page", "2").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`[{"id": 2, "active": true, "events": ["push"], "config": {"url": "http://example.com/other"}}]`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := repo.Webhooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*scm.Hook{
+		{ID: "1", Target: "http://example.com/webhook", Events: []string{"push"}, Active: true},
+		{ID: "2", Target: "http://example.com/other", Events: []string{"push"}, Active: true},
+	}
+	if diff := cmp.Diff(want, hooks); diff != "" {
+		t.Errorf("hooks mismatch got\n%s", diff)
+	}
+	if !gock.IsDone() {
+		t.Fatal("not all the pages were requested")
+	}
+}
+
+func TestHasWebhookEvents(t *testing.T) {
+	eventTests := []struct {
+		driver scm.Driver
+		events []string
+		want   bool
+	}{
+		{scm.DriverGithub, []string{"push", "pull_request"}, true},
+		{scm.DriverGithub, []string{"pull_request", "issues", "push"}, true},
+		{scm.DriverGithub, []string{"push"}, false},
+		{scm.DriverGithub, nil, true},
+		{scm.DriverGitlab, []string{"push", "merge"}, true},
+		{scm.DriverGitlab, []string{"tag", "push"}, false},
+		{scm.DriverStash, []string{"repo:refs_changed", "pr:opened", "pr:from_ref_updated"}, true},
+		{scm.DriverStash, []string{"repo:refs_changed"}, false},
+		{scm.DriverFake, []string{"push"}, true},
+	}
+
+	for _, tt := range eventTests {
+		t.Run(fmt.Sprintf("%s %v", tt.driver, tt.events), func(t *testing.T) {
+			repo := &Repository{Client: &scm.Client{Driver: tt.driver}}
+			if got := repo.HasWebhookEvents(&scm.Hook{Events: tt.events}); got != tt.want {
+				t.Errorf("HasWebhookEvents() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDeleteWebHooks(t *testing.T) {
 	defer gock.Off()
 
@@ -153,6 +251,29 @@ func TestCreateWebHook(t *testing.T) {
 	}
 }
 
+func TestCreateWebHookWithError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Post("/repos/foo/bar/hooks").
+		Reply(422).
+		Type("application/json").
+		SetHeaders(mockHeaders)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret")
+	if err == nil || err.Error() != "Unprocessable Entity" {
+		t.Fatalf("got error %v", err)
+	}
+	if created != "" {
+		t.Errorf("CreateWebhook() got %q, want no ID", created)
+	}
+}
+
 func TestGetRepoName(t *testing.T) {
 	urlTests := []struct {
 		url      string
@@ -161,6 +282,7 @@ func TestGetRepoName(t *testing.T) {
 		{"https://github.com/example/gitops.git?ref=main", "example/gitops"},
 		{"https://github.com/example/testing.git", "example/testing"},
 		{"https://gitlab.com/project/example/testing.git", "project/example/testing"},
+		{"https://gitlab.com/group/subgroup/team/project.git", "group/subgroup/team/project"},
 	}
 
 	for _, tt := range urlTests {
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// CanUpdateWebhooks returns true if the webhooks in this repository can be
+// updated in place with UpdateWebhook.
+//
+// go-scm can't update webhooks, so webhooks are updated with the API of the
+// drivers that are supported here, on other drivers webhooks must be replaced.
+func (r *Repository) CanUpdateWebhooks() bool {
+	switch r.Client.Driver {
+	case scm.DriverGithub, scm.DriverGitlab, scm.DriverGitea:
+		return true
+	}
+	return false
+}
+
+// UpdateWebhook updates the target and the secret of the webhook with the ID,
+// the webhook keeps its ID, and the events that it delivers.
+func (r *Repository) UpdateWebhook(id, listenerURL, secret string) error {
+	switch r.Client.Driver {
+	case scm.DriverGithub:
+		in := map[string]interface{}{
+			"active": true,
+			"config": map[string]string{
+				"url":          listenerURL,
+				"content_type": "json",
+				"secret":       secret,
+				"insecure_ssl": "0",
+			},
+		}
+		return r.hookRequest(http.MethodPatch, fmt.Sprintf("repos/%s/hooks/%s", r.name, id), in)
+	case scm.DriverGitlab:
+		in := map[string]string{
+			"url":   listenerURL,
+			"token": secret,
+		}
+		return r.hookRequest(http.MethodPut, fmt.Sprintf("api/v4/projects/%s/hooks/%s", strings.ReplaceAll(r.name, "/", "%2F"), id), in)
+	case scm.DriverGitea:
+		// The Gitea driver adds the secret to the target, as well as the
+		// configuration, when it creates webhooks.
+		target, err := url.Parse(listenerURL)
+		if err != nil {
+			return fmt.Errorf("failed to parse the listener URL %q: %w", listenerURL, err)
+		}
+		params := target.Query()
+		params.Set("secret", secret)
+		target.RawQuery = params.Encode()
+		in := map[string]interface{}{
+			"active": true,
+			"config": map[string]string{
+				"url":          target.String(),
+				"content_type": "json",
+				"secret":       secret,
+			},
+		}
+		return r.hookRequest(http.MethodPatch, fmt.Sprintf("api/v1/repos/%s/hooks/%s", r.name, id), in)
+	}
+	return fmt.Errorf("updating webhooks is not supported by the %s driver", r.Client.Driver)
+}
+
+// hookRequest sends the JSON body to the webhook API of the driver, the body
+// of responses with an error status is returned in the error.
+func (r *Repository) hookRequest(method, path string, in interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	res, err := r.Client.Do(context.Background(), &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(b),
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status >= 300 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", http.StatusText(res.Status), err)
+		}
+		return fmt.Errorf("%s: %s", http.StatusText(res.Status), strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestUpdateWebhookWithGitHub(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Patch("/repos/foo/bar/hooks/1").
+		MatchType("json").
+		JSON(map[string]interface{}{
+			"active": true,
+			"config": map[string]string{
+				"url":          "http://example.com/webhook",
+				"content_type": "json",
+				"secret":       "new-secret",
+				"insecure_ssl": "0",
+			},
+		}).
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"id": 1}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.CanUpdateWebhooks() {
+		t.Fatal("webhooks can't be updated on GitHub")
+	}
+	if err := repo.UpdateWebhook("1", "http://example.com/webhook", "new-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("the webhook was not updated")
+	}
+}
+
+func TestUpdateWebhookWithGitLab(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Put("/api/v4/projects/foo/bar/hooks/1").
+		MatchType("json").
+		JSON(map[string]string{"url": "http://example.com/webhook", "token": "new-secret"}).
+		Reply(200).
+		Type("application/json").
+		BodyString(`{"id": 1}`)
+
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateWebhook("1", "http://example.com/webhook", "new-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("the webhook was not updated")
+	}
+}
+
+func TestUpdateWebhookWithGitea(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "testing")
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+
+	repo, err := NewRepository(gitea.URL+"/org/test.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerURL := "http://example.com/webhook"
+	id, err := repo.CreateWebhook(listenerURL, "old-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.UpdateWebhook(id, listenerURL, "new-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != id {
+		t.Fatalf("got webhooks %v after updating, want %s", ids, id)
+	}
+	hook := gitea.Hooks["org/test"][0]
+	if hook.Config["secret"] != "new-secret" || hook.Config["url"] != listenerURL+"?secret=new-secret" {
+		t.Fatalf("hook updated with incorrect configuration: %#v", hook.Config)
+	}
+}
+
+func TestUpdateWebhookWithError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Patch("/repos/foo/bar/hooks/1").
+		Reply(422).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		BodyString(`{"message": "Validation Failed"}`)
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.UpdateWebhook("1", "http://example.com/webhook", "new-secret")
+	if !test.ErrorMatch(t, `Unprocessable Entity: {"message": "Validation Failed"}`, err) {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestUpdateWebhookWithoutSupport(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+
+	repo, err := NewRepository("https://fake.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.CanUpdateWebhooks() {
+		t.Fatal("webhooks can be updated with the fake driver")
+	}
+	err = repo.UpdateWebhook("1", "http://example.com/webhook", "new-secret")
+	if !test.ErrorMatch(t, "updating webhooks is not supported by the fake driver", err) {
+		t.Fatalf("got error %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package giturl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// bitbucketServerSSHPort is the default port that Bitbucket Server serves
+// SSH clones on.
+const bitbucketServerSSHPort = "7999"
+
+// Parse parses a Git repository URL.
+//
+// In addition to the URLs accepted by url.Parse, this accepts scp-style
+// SSH URLs e.g. "git@github.com:org/repo.git", which are returned as
+// "ssh://git@github.com/org/repo.git".
+func Parse(rawURL string) (*url.URL, error) {
+	if isSCPStyle(rawURL) {
+		userHost := rawURL[:strings.Index(rawURL, ":")]
+		rawURL = "ssh://" + userHost + "/" + rawURL[len(userHost)+1:]
+	}
+	return url.Parse(rawURL)
+}
+
+// Normalize returns the canonical form of a Git repository URL.
+//
+// SSH URLs, either scp-style or with the ssh:// scheme, are converted to the
+// equivalent HTTPS URL e.g. "git@github.com:org/repo.git" becomes
+// "https://github.com/org/repo.git", any other URL is returned unchanged.
+//
+// SSH URLs on the Bitbucket Server SSH port e.g.
+// "ssh://git@bitbucket.example.com:7999/proj/repo.git" are converted to the
+// Bitbucket Server HTTPS clone URL, which has an /scm/ prefix, on the default
+// HTTPS port e.g. "https://bitbucket.example.com/scm/proj/repo.git".
+func Normalize(rawURL string) (string, error) {
+	u, err := Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Git URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "ssh" && u.Scheme != "git+ssh" {
+		return rawURL, nil
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("failed to parse Git URL %q: host is empty", rawURL)
+	}
+	path := u.Path
+	if u.Port() == bitbucketServerSSHPort && !strings.HasPrefix(path, "/scm/") {
+		path = "/scm" + path
+	}
+	https := &url.URL{
+		Scheme: "https",
+		Host:   u.Hostname(),
+		Path:   path,
+	}
+	return https.String(), nil
+}
+
+// IsSSH returns true if the URL is an SSH URL.
+func IsSSH(rawURL string) bool {
+	if isSCPStyle(rawURL) {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "ssh" || u.Scheme == "git+ssh"
+}
+
+// isSCPStyle returns true for URLs of the form [user@]host:path, which have
+// no scheme, and a ":" before the first "/", the path is relative, so that
+// malformed URLs like "https:/example.com" are not treated as SSH URLs.
+func isSCPStyle(rawURL string) bool {
+	if strings.Contains(rawURL, "://") {
+		return false
+	}
+	colon := strings.Index(rawURL, ":")
+	if colon < 1 || strings.HasPrefix(rawURL[colon+1:], "/") {
+		return false
+	}
+	slash := strings.Index(rawURL, "/")
+	return slash == -1 || colon < slash
+}
@@ -0,0 +1,84 @@
+package giturl
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestParse(t *testing.T) {
+	urlTests := []struct {
+		rawURL   string
+		wantHost string
+		wantPath string
+	}{
+		{"https://github.com/org/repo.git", "github.com", "/org/repo.git"},
+		{"git@github.com:org/repo.git", "github.com", "/org/repo.git"},
+		{"git@gitlab.com:group/subgroup/repo.git", "gitlab.com", "/group/subgroup/repo.git"},
+		{"ssh://git@bitbucket.example.com:7999/proj/repo.git", "bitbucket.example.com:7999", "/proj/repo.git"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.rawURL, func(rt *testing.T) {
+			u, err := Parse(tt.rawURL)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if u.Host != tt.wantHost {
+				rt.Errorf("host got %q, want %q", u.Host, tt.wantHost)
+			}
+			if u.Path != tt.wantPath {
+				rt.Errorf("path got %q, want %q", u.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	urlTests := []struct {
+		rawURL  string
+		want    string
+		wantErr string
+	}{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", ""},
+		{"http://gitea.example.com/org/repo.git", "http://gitea.example.com/org/repo.git", ""},
+		{"git@github.com:org/repo.git", "https://github.com/org/repo.git", ""},
+		{"github.com:org/repo.git", "https://github.com/org/repo.git", ""},
+		{"ssh://git@github.com/org/repo.git", "https://github.com/org/repo.git", ""},
+		{"ssh://git@gitlab.example.com:2222/group/subgroup/repo.git", "https://gitlab.example.com/group/subgroup/repo.git", ""},
+		{"ssh://git@bitbucket.example.com:7999/proj/repo.git", "https://bitbucket.example.com/scm/proj/repo.git", ""},
+		{"ssh://git@bitbucket.example.com:7999/scm/proj/repo.git", "https://bitbucket.example.com/scm/proj/repo.git", ""},
+		{"git+ssh://git@bitbucket.example.com:7999/~user/repo.git", "https://bitbucket.example.com/scm/~user/repo.git", ""},
+		{"ssh:///org/repo.git", "", "host is empty"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.rawURL, func(rt *testing.T) {
+			got, err := Normalize(tt.rawURL)
+			if !test.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("error mismatch: got %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				rt.Errorf("Normalize(%q) got %q, want %q", tt.rawURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSSH(t *testing.T) {
+	urlTests := []struct {
+		rawURL string
+		want   bool
+	}{
+		{"https://github.com/org/repo.git", false},
+		{"git@github.com:org/repo.git", true},
+		{"ssh://git@github.com/org/repo.git", true},
+		{"org/repo", false},
+	}
+
+	for _, tt := range urlTests {
+		if got := IsSSH(tt.rawURL); got != tt.want {
+			t.Errorf("IsSSH(%q) got %v, want %v", tt.rawURL, got, tt.want)
+		}
+	}
+}
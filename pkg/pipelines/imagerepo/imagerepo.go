@@ -53,6 +53,20 @@ func ValidateImageRepo(imageRepo string) (bool, string, error) {
 	return false, "", imageRepoValidationErrors(imageRepo)
 }
 
+// ValidateImageRepoForPlatform validates the input image repo like
+// ValidateImageRepo, the internal registry is only available on the
+// "openshift" platform.
+func ValidateImageRepoForPlatform(imageRepo, platform string) (bool, string, error) {
+	isInternalRegistry, repo, err := ValidateImageRepo(imageRepo)
+	if err != nil {
+		return false, "", err
+	}
+	if isInternalRegistry && platform == config.PlatformKubernetes {
+		return false, "", fmt.Errorf("failed to use image repo:%s, the internal registry is only available on OpenShift, expected image repository in the form <registry>/<username>/<repository>", imageRepo)
+	}
+	return isInternalRegistry, repo, nil
+}
+
 func isBlank(s string) bool {
 	return strings.TrimSpace(s) == "" || len(s) > len(strings.TrimSpace(s))
 }
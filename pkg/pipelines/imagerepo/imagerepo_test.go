@@ -2,6 +2,7 @@ package imagerepo
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -166,3 +167,35 @@ func TestValidateImageRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateImageRepoForPlatform(t *testing.T) {
+	tests := []struct {
+		imageRepo    string
+		platform     string
+		wantInternal bool
+		wantRepo     string
+		wantErr      string
+	}{
+		{"project/app", config.PlatformOpenShift, true, "image-registry.openshift-image-registry.svc:5000/project/app", ""},
+		{"quay.io/sample-user/sample-repo", config.PlatformKubernetes, false, "quay.io/sample-user/sample-repo", ""},
+		{"project/app", config.PlatformKubernetes, false, "", "failed to use image repo:project/app, the internal registry is only available on OpenShift"},
+		{"image-registry.openshift-image-registry.svc:5000/project/app", config.PlatformKubernetes, false, "", "the internal registry is only available on OpenShift"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imageRepo+" on "+tt.platform, func(t *testing.T) {
+			isInternalRegistry, imageRepo, err := ValidateImageRepoForPlatform(tt.imageRepo, tt.platform)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ValidateImageRepoForPlatform() got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isInternalRegistry != tt.wantInternal || imageRepo != tt.wantRepo {
+				t.Errorf("ValidateImageRepoForPlatform() got (%v, %s), want (%v, %s)", isInternalRegistry, imageRepo, tt.wantInternal, tt.wantRepo)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ingresses
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+var (
+	ingressTypeMeta = meta.TypeMeta("Ingress", "networking.k8s.io/v1")
+)
+
+// NewFromService creates and returns an Ingress for the first port of the
+// provided Service, with the same name as the Service.
+func NewFromService(svc *corev1.Service, class, host string) (interface{}, error) {
+	return New(meta.NamespacedName(svc.Namespace, svc.Name), svc.Name, svc.Spec.Ports[0].Name, class, host)
+}
+
+// New creates and returns an Ingress that routes all paths on the host to the
+// named port of a Service, if the host is empty, the Ingress matches all
+// hosts.
+//
+// It strips out the Status field from the Ingress as this causes issues when
+// being created in a cluster.
+func New(name types.NamespacedName, serviceName, portName, class, host string) (interface{}, error) {
+	i := createIngress(name, serviceName, portName, class, host)
+	b, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		return nil, err
+	}
+	// This is removed because it causes synchronisation issues in ArgoCD.
+	delete(result, "status")
+	return result, nil
+}
+
+func createIngress(name types.NamespacedName, serviceName, portName, class, host string) networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := networkingv1.Ingress{
+		TypeMeta:   ingressTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{Name: portName},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if class != "" {
+		ingress.Spec.IngressClassName = &class
+	}
+	return ingress
+}
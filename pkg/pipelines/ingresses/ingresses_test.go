@@ -0,0 +1,88 @@
+package ingresses
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+const (
+	testNS   = "testing"
+	testName = "test-service"
+)
+
+var testSvc = &corev1.Service{
+	TypeMeta:   meta.TypeMeta("Service", "v1"),
+	ObjectMeta: meta.ObjectMeta(meta.NamespacedName(testNS, testName)),
+	Spec: corev1.ServiceSpec{
+		Ports: []corev1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080)},
+		},
+	},
+}
+
+func TestNewFromService(t *testing.T) {
+	want := map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]interface{}{
+			"creationTimestamp": nil,
+			"name":              testName,
+			"namespace":         testNS,
+		},
+		"spec": map[string]interface{}{
+			"ingressClassName": "nginx",
+			"rules": []interface{}{
+				map[string]interface{}{
+					"host": "test-service.example.com",
+					"http": map[string]interface{}{
+						"paths": []interface{}{
+							map[string]interface{}{
+								"path":     "/",
+								"pathType": "Prefix",
+								"backend": map[string]interface{}{
+									"service": map[string]interface{}{
+										"name": testName,
+										"port": map[string]interface{}{"name": "http"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ingress, err := NewFromService(testSvc, "nginx", "test-service.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, ingress); diff != "" {
+		t.Fatalf("NewFromService() failed:\n%s", diff)
+	}
+}
+
+func TestNewWithoutClassAndHost(t *testing.T) {
+	ingress, err := New(meta.NamespacedName(testNS, "listener"), "el-listener", "http-listener", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := ingress.(map[string]interface{})["spec"].(map[string]interface{})
+	if _, ok := spec["ingressClassName"]; ok {
+		t.Fatalf("got ingressClassName %v, want none", spec["ingressClassName"])
+	}
+	rule := spec["rules"].([]interface{})[0].(map[string]interface{})
+	if _, ok := rule["host"]; ok {
+		t.Fatalf("got host %v, want none", rule["host"])
+	}
+}
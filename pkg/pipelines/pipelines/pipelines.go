@@ -3,11 +3,14 @@ package pipelines
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 )
@@ -16,10 +19,67 @@ var (
 	pipelineTypeMeta = meta.TypeMeta("Pipeline", "tekton.dev/v1beta1")
 )
 
-const pipelineWorkspace = "shared-data"
+const (
+	// SourceWorkspace is the workspace of the CI Pipelines that the source is
+	// cloned into.
+	SourceWorkspace = "shared-data"
+	// CacheWorkspace is the optional workspace of the AppCIPipelines that is
+	// shared between PipelineRuns, the tests and the buildpacks build cache
+	// dependencies in it.
+	CacheWorkspace = "build-cache"
 
-// CreateAppCIPipeline creates AppCIPipeline
-func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
+	pipelineWorkspace = SourceWorkspace
+)
+
+// CreateAppCIPipeline creates AppCIPipeline, which builds images from a
+// Dockerfile with buildah.
+//
+// The tests are run in order, after the source is cloned and before the image
+// is built.
+func CreateAppCIPipeline(name types.NamespacedName, tests ...*config.TestStep) *pipelinev1.Pipeline {
+	return createAppCIPipeline(name, createBuildImageTask, tests)
+}
+
+// CreateS2IAppCIPipeline creates an AppCIPipeline that builds images from
+// source with a Source-to-Image builder image.
+func CreateS2IAppCIPipeline(name types.NamespacedName, tests ...*config.TestStep) *pipelinev1.Pipeline {
+	return createAppCIPipeline(name, createS2IBuildImageTask, tests)
+}
+
+// CreateBuildpacksAppCIPipeline creates an AppCIPipeline that builds images
+// from source with a Cloud Native Buildpacks builder image.
+func CreateBuildpacksAppCIPipeline(name types.NamespacedName, tests ...*config.TestStep) *pipelinev1.Pipeline {
+	return createAppCIPipeline(name, createBuildpacksBuildImageTask, tests)
+}
+
+// CreateKanikoAppCIPipeline creates an AppCIPipeline that builds images from a
+// Dockerfile with kaniko.
+func CreateKanikoAppCIPipeline(name types.NamespacedName, tests ...*config.TestStep) *pipelinev1.Pipeline {
+	return createAppCIPipeline(name, createKanikoBuildImageTask, tests)
+}
+
+// createAppCIPipeline creates an AppCIPipeline with the task that builds the
+// image, all the AppCIPipelines have the same parameters, so that they can be
+// run from the same TriggerTemplate.
+//
+// Each test reports its status with its own commit status context, as the
+// image is not built if a test fails, the final status is the aggregate
+// status of the tasks, so that it fails if a test fails.
+func createAppCIPipeline(name types.NamespacedName, buildTask func(name, runAfter string) pipelinev1.PipelineTask, tests []*config.TestStep) *pipelinev1.Pipeline {
+	tasks := []pipelinev1.PipelineTask{
+		createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
+		createGitCloneTask("clone-source"),
+	}
+	finally := []pipelinev1.PipelineTask{}
+	runAfter := "clone-source"
+	for _, test := range tests {
+		taskName := testTaskName(test)
+		tasks = append(tasks, createTestTask(taskName, runAfter, test))
+		finally = append(finally, createTestStatusPipelineTask(taskName))
+		runAfter = taskName
+	}
+	tasks = append(tasks, buildTask("build-image", runAfter))
+	finally = append(finally, createCommitStatusPipelineTask("set-final-status", "$(tasks.status)", "The build is complete"))
 	return &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
@@ -34,49 +94,207 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 				"COMMIT_DATE",
 				"COMMIT_AUTHOR",
 				"COMMIT_MESSAGE",
-				"GIT_REPO"),
-			Tasks: []pipelinev1.PipelineTask{
-				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
-				createGitCloneTask("clone-source"),
-				createBuildImageTask("build-image", "clone-source"),
-			},
+				"GIT_REPO",
+				"CONTEXT",
+				"DOCKERFILE",
+				"BUILDER_IMAGE"),
+			Tasks: tasks,
 			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
 				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+				{Name: CacheWorkspace, Description: "This workspace caches dependencies between builds.", Optional: true},
 			},
-			Finally: []pipelinev1.PipelineTask{
-				createCommitStatusPipelineTask("set-final-status", "$(tasks.build-image.status)", "The build is complete"),
+			Finally: finally,
+		},
+	}
+}
+
+// AddUpdateGitOpsTask adds a finally task to an AppCIPipeline that updates
+// the image for the service in the GitOps repository, once the image is built.
+func AddUpdateGitOpsTask(p *pipelinev1.Pipeline, gitOpsURL, envName, serviceName string, cfg *config.UpdateGitOps) {
+	branch := cfg.Branch
+	if branch == "" {
+		branch = "main"
+	}
+	p.Spec.Finally = append(p.Spec.Finally, pipelinev1.PipelineTask{
+		Name:    "update-gitops",
+		TaskRef: createTaskRef("update-gitops", pipelinev1.NamespacedTaskKind),
+		Params: []pipelinev1.Param{
+			createTaskParam("GITOPS_REPO", gitOpsURL),
+			createTaskParam("GITOPS_BRANCH", branch),
+			createTaskParam("PULL_REQUEST", strconv.FormatBool(cfg.PullRequest)),
+			createTaskParam("ENVIRONMENT", envName),
+			createTaskParam("SERVICE", serviceName),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("BUILD_STATUS", "$(tasks.build-image.status)"),
+		},
+	})
+}
+
+func testTaskName(test *config.TestStep) string {
+	return "test-" + test.Name
+}
+
+// createTestTask creates an inline Task that runs the test script in the
+// directory that the image is built from.
+func createTestTask(name, runAfter string, test *config.TestStep) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name: name,
+		TaskSpec: &pipelinev1.EmbeddedTask{
+			TaskSpec: pipelinev1.TaskSpec{
+				Params: []pipelinev1.ParamSpec{
+					{Name: "CONTEXT", Type: pipelinev1.ParamTypeString},
+				},
+				Workspaces: []pipelinev1.WorkspaceDeclaration{
+					{Name: "source"},
+					{Name: "cache", Optional: true},
+				},
+				Steps: []pipelinev1.Step{
+					{
+						Container: corev1.Container{
+							Name:       test.Name,
+							Image:      test.Image,
+							WorkingDir: "$(workspaces.source.path)/$(params.CONTEXT)",
+							Env:        testEnvVars(test.Env),
+						},
+						Script: test.Script,
+					},
+				},
 			},
 		},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+			{Name: "cache", Workspace: CacheWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
+			createTaskParam("CONTEXT", "$(params.CONTEXT)"),
+		},
 	}
 }
 
+// testEnvVars returns the environment variables sorted by name, so that the
+// generated Pipeline is stable.
+func testEnvVars(env map[string]string) []corev1.EnvVar {
+	if len(env) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vars := make([]corev1.EnvVar, len(names))
+	for i, name := range names {
+		vars[i] = corev1.EnvVar{Name: name, Value: env[name]}
+	}
+	return vars
+}
+
+func createTestStatusPipelineTask(testTask string) pipelinev1.PipelineTask {
+	task := createCommitStatusPipelineTask("set-"+testTask+"-status", "$(tasks."+testTask+".status)", "The "+testTask+" task is complete")
+	task.Params = append(task.Params, createTaskParam("CONTEXT", "continous-integration/tekton/"+testTask))
+	return task
+}
+
 func createBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef("buildah", pipelinev1.NamespacedTaskKind),
 		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
 			{Name: "source", Workspace: pipelineWorkspace},
 		},
 		RunAfter: []string{runAfter},
 		Params: []pipelinev1.Param{
 			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
-			createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()),
+			createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()+" $(params.BUILD_EXTRA_ARGS)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("CONTEXT", "$(params.CONTEXT)"),
+			createTaskParam("DOCKERFILE", "$(params.DOCKERFILE)"),
+		},
+	}
+}
+
+func createS2IBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    name,
+		TaskRef: createTaskRef("s2i", pipelinev1.NamespacedTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
+			createTaskParam("BUILDER_IMAGE", "$(params.BUILDER_IMAGE)"),
+			createTaskParam("PATH_CONTEXT", "$(params.CONTEXT)"),
+			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+		},
+	}
+}
+
+func createBuildpacksBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    name,
+		TaskRef: createTaskRef("buildpacks", pipelinev1.NamespacedTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+			{Name: "cache", Workspace: CacheWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
+			createTaskParam("APP_IMAGE", "$(params.IMAGE)"),
+			createTaskParam("BUILDER_IMAGE", "$(params.BUILDER_IMAGE)"),
+			createTaskParam("SOURCE_SUBPATH", "$(params.CONTEXT)"),
+		},
+	}
+}
+
+func createKanikoBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    name,
+		TaskRef: createTaskRef("kaniko", pipelinev1.NamespacedTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
 			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("CONTEXT", "$(params.CONTEXT)"),
+			createTaskParam("DOCKERFILE", "$(params.DOCKERFILE)"),
 		},
 	}
 }
 
+// SetKanikoExtraArgs passes the args to the kaniko Task that builds the image
+// in an AppCIPipeline, the EXTRA_ARGS of the kaniko Task is an array, so the
+// args can't be passed with the BUILD_EXTRA_ARGS parameter.
+func SetKanikoExtraArgs(p *pipelinev1.Pipeline, args []string) {
+	for i := range p.Spec.Tasks {
+		task := &p.Spec.Tasks[i]
+		if task.Name != "build-image" {
+			continue
+		}
+		task.Params = append(task.Params, pipelinev1.Param{
+			Name:  "EXTRA_ARGS",
+			Value: pipelinev1.ArrayOrString{Type: pipelinev1.ParamTypeArray, ArrayVal: args},
+		})
+	}
+}
+
 func createGitCloneTask(name string) pipelinev1.PipelineTask {
+	return createGitCloneTaskForRevision(name, "$(params.GIT_REF)")
+}
+
+func createGitCloneTaskForRevision(name, revision string) pipelinev1.PipelineTask {
 	// The output workspace mapping here comes from the git-clone task.
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("git-clone", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef("git-clone", pipelinev1.NamespacedTaskKind),
 		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
 			{Name: "output", Workspace: pipelineWorkspace},
 		},
 		Params: []pipelinev1.Param{
 			createTaskParam("url", "$(params.GIT_REPO)"),
-			createTaskParam("revision", "$(params.GIT_REF)"),
+			createTaskParam("revision", revision),
 		},
 		RunAfter: []string{"set-pending-status"},
 	}
@@ -131,6 +349,30 @@ func CreateCIPipeline(name types.NamespacedName, stageNamespace string) *pipelin
 	}
 }
 
+// CreateCIPipelineWithWorkspace creates a CI pipeline that clones the source
+// with the git-clone task into a workspace, rather than using a git
+// PipelineResource.
+func CreateCIPipelineWithWorkspace(name types.NamespacedName, stageNamespace string) *pipelinev1.Pipeline {
+	return &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
+				createGitCloneTaskForRevision("clone-source", "$(params.COMMIT_SHA)"),
+				createCIPipelineWorkspaceTask("apply-source"),
+			},
+			Params: paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"),
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Finally: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The build is complete"),
+			},
+		},
+	}
+}
+
 // CreateAppCDPipeline creates AppCDPipelin
 func CreateAppCDPipeline(name types.NamespacedName, deploymentPath, devNamespace string, isInternalRegistry bool) *pipelinev1.Pipeline {
 	return &pipelinev1.Pipeline{
@@ -163,6 +405,20 @@ func createCIPipelineTask(taskName string) pipelinev1.PipelineTask {
 	}
 }
 
+func createCIPipelineWorkspaceTask(taskName string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    taskName,
+		TaskRef: createTaskRef("deploy-from-source-task", pipelinev1.NamespacedTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		Params: []pipelinev1.Param{
+			createTaskParam("DRYRUN", "true"),
+		},
+		RunAfter: []string{"clone-source"},
+	}
+}
+
 func createDevCDDeployImageTask(name, devNamespace, deploymentPath string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:     name,
@@ -206,7 +462,7 @@ func createInputTaskResource(name, resource string) pipelinev1.PipelineTaskInput
 func createDevCDBuildImageTask(name string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef("buildah", pipelinev1.NamespacedTaskKind),
 		Params: []pipelinev1.Param{
 			createTaskParam("TLSVERIFY", "true"),
 		},
@@ -6,8 +6,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
 
@@ -67,16 +69,20 @@ func TestCreateAppCIPipeline(t *testing.T) {
 				"COMMIT_DATE",
 				"COMMIT_AUTHOR",
 				"COMMIT_MESSAGE",
-				"GIT_REPO"),
+				"GIT_REPO",
+				"CONTEXT",
+				"DOCKERFILE",
+				"BUILDER_IMAGE"),
 			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
 				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+				{Name: CacheWorkspace, Description: "This workspace caches dependencies between builds.", Optional: true},
 			},
 			Tasks: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
 
 				{
 					Name:    "clone-source",
-					TaskRef: &pipelinev1.TaskRef{Name: "git-clone", Kind: "ClusterTask"},
+					TaskRef: &pipelinev1.TaskRef{Name: "git-clone", Kind: "Task"},
 					Params: []pipelinev1.Param{
 						createTaskParam("url", "$(params.GIT_REPO)"),
 						createTaskParam("revision", "$(params.GIT_REF)"),
@@ -90,7 +96,7 @@ func TestCreateAppCIPipeline(t *testing.T) {
 				{
 					Name:     "build-image",
 					RunAfter: []string{"clone-source"},
-					TaskRef:  &pipelinev1.TaskRef{Name: "buildah", Kind: "ClusterTask"},
+					TaskRef:  &pipelinev1.TaskRef{Name: "buildah", Kind: "Task"},
 					Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
 						{Name: "source", Workspace: pipelineWorkspace},
 					},
@@ -100,15 +106,17 @@ func TestCreateAppCIPipeline(t *testing.T) {
 							Name: "BUILD_EXTRA_ARGS",
 							Value: pipelinev1.ArrayOrString{
 								Type:      "string",
-								StringVal: metadataLabelArgs(),
+								StringVal: metadataLabelArgs() + " $(params.BUILD_EXTRA_ARGS)",
 							},
 						},
 						createTaskParam("IMAGE", "$(params.IMAGE)"),
+						createTaskParam("CONTEXT", "$(params.CONTEXT)"),
+						createTaskParam("DOCKERFILE", "$(params.DOCKERFILE)"),
 					},
 				},
 			},
 			Finally: []v1beta1.PipelineTask{
-				createCommitStatusPipelineTask("set-final-status", "$(tasks.build-image.status)", "The build is complete"),
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.status)", "The build is complete"),
 			},
 		},
 	}
@@ -117,3 +125,193 @@ func TestCreateAppCIPipeline(t *testing.T) {
 		t.Fatalf("CreateAppCIPipeline failed:\n%s", diff)
 	}
 }
+
+func TestCreateAppCIPipelineForStrategies(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	strategyTests := []struct {
+		name     string
+		pipeline *pipelinev1.Pipeline
+		wantTask pipelinev1.PipelineTask
+	}{
+		{
+			"s2i", CreateS2IAppCIPipeline(name),
+			pipelinev1.PipelineTask{
+				Name:     "build-image",
+				RunAfter: []string{"clone-source"},
+				TaskRef:  &pipelinev1.TaskRef{Name: "s2i", Kind: "Task"},
+				Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+					{Name: "source", Workspace: pipelineWorkspace},
+				},
+				Params: []pipelinev1.Param{
+					createTaskParam("BUILDER_IMAGE", "$(params.BUILDER_IMAGE)"),
+					createTaskParam("PATH_CONTEXT", "$(params.CONTEXT)"),
+					createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+					createTaskParam("IMAGE", "$(params.IMAGE)"),
+				},
+			},
+		},
+		{
+			"buildpacks", CreateBuildpacksAppCIPipeline(name),
+			pipelinev1.PipelineTask{
+				Name:     "build-image",
+				RunAfter: []string{"clone-source"},
+				TaskRef:  &pipelinev1.TaskRef{Name: "buildpacks", Kind: "Task"},
+				Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+					{Name: "source", Workspace: pipelineWorkspace},
+					{Name: "cache", Workspace: CacheWorkspace},
+				},
+				Params: []pipelinev1.Param{
+					createTaskParam("APP_IMAGE", "$(params.IMAGE)"),
+					createTaskParam("BUILDER_IMAGE", "$(params.BUILDER_IMAGE)"),
+					createTaskParam("SOURCE_SUBPATH", "$(params.CONTEXT)"),
+				},
+			},
+		},
+		{
+			"kaniko", CreateKanikoAppCIPipeline(name),
+			pipelinev1.PipelineTask{
+				Name:     "build-image",
+				RunAfter: []string{"clone-source"},
+				TaskRef:  &pipelinev1.TaskRef{Name: "kaniko", Kind: "Task"},
+				Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+					{Name: "source", Workspace: pipelineWorkspace},
+				},
+				Params: []pipelinev1.Param{
+					createTaskParam("IMAGE", "$(params.IMAGE)"),
+					createTaskParam("CONTEXT", "$(params.CONTEXT)"),
+					createTaskParam("DOCKERFILE", "$(params.DOCKERFILE)"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range strategyTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			want := CreateAppCIPipeline(name)
+			want.Spec.Tasks[2] = tt.wantTask
+			if diff := cmp.Diff(want, tt.pipeline); diff != "" {
+				rt.Fatalf("pipeline for %s failed:\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestSetKanikoExtraArgs(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateKanikoAppCIPipeline(name)
+	SetKanikoExtraArgs(p, []string{"--build-arg=APP=api", "--build-arg=VERSION=1.0"})
+
+	want := pipelinev1.Param{
+		Name: "EXTRA_ARGS",
+		Value: pipelinev1.ArrayOrString{
+			Type:     pipelinev1.ParamTypeArray,
+			ArrayVal: []string{"--build-arg=APP=api", "--build-arg=VERSION=1.0"},
+		},
+	}
+	params := p.Spec.Tasks[2].Params
+	if diff := cmp.Diff(want, params[len(params)-1]); diff != "" {
+		t.Fatalf("SetKanikoExtraArgs() failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithTests(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name,
+		&config.TestStep{Name: "unit", Image: "golang:1.16", Script: "go test ./...", Env: map[string]string{"GOFLAGS": "-mod=vendor", "CGO_ENABLED": "0"}},
+		&config.TestStep{Name: "lint", Image: "golangci/golangci-lint:v1.39", Script: "golangci-lint run"},
+	)
+
+	want := CreateAppCIPipeline(name)
+	want.Spec.Tasks = []pipelinev1.PipelineTask{
+		want.Spec.Tasks[0],
+		want.Spec.Tasks[1],
+		{
+			Name: "test-unit",
+			TaskSpec: &pipelinev1.EmbeddedTask{
+				TaskSpec: pipelinev1.TaskSpec{
+					Params:     []pipelinev1.ParamSpec{{Name: "CONTEXT", Type: "string"}},
+					Workspaces: []pipelinev1.WorkspaceDeclaration{{Name: "source"}, {Name: "cache", Optional: true}},
+					Steps: []pipelinev1.Step{
+						{
+							Container: corev1.Container{
+								Name:       "unit",
+								Image:      "golang:1.16",
+								WorkingDir: "$(workspaces.source.path)/$(params.CONTEXT)",
+								Env: []corev1.EnvVar{
+									{Name: "CGO_ENABLED", Value: "0"},
+									{Name: "GOFLAGS", Value: "-mod=vendor"},
+								},
+							},
+							Script: "go test ./...",
+						},
+					},
+				},
+			},
+			Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+				{Name: "source", Workspace: pipelineWorkspace},
+				{Name: "cache", Workspace: CacheWorkspace},
+			},
+			RunAfter: []string{"clone-source"},
+			Params:   []pipelinev1.Param{createTaskParam("CONTEXT", "$(params.CONTEXT)")},
+		},
+		{
+			Name: "test-lint",
+			TaskSpec: &pipelinev1.EmbeddedTask{
+				TaskSpec: pipelinev1.TaskSpec{
+					Params:     []pipelinev1.ParamSpec{{Name: "CONTEXT", Type: "string"}},
+					Workspaces: []pipelinev1.WorkspaceDeclaration{{Name: "source"}, {Name: "cache", Optional: true}},
+					Steps: []pipelinev1.Step{
+						{
+							Container: corev1.Container{
+								Name:       "lint",
+								Image:      "golangci/golangci-lint:v1.39",
+								WorkingDir: "$(workspaces.source.path)/$(params.CONTEXT)",
+							},
+							Script: "golangci-lint run",
+						},
+					},
+				},
+			},
+			Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+				{Name: "source", Workspace: pipelineWorkspace},
+				{Name: "cache", Workspace: CacheWorkspace},
+			},
+			RunAfter: []string{"test-unit"},
+			Params:   []pipelinev1.Param{createTaskParam("CONTEXT", "$(params.CONTEXT)")},
+		},
+		createBuildImageTask("build-image", "test-lint"),
+	}
+	unitStatus := createCommitStatusPipelineTask("set-test-unit-status", "$(tasks.test-unit.status)", "The test-unit task is complete")
+	unitStatus.Params = append(unitStatus.Params, createTaskParam("CONTEXT", "continous-integration/tekton/test-unit"))
+	lintStatus := createCommitStatusPipelineTask("set-test-lint-status", "$(tasks.test-lint.status)", "The test-lint task is complete")
+	lintStatus.Params = append(lintStatus.Params, createTaskParam("CONTEXT", "continous-integration/tekton/test-lint"))
+	want.Spec.Finally = append([]pipelinev1.PipelineTask{unitStatus, lintStatus}, want.Spec.Finally...)
+
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Fatalf("CreateAppCIPipeline failed:\n%s", diff)
+	}
+}
+
+func TestAddUpdateGitOpsTask(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name)
+	AddUpdateGitOpsTask(p, "https://github.com/org/gitops.git", "dev", "http-api", &config.UpdateGitOps{PullRequest: true})
+
+	want := CreateAppCIPipeline(name)
+	want.Spec.Finally = append(want.Spec.Finally, pipelinev1.PipelineTask{
+		Name:    "update-gitops",
+		TaskRef: createTaskRef("update-gitops", pipelinev1.NamespacedTaskKind),
+		Params: []pipelinev1.Param{
+			createTaskParam("GITOPS_REPO", "https://github.com/org/gitops.git"),
+			createTaskParam("GITOPS_BRANCH", "main"),
+			createTaskParam("PULL_REQUEST", "true"),
+			createTaskParam("ENVIRONMENT", "dev"),
+			createTaskParam("SERVICE", "http-api"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("BUILD_STATUS", "$(tasks.build-image.status)"),
+		},
+	})
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Fatalf("AddUpdateGitOpsTask failed:\n%s", diff)
+	}
+}
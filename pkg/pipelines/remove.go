@@ -0,0 +1,154 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// RemoveServiceOptions control which service is removed from the
+// configuration.
+type RemoveServiceOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+	ServiceName         string
+}
+
+// RemoveEnvOptions control which environment is removed from the
+// configuration.
+type RemoveEnvOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+}
+
+// RemoveService is the entry-point from the CLI for removing services.
+//
+// The service is removed from the manifest, and the files generated for it are
+// deleted, including its image binding and webhook secret, and the
+// application if it has no remaining services.
+func RemoveService(o *RemoveServiceOptions, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	env := m.GetEnvironment(o.EnvName)
+	if env == nil {
+		return fmt.Errorf("environment %s does not exist", o.EnvName)
+	}
+	svc := findService(env, o.ServiceName)
+	app, err := m.RemoveService(o.EnvName, o.ServiceName)
+	if err != nil {
+		return err
+	}
+	paths := serviceArtifacts(m, env, app, svc)
+	if len(app.Services) == 0 && app.ConfigRepo == nil {
+		paths = append(paths, applicationArtifacts(env, app)...)
+	}
+	return removeArtifacts(m, appFs, o.PipelinesFolderPath, paths)
+}
+
+// RemoveEnv is the entry-point from the CLI for removing environments.
+//
+// The environment is removed from the manifest, and the files generated for
+// it, and all of its applications and services, are deleted.
+func RemoveEnv(o *RemoveEnvOptions, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	env, err := m.RemoveEnvironment(o.EnvName)
+	if err != nil {
+		return err
+	}
+	paths := []string{
+		config.PathForEnvironment(env),
+		filepath.Join(config.PathForArgoCD(), env.Name+"-env-app.yaml"),
+	}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			paths = append(paths, serviceArtifacts(m, env, app, svc)...)
+		}
+		paths = append(paths, applicationArtifacts(env, app)...)
+	}
+	return removeArtifacts(m, appFs, o.PipelinesFolderPath, paths)
+}
+
+// removeArtifacts deletes the paths, which are relative to the pipelines
+// folder, and regenerates the manifest, kustomizations and other resources
+// that are built from the manifest.
+//
+// The manifest is validated, and the resources built, before any files are
+// deleted.
+func removeArtifacts(m *config.Manifest, appFs afero.Fs, pipelinesFolderPath string, paths []string) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	files := res.Resources{pipelinesFile: m}
+	built, err := buildResources(appFs, pipelinesFolderPath, m)
+	if err != nil {
+		return fmt.Errorf("failed to build resources: %v", err)
+	}
+	files = res.Merge(built, files)
+	for _, p := range paths {
+		if err := appFs.RemoveAll(filepath.Join(pipelinesFolderPath, p)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+	if _, err := yaml.WriteResources(appFs, pipelinesFolderPath, files); err != nil {
+		return err
+	}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		base := filepath.ToSlash(filepath.Join(pipelinesFolderPath, config.PathForPipelines(cfg), "base"))
+		return updateKustomization(appFs, base)
+	}
+	return nil
+}
+
+// serviceArtifacts returns the paths, relative to the pipelines folder, of the
+// files generated for a service.
+func serviceArtifacts(m *config.Manifest, env *config.Environment, app *config.Application, svc *config.Service) []string {
+	paths := []string{config.PathForService(app, env, svc.Name)}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return paths
+	}
+	cicdBase := filepath.Join(config.PathForPipelines(cfg), "base")
+	pipelineName := serviceCIPipelineName(env, svc)
+	paths = append(paths,
+		filepath.Join(cicdBase, makeSvcImageBindingFilename(makeSvcImageBindingName(env.Name, app.Name, svc.Name))),
+		filepath.Join(cicdBase, serviceCITemplatePath(serviceCITemplateName(env, svc))),
+		getAppCIPipelinePath(config.PathForPipelines(cfg), pipelineName),
+		getAppCIPipelinePath(config.PathForPipelines(cfg), pipelineName+"-promote"))
+	if svc.Webhook != nil && svc.Webhook.Secret != nil {
+		filename := svc.Webhook.Secret.Name + ".yaml"
+		paths = append(paths,
+			filepath.Join(cicdBase, cicdSecretsPath, filename),
+			filepath.Join("..", "secrets", filename))
+	}
+	return paths
+}
+
+// applicationArtifacts returns the paths, relative to the pipelines folder, of
+// the files generated for an application.
+func applicationArtifacts(env *config.Environment, app *config.Application) []string {
+	return []string{
+		config.PathForApplication(env, app),
+		filepath.Join(config.PathForArgoCD(), env.Name+"-"+app.Name+"-app.yaml"),
+	}
+}
+
+func findService(env *config.Environment, serviceName string) *config.Service {
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name == serviceName {
+				return svc
+			}
+		}
+	}
+	return nil
+}
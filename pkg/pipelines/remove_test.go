@@ -0,0 +1,186 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestRemoveService(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	assertExists(t, fakeFs,
+		"/gitops/environments/tst-dev/apps/app-http-api",
+		"/gitops/config/argocd/tst-dev-app-http-api-app.yaml",
+		"/gitops/config/tst-cicd/base/05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
+		"/secrets/webhook-secret-tst-dev-http-api.yaml")
+
+	err := RemoveService(&RemoveServiceOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+		ServiceName:         "http-api",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	if apps := m.GetEnvironment("tst-dev").Apps; len(apps) != 0 {
+		t.Fatalf("the application was not removed from the manifest: %#v", apps)
+	}
+	assertRemoved(t, fakeFs,
+		"/gitops/environments/tst-dev/apps/app-http-api",
+		"/gitops/config/argocd/tst-dev-app-http-api-app.yaml",
+		"/gitops/config/tst-cicd/base/05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
+		"/secrets/webhook-secret-tst-dev-http-api.yaml")
+	assertExists(t, fakeFs,
+		"/gitops/environments/tst-dev/env/base/kustomization.yaml",
+		"/secrets/gitops-webhook-secret.yaml")
+	assertKustomizationExcludes(t, fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml",
+		"05-bindings/tst-dev-app-http-api-http-api-binding.yaml")
+	assertKustomizationExcludes(t, fakeFs, "/gitops/config/argocd/kustomization.yaml",
+		"tst-dev-app-http-api-app.yaml")
+}
+
+func TestRemoveServiceWithServicePipelines(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	env := m.GetEnvironment("tst-dev")
+	env.Pipelines.Tests = []*config.TestStep{{Name: "unit", Image: "golang:1.16", Script: "go test ./..."}}
+	env.Pipelines.UpdateGitOps = &config.UpdateGitOps{}
+	b, err := yaml.Marshal(m)
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", b, 0644))
+	assertNoError(t, BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs))
+	assertExists(t, fakeFs,
+		"/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-tst-dev-http-api.yaml",
+		"/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-tst-dev-http-api-promote.yaml")
+
+	err = RemoveService(&RemoveServiceOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+		ServiceName:         "http-api",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	assertRemoved(t, fakeFs,
+		"/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-tst-dev-http-api.yaml",
+		"/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-tst-dev-http-api-promote.yaml")
+	assertKustomizationExcludes(t, fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml",
+		"04-pipelines/app-ci-pipeline-tst-dev-http-api.yaml",
+		"04-pipelines/app-ci-pipeline-tst-dev-http-api-promote.yaml")
+}
+
+func TestRemoveServiceWithUnknownService(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := RemoveService(&RemoveServiceOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+		ServiceName:         "unknown",
+	}, fakeFs)
+	test.AssertErrorMatch(t, "service unknown does not exist in environment tst-dev", err)
+}
+
+func TestRemoveArtifactsWithInvalidManifest(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	m.Environments = append(m.Environments, &config.Environment{Name: "Invalid_Name"})
+
+	err = removeArtifacts(m, fakeFs, "/gitops", []string{"environments/tst-dev/apps/app-http-api"})
+	if err == nil {
+		t.Fatal("expected the invalid manifest to fail validation")
+	}
+	assertExists(t, fakeFs, "/gitops/environments/tst-dev/apps/app-http-api")
+}
+
+func TestRemoveEnv(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := RemoveEnv(&RemoveEnvOptions{
+		PipelinesFolderPath: "/gitops",
+		EnvName:             "tst-dev",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	if env := m.GetEnvironment("tst-dev"); env != nil {
+		t.Fatalf("the environment was not removed from the manifest: %#v", env)
+	}
+	assertRemoved(t, fakeFs,
+		"/gitops/environments/tst-dev",
+		"/gitops/config/argocd/tst-dev-env-app.yaml",
+		"/gitops/config/argocd/tst-dev-app-http-api-app.yaml",
+		"/gitops/config/tst-cicd/base/05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
+		"/secrets/webhook-secret-tst-dev-http-api.yaml")
+	assertExists(t, fakeFs, "/gitops/environments/tst-stage/env/base/kustomization.yaml")
+	assertKustomizationExcludes(t, fakeFs, "/gitops/config/argocd/kustomization.yaml",
+		"tst-dev-env-app.yaml", "tst-dev-app-http-api-app.yaml")
+}
+
+func TestRemoveEnvWithUnknownEnvironment(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := RemoveEnv(&RemoveEnvOptions{PipelinesFolderPath: "/gitops", EnvName: "unknown"}, fakeFs)
+	test.AssertErrorMatch(t, "environment unknown does not exist", err)
+}
+
+func bootstrapForRemoval(t *testing.T) afero.Fs {
+	t.Helper()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	err := Bootstrap(&BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+	}, fakeFs)
+	assertNoError(t, err)
+	return fakeFs
+}
+
+func assertRemoved(t *testing.T, fs afero.Fs, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		exists, err := afero.Exists(fs, p)
+		assertNoError(t, err)
+		if exists {
+			t.Errorf("%s was not removed", p)
+		}
+	}
+}
+
+func assertExists(t *testing.T, fs afero.Fs, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		exists, err := afero.Exists(fs, p)
+		assertNoError(t, err)
+		if !exists {
+			t.Errorf("%s does not exist", p)
+		}
+	}
+}
+
+func assertKustomizationExcludes(t *testing.T, fs afero.Fs, path string, resources ...string) {
+	t.Helper()
+	k := struct {
+		Resources []string `json:"resources"`
+	}{}
+	data, err := afero.ReadFile(fs, path)
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(data, &k))
+	for _, r := range resources {
+		if containsString(k.Resources, r) {
+			t.Errorf("%s still includes %s", path, r)
+		}
+	}
+}
@@ -1,18 +1,58 @@
 package resources
 
-import "sort"
+import (
+	"sort"
+	"strings"
+)
 
 // Kustomization is a structural representation of the Kustomize file format.
 type Kustomization struct {
 	Resources    []string          `json:"resources,omitempty"`
 	Bases        []string          `json:"bases,omitempty"`
 	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	Images       []Image           `json:"images,omitempty"`
+}
+
+// Image is a Kustomize image override, it replaces the image with the name in
+// the resources with the new name, tag or digest.
+type Image struct {
+	Name    string `json:"name,omitempty"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
 }
 
 func (k *Kustomization) AddResources(s ...string) {
 	k.Resources = removeDuplicatesAndSort(append(k.Resources, s...))
 }
 
+// SetImage adds the image override, replacing any existing override for the
+// same image name.
+func (k *Kustomization) SetImage(image Image) {
+	for i := range k.Images {
+		if k.Images[i].Name == image.Name {
+			k.Images[i] = image
+			return
+		}
+	}
+	k.Images = append(k.Images, image)
+	sort.Slice(k.Images, func(i, j int) bool { return k.Images[i].Name < k.Images[j].Name })
+}
+
+// ParseImage parses an image reference e.g. "quay.io/org/app:v1" or
+// "quay.io/org/app@sha256:..." into an override for the image with the same
+// name.
+func ParseImage(ref string) Image {
+	if i := strings.Index(ref, "@"); i != -1 {
+		return Image{Name: ref[:i], Digest: ref[i+1:]}
+	}
+	// The tag follows the last ":" after the last "/", other ":" are ports.
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return Image{Name: ref[:i], NewTag: ref[i+1:]}
+	}
+	return Image{Name: ref}
+}
+
 func removeDuplicatesAndSort(s []string) []string {
 	exists := make(map[string]bool)
 	out := []string{}
@@ -33,3 +33,39 @@ func Test_AddResource_sorts_elements(t *testing.T) {
 		t.Fatalf("failed to sort resources:\n%s", diff)
 	}
 }
+
+func Test_SetImage(t *testing.T) {
+	k := Kustomization{}
+	k.SetImage(Image{Name: "quay.io/org/web", NewTag: "v1"})
+	k.SetImage(Image{Name: "quay.io/org/api", NewTag: "v1"})
+	k.SetImage(Image{Name: "quay.io/org/web", NewTag: "v2"})
+
+	want := []Image{
+		{Name: "quay.io/org/api", NewTag: "v1"},
+		{Name: "quay.io/org/web", NewTag: "v2"},
+	}
+	if diff := cmp.Diff(want, k.Images); diff != "" {
+		t.Fatalf("failed to set images:\n%s", diff)
+	}
+}
+
+func Test_ParseImage(t *testing.T) {
+	imageTests := []struct {
+		ref  string
+		want Image
+	}{
+		{"quay.io/org/app", Image{Name: "quay.io/org/app"}},
+		{"quay.io/org/app:main-a1b2c3", Image{Name: "quay.io/org/app", NewTag: "main-a1b2c3"}},
+		{"registry.example.com:5000/org/app:v1", Image{Name: "registry.example.com:5000/org/app", NewTag: "v1"}},
+		{"registry.example.com:5000/org/app", Image{Name: "registry.example.com:5000/org/app"}},
+		{"quay.io/org/app@sha256:abcdef", Image{Name: "quay.io/org/app", Digest: "sha256:abcdef"}},
+	}
+
+	for _, tt := range imageTests {
+		t.Run(tt.ref, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, ParseImage(tt.ref)); diff != "" {
+				rt.Fatalf("ParseImage(%q) failed:\n%s", tt.ref, diff)
+			}
+		})
+	}
+}
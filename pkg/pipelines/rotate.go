@@ -0,0 +1,141 @@
+package pipelines
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// RotateSecretOptions control which webhook secret is rotated.
+type RotateSecretOptions struct {
+	PipelinesFolderPath string
+	IsCICD              bool
+	EnvName             string
+	ServiceName         string
+	WebhookSecret       string // If not provided, a new secret is generated.
+	SealedSecretsCert   string
+}
+
+// RotatedWebhookSecret is a regenerated webhook secret that hasn't been
+// written yet.
+type RotatedWebhookSecret struct {
+	Value string
+
+	pipelinesFolderPath string
+	cicdBase            string
+	files               res.Resources
+	otherResources      res.Resources
+}
+
+// RotateWebhookSecret regenerates the webhook secret for the CICD or a
+// service according to the configured secrets mode.
+//
+// The new secret value should be used to update the hooks on the Git host
+// before the secret is written with Write, so that a failure to update the
+// hooks leaves the existing secret in place.
+func RotateWebhookSecret(o *RotateSecretOptions, appFs afero.Fs) (*RotatedWebhookSecret, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	files, otherResources, value, err := rotatedSecretResources(m, appFs, o)
+	if err != nil {
+		return nil, err
+	}
+	return &RotatedWebhookSecret{
+		Value:               value,
+		pipelinesFolderPath: o.PipelinesFolderPath,
+		cicdBase:            filepath.ToSlash(filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(m.GetPipelinesConfig()), "base")),
+		files:               files,
+		otherResources:      otherResources,
+	}, nil
+}
+
+// Write rewrites the secret file with the rotated secret.
+func (r *RotatedWebhookSecret) Write(appFs afero.Fs) error {
+	if _, err := yaml.WriteResources(appFs, r.pipelinesFolderPath, r.files); err != nil {
+		return err
+	}
+	if _, err := yaml.WriteResources(appFs, filepath.Join(r.pipelinesFolderPath, ".."), r.otherResources); err != nil {
+		return err
+	}
+	if len(r.files) > 0 {
+		return updateKustomization(appFs, r.cicdBase)
+	}
+	return nil
+}
+
+func rotatedSecretResources(m *config.Manifest, appFs afero.Fs, o *RotateSecretOptions) (res.Resources, res.Resources, string, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, nil, "", errors.New("failed to find a pipeline configuration - unable to rotate the webhook secret")
+	}
+	name, filename, err := webhookSecretForRotation(m, cfg, o)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	secretsOut, err := newSecretOutputs(appFs, m.GetSecretsConfig(), o.SealedSecretsCert)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	value := o.WebhookSecret
+	if value == "" {
+		if secretsOut.mode == config.SecretsModeExternal {
+			return nil, nil, "", errors.New("the new webhook secret must be provided when the secrets mode is external, it should be populated in the secret store first")
+		}
+		value, err = secrets.GenerateString(webhookSecretLength)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to generate webhook secret: %v", err)
+		}
+	}
+
+	secret, err := secrets.CreateUnsealedSecret(meta.NamespacedName(name.Namespace, name.Name), value, eventlisteners.WebhookSecretKey)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	cicdFiles := res.Resources{}
+	otherResources := res.Resources{}
+	if _, err := secretsOut.add(cicdFiles, otherResources, filename, secret); err != nil {
+		return nil, nil, "", err
+	}
+	files := res.Resources{}
+	if secretsOut.mode != config.SecretsModeExternal {
+		files = addPrefixToResources(filepath.Join(config.PathForPipelines(cfg), "base"), cicdFiles)
+	}
+	return files, otherResources, value, nil
+}
+
+// webhookSecretForRotation returns the name of the webhook secret to rotate,
+// and the filename that the secret is written to.
+func webhookSecretForRotation(m *config.Manifest, cfg *config.PipelinesConfig, o *RotateSecretOptions) (types.NamespacedName, string, error) {
+	if o.IsCICD {
+		return meta.NamespacedName(cfg.Name, eventlisteners.GitOpsWebhookSecret), "gitops-webhook-secret.yaml", nil
+	}
+	env := m.GetEnvironment(o.EnvName)
+	if env == nil {
+		return types.NamespacedName{}, "", fmt.Errorf("environment %s does not exist", o.EnvName)
+	}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name != o.ServiceName {
+				continue
+			}
+			if svc.Webhook == nil || svc.Webhook.Secret == nil {
+				return types.NamespacedName{}, "", fmt.Errorf("service %s in environment %s has no webhook secret", o.ServiceName, o.EnvName)
+			}
+			return meta.NamespacedName(svc.Webhook.Secret.Namespace, svc.Webhook.Secret.Name), svc.Webhook.Secret.Name + ".yaml", nil
+		}
+	}
+	return types.NamespacedName{}, "", fmt.Errorf("service %s does not exist in environment %s", o.ServiceName, o.EnvName)
+}
@@ -0,0 +1,150 @@
+package pipelines
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestRotatedSecretResources(t *testing.T) {
+	rotateTests := []struct {
+		name     string
+		opts     *RotateSecretOptions
+		wantName string
+		wantFile string
+	}{
+		{"cicd", &RotateSecretOptions{IsCICD: true, WebhookSecret: "new-secret"}, eventlisteners.GitOpsWebhookSecret, "secrets/gitops-webhook-secret.yaml"},
+		{"service", &RotateSecretOptions{EnvName: "test-dev", ServiceName: "test-svc", WebhookSecret: "new-secret"}, "webhook-secret-test-dev-test-svc", "secrets/webhook-secret-test-dev-test-svc.yaml"},
+	}
+
+	for _, tt := range rotateTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			files, otherResources, value, err := rotatedSecretResources(buildManifest(true, false), ioutils.NewMemoryFilesystem(), tt.opts)
+			assertNoError(rt, err)
+
+			want, err := secrets.CreateUnsealedSecret(meta.NamespacedName("cicd", tt.wantName), "new-secret", eventlisteners.WebhookSecretKey)
+			assertNoError(rt, err)
+			if diff := cmp.Diff(res.Resources{tt.wantFile: want}, otherResources); diff != "" {
+				rt.Fatalf("rotated secret failed:\n%s", diff)
+			}
+			if diff := cmp.Diff(res.Resources{}, files); diff != "" {
+				rt.Fatalf("unexpected GitOps files:\n%s", diff)
+			}
+			if value != "new-secret" {
+				rt.Fatalf("got secret value %q, want %q", value, "new-secret")
+			}
+		})
+	}
+}
+
+func TestRotatedSecretResourcesGeneratesSecret(t *testing.T) {
+	_, otherResources, value, err := rotatedSecretResources(buildManifest(true, false), ioutils.NewMemoryFilesystem(), &RotateSecretOptions{IsCICD: true})
+	assertNoError(t, err)
+
+	if len(value) == 0 {
+		t.Fatal("no secret was generated")
+	}
+	secret := otherResources["secrets/gitops-webhook-secret.yaml"]
+	want, err := secrets.CreateUnsealedSecret(meta.NamespacedName("cicd", eventlisteners.GitOpsWebhookSecret), value, eventlisteners.WebhookSecretKey)
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, secret); diff != "" {
+		t.Fatalf("rotated secret failed:\n%s", diff)
+	}
+}
+
+func TestRotatedSecretResourcesWithSealedSecrets(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	cert, err := ioutil.ReadFile("testdata/sealed-secrets-cert.pem")
+	assertNoError(t, err)
+	assertNoError(t, fakeFs.WriteFile("/tmp/cert.pem", cert, 0644))
+	m := buildManifest(true, false)
+	m.Config.Secrets = &config.SecretsConfig{Mode: config.SecretsModeSealed}
+
+	files, otherResources, _, err := rotatedSecretResources(m, fakeFs, &RotateSecretOptions{
+		EnvName: "test-dev", ServiceName: "test-svc", SealedSecretsCert: "/tmp/cert.pem"})
+	assertNoError(t, err)
+
+	if diff := cmp.Diff(res.Resources{}, otherResources); diff != "" {
+		t.Fatalf("unsealed secrets were generated:\n%s", diff)
+	}
+	if _, ok := files["config/cicd/base/09-secrets/webhook-secret-test-dev-test-svc.yaml"].(*secrets.SealedSecret); !ok {
+		t.Fatalf("failed to find the sealed secret in %#v", files)
+	}
+}
+
+func TestRotatedSecretResourcesWithRecordedSealedSecretsCert(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	cert, err := ioutil.ReadFile("testdata/sealed-secrets-cert.pem")
+	assertNoError(t, err)
+	assertNoError(t, fakeFs.WriteFile("/tmp/cert.pem", cert, 0644))
+	m := buildManifest(true, false)
+	m.Config.Secrets = &config.SecretsConfig{Mode: config.SecretsModeSealed, SealedSecretsCert: "/tmp/cert.pem"}
+
+	files, _, _, err := rotatedSecretResources(m, fakeFs, &RotateSecretOptions{
+		EnvName: "test-dev", ServiceName: "test-svc"})
+	assertNoError(t, err)
+
+	if _, ok := files["config/cicd/base/09-secrets/webhook-secret-test-dev-test-svc.yaml"].(*secrets.SealedSecret); !ok {
+		t.Fatalf("failed to find the sealed secret in %#v", files)
+	}
+}
+
+func TestRotatedSecretResourcesWithExternalSecrets(t *testing.T) {
+	m := buildManifest(true, false)
+	m.Config.Secrets = &config.SecretsConfig{
+		Mode:  config.SecretsModeExternal,
+		Store: &config.SecretStoreRef{Name: "vault-backend"},
+	}
+
+	_, _, _, err := rotatedSecretResources(m, ioutils.NewMemoryFilesystem(), &RotateSecretOptions{IsCICD: true})
+	test.AssertErrorMatch(t, "must be provided when the secrets mode is external", err)
+
+	files, otherResources, value, err := rotatedSecretResources(m, ioutils.NewMemoryFilesystem(), &RotateSecretOptions{IsCICD: true, WebhookSecret: "new-secret"})
+	assertNoError(t, err)
+	if len(files) != 0 || len(otherResources) != 0 {
+		t.Fatalf("no files should be rewritten for external secrets, got %#v and %#v", files, otherResources)
+	}
+	if value != "new-secret" {
+		t.Fatalf("got secret value %q, want %q", value, "new-secret")
+	}
+}
+
+func TestRotatedSecretResourcesWithUnknownService(t *testing.T) {
+	_, _, _, err := rotatedSecretResources(buildManifest(true, false), ioutils.NewMemoryFilesystem(), &RotateSecretOptions{EnvName: "test-dev", ServiceName: "unknown"})
+	test.AssertErrorMatch(t, "service unknown does not exist in environment test-dev", err)
+}
+
+func TestRotateWebhookSecretWritesOnlyOnWrite(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := afero.GetTempDir(fakeFs, "test")
+	b, err := yaml.Marshal(buildManifest(true, false))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(outputPath, pipelinesFile), b, 0644))
+	secretPath := filepath.Join(outputPath, "..", "secrets", "gitops-webhook-secret.yaml")
+
+	rotated, err := RotateWebhookSecret(&RotateSecretOptions{PipelinesFolderPath: outputPath, IsCICD: true, WebhookSecret: "new-secret"}, fakeFs)
+	assertNoError(t, err)
+	if rotated.Value != "new-secret" {
+		t.Fatalf("got secret %q, want %q", rotated.Value, "new-secret")
+	}
+	if exists, _ := afero.Exists(fakeFs, secretPath); exists {
+		t.Fatal("the rotated secret was written before Write")
+	}
+
+	assertNoError(t, rotated.Write(fakeFs))
+	if exists, _ := afero.Exists(fakeFs, secretPath); !exists {
+		t.Fatal("the rotated secret was not written")
+	}
+}
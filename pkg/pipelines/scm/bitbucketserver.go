@@ -0,0 +1,153 @@
+package scm
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const (
+	bitbucketServerPushEventFilters        = "(header.match('X-Event-Key', 'repo:refs_changed') && body.repository.project.key + '/' + body.repository.slug == '%s')"
+	bitbucketServerPullRequestEventFilters = "((header.match('X-Event-Key', 'pr:opened') || header.match('X-Event-Key', 'pr:from_ref_updated')) && body.pullRequest.toRef.repository.project.key + '/' + body.pullRequest.toRef.repository.slug == '%s' && body.pullRequest.fromRef.repository.id == body.pullRequest.toRef.repository.id)"
+	bitbucketServerType                    = "bitbucketserver"
+	// go-scm identifies Bitbucket Server as "stash".
+	stashType = "stash"
+	// bitbucketServerPushRef is the full ref of the first change in a push.
+	bitbucketServerPushRef = "body.changes[0].ref.id"
+)
+
+var (
+	bitbucketServerRefOverlay = refOverlays(bitbucketServerPushRef)
+)
+
+type bitbucketServerSpec struct {
+	pushBinding        string
+	pullRequestBinding string
+}
+
+func init() {
+	gits[bitbucketServerType] = newBitbucketServer
+	gits[stashType] = newBitbucketServer
+}
+
+func newBitbucketServer(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, processBitbucketServerPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &bitbucketServerSpec{pushBinding: "bitbucketserver-push-binding", pullRequestBinding: "bitbucketserver-pull-request-binding"}}, nil
+}
+
+// processBitbucketServerPath returns the path as <PROJECT>/<repo> from either
+// a clone URL e.g. https://example.com/scm/proj/repo.git or a browse URL e.g.
+// https://example.com/projects/PROJ/repos/repo/browse.
+//
+// Bitbucket Server project keys are always upper-case and repository slugs are
+// always lower-case, this is normalised to match the hook payloads.
+func processBitbucketServerPath(parsedURL *url.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	for i, c := range components {
+		switch {
+		case c == "scm" && len(components) == i+3:
+			return bitbucketServerPath(components[i+1], components[i+2]), nil
+		case c == "projects" && len(components) > i+3 && components[i+2] == "repos":
+			return bitbucketServerPath(components[i+1], strings.TrimSuffix(components[i+3], ".git")), nil
+		}
+	}
+	return "", invalidRepoPathError(bitbucketServerType, parsedURL.Path)
+}
+
+func bitbucketServerPath(project, slug string) string {
+	return strings.ToUpper(project) + "/" + strings.ToLower(slug)
+}
+
+func (r *bitbucketServerSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+// The refs_changed payload doesn't include the commits, so there is no commit
+// message available.
+func (r *bitbucketServerSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", `$(body.repository.links.clone[?(@.name=="http")].href)`),
+		createBindingParam("fullname", "$(body.repository.project.key)/$(body.repository.slug)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitRefType, "$(extensions.ref_type)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+		createBindingParam(triggers.GitCommitID, "$(body.changes[0].toHash)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.date)"),
+		createBindingParam(triggers.GitCommitMessage, ""),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.actor.name)"),
+	}
+}
+
+func (r *bitbucketServerSpec) pushEventFilters() string {
+	return bitbucketServerPushEventFilters
+}
+
+// Bitbucket Server push events don't include the changed files, so events
+// can't be filtered by path.
+func (r *bitbucketServerSpec) pushPathFilter(path string) string {
+	return ""
+}
+
+func (r *bitbucketServerSpec) pushRef() string {
+	return bitbucketServerPushRef
+}
+
+// Bitbucket Server push events don't include the default branch of the
+// repository.
+func (r *bitbucketServerSpec) pushDefaultBranch() string {
+	return ""
+}
+
+func (r *bitbucketServerSpec) pushEventOverlays() []triggersv1.CELOverlay {
+	return bitbucketServerRefOverlay
+}
+
+func (r *bitbucketServerSpec) pullRequestBindingName() string {
+	return r.pullRequestBinding
+}
+
+func (r *bitbucketServerSpec) pullRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", `$(body.pullRequest.fromRef.repository.links.clone[?(@.name=="http")].href)`),
+		createBindingParam("fullname", "$(body.pullRequest.toRef.repository.project.key)/$(body.pullRequest.toRef.repository.slug)"),
+		createBindingParam(triggers.GitRef, "$(body.pullRequest.fromRef.displayId)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pullRequest.fromRef.latestCommit)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.date)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pullRequest.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pullRequest.author.user.name)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.pullRequest.id)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+	}
+}
+
+func (r *bitbucketServerSpec) pullRequestEventFilters() string {
+	return bitbucketServerPullRequestEventFilters
+}
+
+func (r *bitbucketServerSpec) pullRequestTargetBranch() string {
+	return "body.pullRequest.toRef.displayId"
+}
+
+func (r *bitbucketServerSpec) pullRequestEventOverlays() []triggersv1.CELOverlay {
+	return imageTagOverlay("body.pullRequest.fromRef.displayId")
+}
+
+// The Bitbucket interceptor validates the X-Hub-Signature HMAC of the payload.
+func (r *bitbucketServerSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
+	return &triggersv1.EventInterceptor{
+		Bitbucket: &triggersv1.BitbucketInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: secretName,
+				SecretKey:  webhookSecretKey,
+			},
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package scm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForBitbucketServer(t *testing.T) {
+	repo, err := newBitbucketServer("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "bitbucketserver-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{
+					Name:  "gitrepositoryurl",
+					Value: `$(body.repository.links.clone[?(@.name=="http")].href)`,
+				},
+				{
+					Name:  "fullname",
+					Value: "$(body.repository.project.key)/$(body.repository.slug)",
+				},
+				{
+					Name:  triggers.GitRef,
+					Value: "$(extensions.ref)",
+				},
+				{
+					Name:  triggers.GitRefType,
+					Value: "$(extensions.ref_type)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
+				{
+					Name:  triggers.GitCommitID,
+					Value: "$(body.changes[0].toHash)",
+				},
+				{
+					Name:  triggers.GitCommitDate,
+					Value: "$(body.date)",
+				},
+				{
+					Name:  triggers.GitCommitMessage,
+					Value: "",
+				},
+				{
+					Name:  triggers.GitCommitAuthor,
+					Value: "$(body.actor.name)",
+				},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "bitbucketserver-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "bitbucketserver-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushTriggerForBitbucketServer(t *testing.T) {
+	repo, err := newBitbucketServer("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Bitbucket: &triggersv1.BitbucketInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(bitbucketServerPushEventFilters, "PROJ/test"),
+					Overlays: bitbucketServerRefOverlay,
+				},
+			},
+		},
+	}
+	got := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushTriggerForPathForBitbucketServer(t *testing.T) {
+	repo, err := newBitbucketServer("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+
+	want := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	got := repo.CreatePushTriggerForPath("test", "secret", "ns", "test-template", []string{"test-binding"}, "services/api")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTriggerForPath() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePullRequestTriggerForBitbucketServer(t *testing.T) {
+	repo, err := newBitbucketServer("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "bitbucketserver-pull-request-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Bitbucket: &triggersv1.BitbucketInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(bitbucketServerPullRequestEventFilters, "PROJ/test"),
+					Overlays: imageTagOverlay("body.pullRequest.fromRef.displayId"),
+				},
+			},
+		},
+	}
+	got := repo.CreatePullRequestTrigger("test", "secret", "ns", "test-template", []string{repo.PullRequestBindingName()})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePullRequestTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestNewBitbucketServerRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://bitbucket.example.com/scm/proj/test.git",
+			"PROJ/test",
+			"",
+		},
+		{
+			"https://bitbucket.example.com/projects/PROJ/repos/test/browse",
+			"PROJ/test",
+			"",
+		},
+		{
+			"https://example.com/bitbucket/scm/proj/test.git",
+			"PROJ/test",
+			"",
+		},
+		{
+			"https://bitbucket.example.com/proj/test.git",
+			"",
+			"invalid repository path for bitbucketserver: /proj/test.git",
+		},
+		{
+			"https://bitbucket.example.com/",
+			"",
+			"invalid repository URL https://bitbucket.example.com/: path is empty",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := newBitbucketServer(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateFilteredPushTriggerForDefaultBranchForBitbucketServer(t *testing.T) {
+	repo, err := newBitbucketServer("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	defaultBranch := true
+	got := repo.CreateFilteredPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, PushFilter{DefaultBranch: &defaultBranch})
+	want := fmt.Sprintf(bitbucketServerPushEventFilters, "PROJ/test") + " && body.changes[0].ref.id.matches(r'^refs/heads/(?:main|master)$')"
+	if filter := got.Interceptors[len(got.Interceptors)-1].CEL.Filter; filter != want {
+		t.Fatalf("CreateFilteredPushTrigger() got filter %s, want %s", filter, want)
+	}
+}
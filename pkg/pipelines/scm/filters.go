@@ -0,0 +1,135 @@
+package scm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pushRefBody is the full ref e.g. refs/heads/main in the push events of
+// GitHub, GitLab and Gitea.
+const pushRefBody = "body.ref"
+
+// PushFilter filters the push events that trigger a build.
+type PushFilter struct {
+	// Path is the directory within the repository that the pushed commits
+	// must change files in.
+	Path string
+	// Branches are glob patterns of the branches that are built, if empty, all
+	// branches are built.
+	Branches []string
+	// ExcludeBranches are glob patterns of the branches that are not built,
+	// these take precedence over the Branches.
+	ExcludeBranches []string
+	// Tags is a glob pattern of the tags that are built, if empty, tags are
+	// only built when there are no filters for branches.
+	Tags string
+	// DefaultBranch, if not nil, matches the pushes to the default branch of
+	// the repository when true, and the pushes to any other ref when false.
+	DefaultBranch *bool
+}
+
+func (f PushFilter) filtersRefs() bool {
+	return len(f.Branches) > 0 || len(f.ExcludeBranches) > 0 || f.Tags != ""
+}
+
+// PullRequestFilter filters the pull request events that trigger a build.
+type PullRequestFilter struct {
+	// Branches are glob patterns of the target branches of the pull requests
+	// that are built, if empty, pull requests to all branches are built.
+	Branches []string
+	// ExcludeBranches are glob patterns of the target branches of the pull
+	// requests that are not built, these take precedence over the Branches.
+	ExcludeBranches []string
+}
+
+// pullRequestBranchFilter returns a CEL filter that matches the target branch
+// of a pull request against the patterns of the filter, if there are no
+// patterns, this returns "".
+func pullRequestBranchFilter(branch string, f PullRequestFilter) string {
+	filters := []string{}
+	if len(f.Branches) > 0 {
+		filters = append(filters, refMatches(branch, "", f.Branches))
+	}
+	if len(f.ExcludeBranches) > 0 {
+		filters = append(filters, "!"+refMatches(branch, "", f.ExcludeBranches))
+	}
+	return strings.Join(filters, " && ")
+}
+
+// pushRefFilter returns a CEL filter that matches the full ref of a push
+// against the patterns of the filter, if there are no patterns, this returns
+// "".
+func pushRefFilter(ref string, f PushFilter) string {
+	if !f.filtersRefs() {
+		return ""
+	}
+	branches := fmt.Sprintf("%s.startsWith('refs/heads/')", ref)
+	if len(f.Branches) > 0 {
+		branches = refMatches(ref, "refs/heads/", f.Branches)
+	}
+	if len(f.ExcludeBranches) > 0 {
+		branches = fmt.Sprintf("%s && !%s", branches, refMatches(ref, "refs/heads/", f.ExcludeBranches))
+	}
+	if f.Tags == "" {
+		return "(" + branches + ")"
+	}
+	return fmt.Sprintf("((%s) || %s)", branches, refMatches(ref, "refs/tags/", []string{f.Tags}))
+}
+
+// defaultBranchFilter returns a CEL filter that matches a push to the default
+// branch of the repository, if the push events don't include the default
+// branch, the "main" and "master" branches are matched.
+func defaultBranchFilter(ref, defaultBranch string) string {
+	if defaultBranch == "" {
+		return refMatches(ref, "refs/heads/", []string{"main", "master"})
+	}
+	return fmt.Sprintf("%s == 'refs/heads/' + %s", ref, defaultBranch)
+}
+
+// refMatches returns a CEL expression that matches the ref against any of
+// the glob patterns, relative to the prefix.
+func refMatches(ref, prefix string, patterns []string) string {
+	alternatives := make([]string, len(patterns))
+	for i, p := range patterns {
+		alternatives[i] = globToRegexp(p)
+	}
+	return fmt.Sprintf("%s.matches(r'^%s(?:%s)$')", ref, regexp.QuoteMeta(prefix), strings.Join(alternatives, "|"))
+}
+
+// globToRegexp converts a glob pattern for a ref to a regular expression.
+//
+// "*" matches any characters except "/", "**" matches any characters, "?"
+// matches a single character except "/", and "[...]" matches a character
+// class, which is negated with a leading "!".
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(glob[i:]))
+				return sb.String()
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package scm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	globTests := []struct {
+		glob  string
+		name  string
+		match bool
+	}{
+		{"main", "main", true},
+		{"main", "maintenance", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"release/**", "release/1.0/hotfix", true},
+		{"feature-?", "feature-a", true},
+		{"feature-?", "feature-ab", false},
+		{"v[0-9].*", "v1.0", true},
+		{"v[0-9].*", "va.0", false},
+		{"v[!0-9]*", "va", true},
+		{"v[!0-9]*", "v1", false},
+		{"v1.0", "v1x0", false},
+		{"fix+1", "fix+1", true},
+	}
+
+	for _, tt := range globTests {
+		t.Run(fmt.Sprintf("%s %s", tt.glob, tt.name), func(rt *testing.T) {
+			r := regexp.MustCompile("^(?:" + globToRegexp(tt.glob) + ")$")
+			if m := r.MatchString(tt.name); m != tt.match {
+				rt.Fatalf("globToRegexp(%q) matching %q got %v, want %v", tt.glob, tt.name, m, tt.match)
+			}
+		})
+	}
+}
+
+func TestPushRefFilter(t *testing.T) {
+	filterTests := []struct {
+		desc   string
+		filter PushFilter
+		want   string
+	}{
+		{
+			"no ref filters",
+			PushFilter{Path: "services/api"},
+			"",
+		},
+		{
+			"included branches",
+			PushFilter{Branches: []string{"main", "release/*"}},
+			`(body.ref.matches(r'^refs/heads/(?:main|release/[^/]*)$'))`,
+		},
+		{
+			"excluded branches",
+			PushFilter{ExcludeBranches: []string{"dependabot/**"}},
+			`(body.ref.startsWith('refs/heads/') && !body.ref.matches(r'^refs/heads/(?:dependabot/.*)$'))`,
+		},
+		{
+			"branches and tags",
+			PushFilter{Branches: []string{"main"}, ExcludeBranches: []string{"main-old"}, Tags: "v*"},
+			`((body.ref.matches(r'^refs/heads/(?:main)$') && !body.ref.matches(r'^refs/heads/(?:main-old)$')) || body.ref.matches(r'^refs/tags/(?:v[^/]*)$'))`,
+		},
+		{
+			"only tags",
+			PushFilter{Tags: "v*"},
+			`((body.ref.startsWith('refs/heads/')) || body.ref.matches(r'^refs/tags/(?:v[^/]*)$'))`,
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if got := pushRefFilter("body.ref", tt.filter); got != tt.want {
+				rt.Fatalf("pushRefFilter() got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullRequestBranchFilter(t *testing.T) {
+	filterTests := []struct {
+		desc   string
+		filter PullRequestFilter
+		want   string
+	}{
+		{
+			"no branch filters",
+			PullRequestFilter{},
+			"",
+		},
+		{
+			"included branches",
+			PullRequestFilter{Branches: []string{"main", "release/*"}},
+			`body.pull_request.base.ref.matches(r'^(?:main|release/[^/]*)$')`,
+		},
+		{
+			"included and excluded branches",
+			PullRequestFilter{Branches: []string{"release/**"}, ExcludeBranches: []string{"release/old"}},
+			`body.pull_request.base.ref.matches(r'^(?:release/.*)$') && !body.pull_request.base.ref.matches(r'^(?:release/old)$')`,
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if got := pullRequestBranchFilter("body.pull_request.base.ref", tt.filter); got != tt.want {
+				rt.Fatalf("pullRequestBranchFilter() got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package scm
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const (
+	// giteaSignatureFilter requires the X-Gitea-Signature HMAC of the payload
+	// to be the HMAC that the GitHub interceptor validated, Gitea computes
+	// both with the hook secret.
+	giteaSignatureFilter         = "header.canonical('X-Hub-Signature-256') == 'sha256=' + header.canonical('X-Gitea-Signature')"
+	giteaPushEventFilters        = "(header.match('X-Gitea-Event', 'push') && " + giteaSignatureFilter + " && body.repository.full_name == '%s')"
+	giteaPullRequestEventFilters = "(header.match('X-Gitea-Event', 'pull_request') && " + giteaSignatureFilter + " && body.action in ['opened', 'synchronized'] && body.repository.full_name == '%s' && body.pull_request.head.repo.full_name == body.repository.full_name)"
+	giteaType                    = "gitea"
+)
+
+type giteaSpec struct {
+	pushBinding        string
+	pullRequestBinding string
+}
+
+func init() {
+	gits[giteaType] = newGitea
+}
+
+func newGitea(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, processGiteaPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &giteaSpec{pushBinding: "gitea-push-binding", pullRequestBinding: "gitea-pull-request-binding"}}, nil
+}
+
+// processGiteaPath returns the <owner>/<repo> path of the repository, Gitea
+// can be hosted with a sub-path, which is the prefix of the path.
+func processGiteaPath(parsedURL *url.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	if len(components) < 2 {
+		return "", invalidRepoPathError(giteaType, parsedURL.Path)
+	}
+	return strings.Join(components[len(components)-2:], "/"), nil
+}
+
+func (r *giteaSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+func (r *giteaSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.repository.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitRefType, "$(extensions.ref_type)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+		createBindingParam(triggers.GitCommitID, "$(body.after)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.head_commit.timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.head_commit.message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.head_commit.author.name)"),
+	}
+}
+
+func (r *giteaSpec) pushEventFilters() string {
+	return giteaPushEventFilters
+}
+
+func (r *giteaSpec) pushPathFilter(path string) string {
+	return changedFilesPathFilter(path)
+}
+
+func (r *giteaSpec) pushRef() string {
+	return pushRefBody
+}
+
+func (r *giteaSpec) pushDefaultBranch() string {
+	return "body.repository.default_branch"
+}
+
+func (r *giteaSpec) pushEventOverlays() []triggersv1.CELOverlay {
+	return branchRefOverlay
+}
+
+func (r *giteaSpec) pullRequestBindingName() string {
+	return r.pullRequestBinding
+}
+
+func (r *giteaSpec) pullRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pull_request.head.repo.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pull_request.head.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pull_request.head.sha)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pull_request.updated_at)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pull_request.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pull_request.user.login)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.number)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+	}
+}
+
+func (r *giteaSpec) pullRequestEventFilters() string {
+	return giteaPullRequestEventFilters
+}
+
+func (r *giteaSpec) pullRequestTargetBranch() string {
+	return "body.pull_request.base.ref"
+}
+
+func (r *giteaSpec) pullRequestEventOverlays() []triggersv1.CELOverlay {
+	return imageTagOverlay("body.pull_request.head.ref")
+}
+
+// Tekton Triggers has no Gitea interceptor, and CEL can't compute an HMAC, so
+// the GitHub interceptor validates the HMAC-SHA256 of the payload in the
+// GitHub compatible X-Hub-Signature-256 header, and the event filters check
+// that the X-Gitea-Signature is the same HMAC.
+func (r *giteaSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
+	return &triggersv1.EventInterceptor{
+		GitHub: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: secretName,
+				SecretKey:  webhookSecretKey,
+			},
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package scm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForGitea(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitea-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{
+					Name:  "gitrepositoryurl",
+					Value: "$(body.repository.clone_url)",
+				},
+				{
+					Name:  "fullname",
+					Value: "$(body.repository.full_name)",
+				},
+				{
+					Name:  triggers.GitRef,
+					Value: "$(extensions.ref)",
+				},
+				{
+					Name:  triggers.GitRefType,
+					Value: "$(extensions.ref_type)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
+				{
+					Name:  triggers.GitCommitID,
+					Value: "$(body.after)",
+				},
+				{
+					Name:  triggers.GitCommitDate,
+					Value: "$(body.head_commit.timestamp)",
+				},
+				{
+					Name:  triggers.GitCommitMessage,
+					Value: "$(body.head_commit.message)",
+				},
+				{
+					Name:  triggers.GitCommitAuthor,
+					Value: "$(body.head_commit.author.name)",
+				},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "gitea-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "gitea-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreateTriggersForGitea(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	name := "test-template"
+	interceptor := &triggersv1.EventInterceptor{
+		GitHub: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+		},
+	}
+	triggerTests := []struct {
+		name string
+		got  triggersv1.EventListenerTrigger
+		cel  *triggersv1.CELInterceptor
+	}{
+		{
+			"push",
+			repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}),
+			&triggersv1.CELInterceptor{Filter: fmt.Sprintf(giteaPushEventFilters, "org/test"), Overlays: branchRefOverlay},
+		},
+		{
+			"pull request",
+			repo.CreatePullRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}),
+			&triggersv1.CELInterceptor{Filter: fmt.Sprintf(giteaPullRequestEventFilters, "org/test"), Overlays: imageTagOverlay("body.pull_request.head.ref")},
+		},
+	}
+
+	for _, tt := range triggerTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			want := triggersv1.EventListenerTrigger{
+				Name: "test",
+				Bindings: []*triggersv1.EventListenerBinding{
+					{Ref: "test-binding"},
+				},
+				Template:     &triggersv1.EventListenerTemplate{Ref: &name},
+				Interceptors: []*triggersv1.EventInterceptor{interceptor, {CEL: tt.cel}},
+			}
+			if diff := cmp.Diff(want, tt.got); diff != "" {
+				rt.Fatalf("trigger creation failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewGiteaRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://gitea.example.com/org/test.git",
+			"org/test",
+			"",
+		},
+		{
+			"https://example.com/gitea/org/test.git",
+			"org/test",
+			"",
+		},
+		{
+			"https://gitea.example.com/test.git",
+			"",
+			"invalid repository path for gitea: /test.git",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := newGitea(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGiteaEventFiltersCheckSignature(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	triggers := []triggersv1.EventListenerTrigger{
+		repo.CreatePushTrigger("push", "secret", "ns", "template", nil),
+		repo.CreatePullRequestTrigger("pr", "secret", "ns", "template", nil),
+	}
+	for _, tr := range triggers {
+		if filter := tr.Interceptors[1].CEL.Filter; !strings.Contains(filter, giteaSignatureFilter) {
+			t.Errorf("trigger %s does not check the X-Gitea-Signature: %s", tr.Name, filter)
+		}
+	}
+}
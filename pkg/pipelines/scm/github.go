@@ -10,11 +10,15 @@ import (
 
 const (
 	githubPushEventFilters = "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == '%s')"
-	githubType             = "github"
+	// Pull requests from forks are not built, as they could run arbitrary
+	// code with the secrets of the pipeline.
+	githubPullRequestEventFilters = "(header.match('X-GitHub-Event', 'pull_request') && body.action in ['opened', 'synchronize'] && body.repository.full_name == '%s' && body.pull_request.head.repo.full_name == body.repository.full_name)"
+	githubType                    = "github"
 )
 
 type githubSpec struct {
-	pushBinding string
+	pushBinding        string
+	pullRequestBinding string
 }
 
 func init() {
@@ -26,7 +30,7 @@ func newGitHub(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding", pullRequestBinding: "github-pull-request-binding"}}, nil
 }
 
 func proccessGitHubPath(parsedURL *url.URL) (string, error) {
@@ -51,6 +55,8 @@ func (r *githubSpec) pushBindingParams() []triggersv1.Param {
 		createBindingParam("gitrepositoryurl", "$(body.repository.clone_url)"),
 		createBindingParam("fullname", "$(body.repository.full_name)"),
 		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitRefType, "$(extensions.ref_type)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
 		createBindingParam(triggers.GitCommitID, "$(body.head_commit.id)"),
 		createBindingParam(triggers.GitCommitDate, "$(body.head_commit.timestamp)"),
 		createBindingParam(triggers.GitCommitMessage, "$(body.head_commit.message)"),
@@ -62,6 +68,52 @@ func (r *githubSpec) pushEventFilters() string {
 	return githubPushEventFilters
 }
 
+func (r *githubSpec) pushPathFilter(path string) string {
+	return changedFilesPathFilter(path)
+}
+
+func (r *githubSpec) pushRef() string {
+	return pushRefBody
+}
+
+func (r *githubSpec) pushDefaultBranch() string {
+	return "body.repository.default_branch"
+}
+
+func (r *githubSpec) pushEventOverlays() []triggersv1.CELOverlay {
+	return branchRefOverlay
+}
+
+func (r *githubSpec) pullRequestBindingName() string {
+	return r.pullRequestBinding
+}
+
+func (r *githubSpec) pullRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pull_request.head.repo.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pull_request.head.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pull_request.head.sha)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pull_request.updated_at)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pull_request.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pull_request.user.login)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.number)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+	}
+}
+
+func (r *githubSpec) pullRequestEventFilters() string {
+	return githubPullRequestEventFilters
+}
+
+func (r *githubSpec) pullRequestTargetBranch() string {
+	return "body.pull_request.base.ref"
+}
+
+func (r *githubSpec) pullRequestEventOverlays() []triggersv1.CELOverlay {
+	return imageTagOverlay("body.pull_request.head.ref")
+}
+
 func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitHub: &triggersv1.GitHubInterceptor{
@@ -33,6 +33,14 @@ func TestCreatePushBindingForGithub(t *testing.T) {
 					Name:  triggers.GitRef,
 					Value: "$(extensions.ref)",
 				},
+				{
+					Name:  triggers.GitRefType,
+					Value: "$(extensions.ref_type)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
 				{
 					Name:  triggers.GitCommitID,
 					Value: "$(body.head_commit.id)",
@@ -91,6 +99,190 @@ func TestCreateCDTriggersForGithub(t *testing.T) {
 	}
 }
 
+func TestCreatePushTriggerForPathForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitHub: &triggersv1.GitHubInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(githubPushEventFilters, "org/test") + " && body.commits.exists(c, c.added.exists(f, f.startsWith('services/api/')) || c.modified.exists(f, f.startsWith('services/api/')) || c.removed.exists(f, f.startsWith('services/api/')))",
+					Overlays: branchRefOverlay,
+				},
+			},
+		},
+	}
+	got := repo.CreatePushTriggerForPath("test", "secret", "ns", "test-template", []string{"test-binding"}, "services/api")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTriggerForPath() failed:\n%s", diff)
+	}
+}
+
+func TestCreateFilteredPushTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitHub: &triggersv1.GitHubInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(githubPushEventFilters, "org/test") + " && body.commits.exists(c, c.added.exists(f, f.startsWith('services/api/')) || c.modified.exists(f, f.startsWith('services/api/')) || c.removed.exists(f, f.startsWith('services/api/'))) && ((body.ref.matches(r'^refs/heads/(?:main)$')) || body.ref.matches(r'^refs/tags/(?:v[^/]*)$'))",
+					Overlays: branchRefOverlay,
+				},
+			},
+		},
+	}
+	got := repo.CreateFilteredPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, PushFilter{Path: "services/api", Branches: []string{"main"}, Tags: "v*"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreateFilteredPushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreateFilteredPushTriggerForDefaultBranch(t *testing.T) {
+	defaultBranch, otherRefs := true, false
+	filterTests := []struct {
+		repoURL string
+		filter  PushFilter
+		want    string
+	}{
+		{"http://github.com/org/test", PushFilter{DefaultBranch: &defaultBranch}, fmt.Sprintf(githubPushEventFilters, "org/test") + " && body.ref == 'refs/heads/' + body.repository.default_branch"},
+		{"http://github.com/org/test", PushFilter{DefaultBranch: &otherRefs}, fmt.Sprintf(githubPushEventFilters, "org/test") + " && !(body.ref == 'refs/heads/' + body.repository.default_branch)"},
+		{"https://gitlab.com/org/test", PushFilter{DefaultBranch: &defaultBranch}, fmt.Sprintf(gitlabPushEventFilters, "org/test") + " && body.ref == 'refs/heads/' + body.project.default_branch"},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.repoURL, func(rt *testing.T) {
+			repo, err := NewRepository(tt.repoURL)
+			assertNoError(rt, err)
+			got := repo.CreateFilteredPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, tt.filter)
+			if filter := got.Interceptors[len(got.Interceptors)-1].CEL.Filter; filter != tt.want {
+				rt.Fatalf("CreateFilteredPushTrigger() got filter %s, want %s", filter, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestBindingForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "github-pull-request-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{
+					Name:  "gitrepositoryurl",
+					Value: "$(body.pull_request.head.repo.clone_url)",
+				},
+				{
+					Name:  "fullname",
+					Value: "$(body.repository.full_name)",
+				},
+				{
+					Name:  triggers.GitRef,
+					Value: "$(body.pull_request.head.ref)",
+				},
+				{
+					Name:  triggers.GitCommitID,
+					Value: "$(body.pull_request.head.sha)",
+				},
+				{
+					Name:  triggers.GitCommitDate,
+					Value: "$(body.pull_request.updated_at)",
+				},
+				{
+					Name:  triggers.GitCommitMessage,
+					Value: "$(body.pull_request.title)",
+				},
+				{
+					Name:  triggers.GitCommitAuthor,
+					Value: "$(body.pull_request.user.login)",
+				},
+				{
+					Name:  triggers.PullRequestNumber,
+					Value: "$(body.number)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
+			},
+		},
+	}
+	got, name := repo.CreatePullRequestBinding("testns")
+	if name != "github-pull-request-binding" {
+		t.Fatalf("CreatePullRequestBinding() returned a wrong binding: want %v got %v", "github-pull-request-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePullRequestBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePullRequestTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitHub: &triggersv1.GitHubInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(githubPullRequestEventFilters, "org/test"),
+					Overlays: imageTagOverlay("body.pull_request.head.ref"),
+				},
+			},
+		},
+	}
+	got := repo.CreatePullRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePullRequestTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreateFilteredPullRequestTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+
+	got := repo.CreateFilteredPullRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, PullRequestFilter{Branches: []string{"main"}})
+	want := fmt.Sprintf(githubPullRequestEventFilters, "org/test") + " && body.pull_request.base.ref.matches(r'^(?:main)$')"
+	if filter := got.Interceptors[1].CEL.Filter; filter != want {
+		t.Fatalf("CreateFilteredPullRequestTrigger() got filter %s, want %s", filter, want)
+	}
+}
+
 func TestNewGitHubRepository(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -117,6 +309,11 @@ func TestNewGitHubRepository(t *testing.T) {
 			"foo/bar",
 			"",
 		},
+		{
+			"git@github.com:foo/bar.git",
+			"foo/bar",
+			"",
+		},
 		{
 			"https://githuB.com/foo/bar/test.git",
 			"",
@@ -10,11 +10,16 @@ import (
 
 const (
 	gitlabPushEventFilters = "header.match('X-Gitlab-Event','Push Hook') && body.project.path_with_namespace == '%s'"
-	gitlabType             = "gitlab"
+	// Merge requests are "updated" for changes other than pushes, oldrev is
+	// only present when new commits were pushed. Merge requests from forks
+	// are not built.
+	gitlabPullRequestEventFilters = "header.match('X-Gitlab-Event','Merge Request Hook') && (body.object_attributes.action == 'open' || (body.object_attributes.action == 'update' && has(body.object_attributes.oldrev))) && body.project.path_with_namespace == '%s' && body.object_attributes.source_project_id == body.object_attributes.target_project_id"
+	gitlabType                    = "gitlab"
 )
 
 type gitlabSpec struct {
-	pushBinding string
+	pushBinding        string
+	pullRequestBinding string
 }
 
 func init() {
@@ -26,7 +31,7 @@ func newGitLab(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding", pullRequestBinding: "gitlab-merge-request-binding"}}, nil
 }
 
 func proccessGitLabPath(parsedURL *url.URL) (string, error) {
@@ -50,6 +55,8 @@ func (r *gitlabSpec) pushBindingParams() []triggersv1.Param {
 		createBindingParam("gitrepositoryurl", "$(body.project.git_http_url)"),
 		createBindingParam("fullname", "$(body.project.path_with_namespace)"),
 		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitRefType, "$(extensions.ref_type)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
 		createBindingParam(triggers.GitCommitID, "$(body.after)"),
 		createBindingParam(triggers.GitCommitDate, "$(body.commits[-1:].timestamp)"),
 		createBindingParam(triggers.GitCommitMessage, "$(body.commits[-1:].message)"),
@@ -61,6 +68,52 @@ func (r *gitlabSpec) pushEventFilters() string {
 	return gitlabPushEventFilters
 }
 
+func (r *gitlabSpec) pushPathFilter(path string) string {
+	return changedFilesPathFilter(path)
+}
+
+func (r *gitlabSpec) pushRef() string {
+	return pushRefBody
+}
+
+func (r *gitlabSpec) pushDefaultBranch() string {
+	return "body.project.default_branch"
+}
+
+func (r *gitlabSpec) pushEventOverlays() []triggersv1.CELOverlay {
+	return branchRefOverlay
+}
+
+func (r *gitlabSpec) pullRequestBindingName() string {
+	return r.pullRequestBinding
+}
+
+func (r *gitlabSpec) pullRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.object_attributes.source.git_http_url)"),
+		createBindingParam("fullname", "$(body.project.path_with_namespace)"),
+		createBindingParam(triggers.GitRef, "$(body.object_attributes.source_branch)"),
+		createBindingParam(triggers.GitCommitID, "$(body.object_attributes.last_commit.id)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.object_attributes.last_commit.timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.object_attributes.last_commit.message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.object_attributes.last_commit.author.name)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.object_attributes.iid)"),
+		createBindingParam(triggers.ImageTag, "$(extensions.image_tag)"),
+	}
+}
+
+func (r *gitlabSpec) pullRequestEventFilters() string {
+	return gitlabPullRequestEventFilters
+}
+
+func (r *gitlabSpec) pullRequestTargetBranch() string {
+	return "body.object_attributes.target_branch"
+}
+
+func (r *gitlabSpec) pullRequestEventOverlays() []triggersv1.CELOverlay {
+	return imageTagOverlay("body.object_attributes.source_branch")
+}
+
 func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitLab: &triggersv1.GitLabInterceptor{
@@ -33,6 +33,14 @@ func TestCreatePushBindingForGitlab(t *testing.T) {
 					Name:  triggers.GitRef,
 					Value: "$(extensions.ref)",
 				},
+				{
+					Name:  triggers.GitRefType,
+					Value: "$(extensions.ref_type)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
 				{
 					Name:  triggers.GitCommitID,
 					Value: "$(body.after)",
@@ -92,6 +100,95 @@ func TestCreateCDTriggersForGitLab(t *testing.T) {
 	}
 }
 
+func TestCreatePullRequestBindingForGitlab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitlab-merge-request-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{
+					Name:  "gitrepositoryurl",
+					Value: "$(body.object_attributes.source.git_http_url)",
+				},
+				{
+					Name:  "fullname",
+					Value: "$(body.project.path_with_namespace)",
+				},
+				{
+					Name:  triggers.GitRef,
+					Value: "$(body.object_attributes.source_branch)",
+				},
+				{
+					Name:  triggers.GitCommitID,
+					Value: "$(body.object_attributes.last_commit.id)",
+				},
+				{
+					Name:  triggers.GitCommitDate,
+					Value: "$(body.object_attributes.last_commit.timestamp)",
+				},
+				{
+					Name:  triggers.GitCommitMessage,
+					Value: "$(body.object_attributes.last_commit.message)",
+				},
+				{
+					Name:  triggers.GitCommitAuthor,
+					Value: "$(body.object_attributes.last_commit.author.name)",
+				},
+				{
+					Name:  triggers.PullRequestNumber,
+					Value: "$(body.object_attributes.iid)",
+				},
+				{
+					Name:  triggers.ImageTag,
+					Value: "$(extensions.image_tag)",
+				},
+			},
+		},
+	}
+	got, name := repo.CreatePullRequestBinding("testns")
+	if name != "gitlab-merge-request-binding" {
+		t.Fatalf("CreatePullRequestBinding() returned a wrong binding: want %v got %v", "gitlab-merge-request-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePullRequestBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePullRequestTriggerForGitlab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitLab: &triggersv1.GitLabInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter:   fmt.Sprintf(gitlabPullRequestEventFilters, "org/test"),
+					Overlays: imageTagOverlay("body.object_attributes.source_branch"),
+				},
+			},
+		},
+	}
+	got := repo.CreatePullRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePullRequestTrigger() failed:\n%s", diff)
+	}
+}
+
 func TestNewGitlabRepository(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -16,6 +16,26 @@ type Repository interface {
 	// Create an eventlistener trigger for Push event
 	CreatePushTrigger(name, secretName, secretNs, template string, bindings []string) triggersv1.EventListenerTrigger
 
+	// Create an eventlistener trigger for Push events that change files within
+	// the path in the repository
+	CreatePushTriggerForPath(name, secretName, secretNs, template string, bindings []string, path string) triggersv1.EventListenerTrigger
+
+	// Create an eventlistener trigger for Push events that match the filter
+	CreateFilteredPushTrigger(name, secretName, secretNs, template string, bindings []string, filter PushFilter) triggersv1.EventListenerTrigger
+
+	// Get Pull Request TriggerBinding name for this repository provider
+	PullRequestBindingName() string
+
+	// Create a TriggerBinding for Pull Request hooks
+	CreatePullRequestBinding(namespace string) (triggersv1.TriggerBinding, string)
+
+	// Create an eventlistener trigger for Pull Request events
+	CreatePullRequestTrigger(name, secretName, secretNs, template string, bindings []string) triggersv1.EventListenerTrigger
+
+	// Create an eventlistener trigger for Pull Request events that match the
+	// filter
+	CreateFilteredPullRequestTrigger(name, secretName, secretNs, template string, bindings []string, filter PullRequestFilter) triggersv1.EventListenerTrigger
+
 	// Git Repository URL
 	URL() string
 }
@@ -1,6 +1,8 @@
 package scm
 
 import (
+	"strings"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
@@ -23,8 +25,17 @@ type repository struct {
 type triggerSpec interface {
 	pushBindingParams() []triggersv1.Param
 	pushEventFilters() string
+	pushRef() string
+	pushDefaultBranch() string
+	pushEventOverlays() []triggersv1.CELOverlay
+	pushPathFilter(path string) string
 	eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor
 	pushBindingName() string
+	pullRequestBindingParams() []triggersv1.Param
+	pullRequestEventFilters() string
+	pullRequestEventOverlays() []triggersv1.CELOverlay
+	pullRequestTargetBranch() string
+	pullRequestBindingName() string
 }
 
 // NewRepository returns a suitable Repository instance
@@ -56,7 +67,75 @@ func (r *repository) CreatePushBinding(ns string) (triggersv1.TriggerBinding, st
 
 // CreatePushTrigger implements the Repository interface.
 func (r *repository) CreatePushTrigger(name, secretName, secretNS, template string, bindings []string) triggersv1.EventListenerTrigger {
-	return r.createTrigger(name, r.spec.pushEventFilters(),
+	return r.createTrigger(name, r.spec.pushEventFilters(), r.spec.pushEventOverlays(),
+		template, bindings,
+		r.spec.eventInterceptor(secretNS, secretName))
+}
+
+// CreatePushTriggerForPath implements the Repository interface.
+//
+// Push events are filtered on the files changed by the pushed commits, if the
+// Git hosting service doesn't report the changed files, this is the same as
+// CreatePushTrigger.
+func (r *repository) CreatePushTriggerForPath(name, secretName, secretNS, template string, bindings []string, path string) triggersv1.EventListenerTrigger {
+	return r.CreateFilteredPushTrigger(name, secretName, secretNS, template, bindings, PushFilter{Path: path})
+}
+
+// CreateFilteredPushTrigger implements the Repository interface.
+//
+// The filters for the path and the pushed ref are added to the filter for push
+// events.
+func (r *repository) CreateFilteredPushTrigger(name, secretName, secretNS, template string, bindings []string, filter PushFilter) triggersv1.EventListenerTrigger {
+	filters := []string{r.spec.pushEventFilters()}
+	if filter.Path != "" {
+		if pathFilter := r.spec.pushPathFilter(filter.Path); pathFilter != "" {
+			filters = append(filters, strings.ReplaceAll(pathFilter, "%", "%%"))
+		}
+	}
+	if refFilter := pushRefFilter(r.spec.pushRef(), filter); refFilter != "" {
+		filters = append(filters, strings.ReplaceAll(refFilter, "%", "%%"))
+	}
+	if filter.DefaultBranch != nil {
+		branchFilter := defaultBranchFilter(r.spec.pushRef(), r.spec.pushDefaultBranch())
+		if !*filter.DefaultBranch {
+			branchFilter = "!(" + branchFilter + ")"
+		}
+		filters = append(filters, strings.ReplaceAll(branchFilter, "%", "%%"))
+	}
+	return r.createTrigger(name, strings.Join(filters, " && "), r.spec.pushEventOverlays(),
+		template, bindings,
+		r.spec.eventInterceptor(secretNS, secretName))
+}
+
+// CreatePullRequestBinding implements the Repository interface.
+func (r *repository) CreatePullRequestBinding(ns string) (triggersv1.TriggerBinding, string) {
+	return triggersv1.TriggerBinding{
+		TypeMeta:   triggers.TriggerBindingTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, r.spec.pullRequestBindingName())),
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: r.spec.pullRequestBindingParams(),
+		},
+	}, r.spec.pullRequestBindingName()
+}
+
+// CreatePullRequestTrigger implements the Repository interface.
+//
+// The ref for pull requests is the source branch, which is provided directly
+// by the binding, only the image tag is provided by an overlay.
+func (r *repository) CreatePullRequestTrigger(name, secretName, secretNS, template string, bindings []string) triggersv1.EventListenerTrigger {
+	return r.CreateFilteredPullRequestTrigger(name, secretName, secretNS, template, bindings, PullRequestFilter{})
+}
+
+// CreateFilteredPullRequestTrigger implements the Repository interface.
+//
+// The filter for the target branch is added to the filter for pull request
+// events.
+func (r *repository) CreateFilteredPullRequestTrigger(name, secretName, secretNS, template string, bindings []string, filter PullRequestFilter) triggersv1.EventListenerTrigger {
+	filters := []string{r.spec.pullRequestEventFilters()}
+	if branchFilter := pullRequestBranchFilter(r.spec.pullRequestTargetBranch(), filter); branchFilter != "" {
+		filters = append(filters, strings.ReplaceAll(branchFilter, "%", "%%"))
+	}
+	return r.createTrigger(name, strings.Join(filters, " && "), r.spec.pullRequestEventOverlays(),
 		template, bindings,
 		r.spec.eventInterceptor(secretNS, secretName))
 }
@@ -71,12 +150,17 @@ func (r *repository) PushBindingName() string {
 	return r.spec.pushBindingName()
 }
 
-func (r *repository) createTrigger(name, filters, template string, bindings []string, interceptor *triggersv1.EventInterceptor) triggersv1.EventListenerTrigger {
+// PullRequestBindingName returns the name of the pull request binding.
+func (r *repository) PullRequestBindingName() string {
+	return r.spec.pullRequestBindingName()
+}
+
+func (r *repository) createTrigger(name, filters string, overlays []triggersv1.CELOverlay, template string, bindings []string, interceptor *triggersv1.EventInterceptor) triggersv1.EventListenerTrigger {
 	return triggersv1.EventListenerTrigger{
 		Name: name,
 		Interceptors: []*triggersv1.EventInterceptor{
 			interceptor,
-			createEventInterceptor(filters, r.path),
+			createEventInterceptor(filters, r.path, overlays),
 		},
 		Bindings: createBindings(bindings),
 		Template: createListenerTemplate(&template),
@@ -6,15 +6,108 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
 var (
-	branchRefOverlay = []triggersv1.CELOverlay{
-		{Key: "ref", Expression: "body.ref.split('/')[2]"},
-	}
+	branchRefOverlay = refOverlays("body.ref")
 )
 
+const (
+	// imageTagChars are the characters that are kept in an image tag, other
+	// characters are escaped.
+	imageTagChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789.-"
+
+	// refInvalidChars can't appear in a Git ref, see git-check-ref-format.
+	refInvalidChars = " ~^:?*[\\"
+
+	// maxImageTagLength is the longest image tag that can be used from a ref,
+	// the PipelineRun appends "-" and the commit SHA to the tag, and an image
+	// tag can be at most 128 characters.
+	maxImageTagLength = 128 - 41
+)
+
+// imageTagEscapes returns the replacements that escape the characters that can
+// appear in a Git ref, but not in an image tag.
+//
+// "_" is used as the escape character, "/" is replaced with "__" and other
+// characters with "_" and their hex code, so that refs such as feature/login
+// and feature-login don't result in the same image tag.
+func imageTagEscapes() [][2]string {
+	escapes := [][2]string{{"_", "_5f"}, {"/", "__"}}
+	for c := byte('!'); c <= '~'; c++ {
+		if strings.IndexByte(imageTagChars+refInvalidChars+"_/", c) >= 0 {
+			continue
+		}
+		escapes = append(escapes, [2]string{string(c), fmt.Sprintf("_%02x", c)})
+	}
+	return escapes
+}
+
+// refOverlays provides the branch or tag name from a full Git ref e.g.
+// refs/heads/feature/login, as the "ref" extension, whether the ref is a
+// branch or a tag as the "ref_type" extension, and the name sanitised for use
+// in an image tag as the "image_tag" extension.
+func refOverlays(ref string) []triggersv1.CELOverlay {
+	name := refNameExpression(ref)
+	return []triggersv1.CELOverlay{
+		{Key: "ref", Expression: name},
+		{Key: "ref_type", Expression: fmt.Sprintf("%s.startsWith('refs/tags/') ? 'tag' : 'branch'", ref)},
+		{Key: "image_tag", Expression: refImageTagExpression(name)},
+	}
+}
+
+// refNameExpression returns a CEL expression that strips the refs/heads/ or
+// refs/tags/ prefix from a full Git ref, other refs are returned unchanged.
+func refNameExpression(ref string) string {
+	return fmt.Sprintf("(%[1]s.startsWith('refs/heads/') ? %[1]s.substring(11) : %[1]s.startsWith('refs/tags/') ? %[1]s.substring(10) : %[1]s)", ref)
+}
+
+// imageTagExpression returns a CEL expression that escapes the characters
+// that aren't valid in an image tag in the result of an expression.
+func imageTagExpression(expr string) string {
+	var sb strings.Builder
+	sb.WriteString(expr)
+	for _, e := range imageTagEscapes() {
+		fmt.Fprintf(&sb, ".replace('%s', '%s')", strings.ReplaceAll(e[0], "'", "\\'"), e[1])
+	}
+	return sb.String()
+}
+
+// refImageTagExpression returns a CEL expression for the image tag of a ref,
+// the tag can't start with "-", so a leading "-" is escaped, and the tag is
+// truncated to the maximum length.
+//
+// The tag is prefixed with "/", which is always escaped, so that only a
+// leading "-" is replaced.
+func refImageTagExpression(name string) string {
+	return fmt.Sprintf("('/' + %s).replace('/-', '/_2d').substring(1).truncate(%d)", imageTagExpression(name), maxImageTagLength)
+}
+
+// imageTagOverlay provides the branch name of a pull request, sanitised for
+// use in an image tag, as the "image_tag" extension.
+//
+// The tag is prefixed with "pr-" so that images built from pull requests
+// don't replace the images built from pushes to the same branch.
+func imageTagOverlay(branch string) []triggersv1.CELOverlay {
+	return []triggersv1.CELOverlay{
+		{Key: "image_tag", Expression: fmt.Sprintf("('pr-' + %s).truncate(%d)", imageTagExpression(branch), maxImageTagLength)},
+	}
+}
+
+// changedFilesFilter matches push events where the commits add, modify or
+// remove files with a prefix, this works for Git hosting services that list
+// the changed files for each commit in the push event.
+const changedFilesFilter = "body.commits.exists(c, c.added.exists(f, f.startsWith('%[1]s')) || c.modified.exists(f, f.startsWith('%[1]s')) || c.removed.exists(f, f.startsWith('%[1]s')))"
+
+// changedFilesPathFilter returns a CEL filter that matches commits that change
+// files within the path, the path is relative to the root of the repository.
+func changedFilesPathFilter(path string) string {
+	prefix := strings.ReplaceAll(strings.Trim(path, "/"), "'", "\\'") + "/"
+	return fmt.Sprintf(changedFilesFilter, prefix)
+}
+
 func invalidRepoPathError(gitType, path string) error {
 	return fmt.Errorf("invalid repository path for %s: %s", gitType, path)
 }
@@ -27,11 +120,11 @@ func invalidRepoURLError(repoURL, reason string) error {
 	return fmt.Errorf("invalid repository URL %s: %s", repoURL, reason)
 }
 
-func createEventInterceptor(filter, repoName string) *triggersv1.EventInterceptor {
+func createEventInterceptor(filter, repoName string, overlays []triggersv1.CELOverlay) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		CEL: &triggersv1.CELInterceptor{
 			Filter:   fmt.Sprintf(filter, repoName),
-			Overlays: branchRefOverlay,
+			Overlays: overlays,
 		},
 	}
 }
@@ -64,7 +157,7 @@ func createBindingParam(name, value string) triggersv1.Param {
 }
 
 func processRawURL(rawURL string, processPath func(*url.URL) (string, error)) (string, error) {
-	parsedURL, err := url.Parse(rawURL)
+	parsedURL, err := giturl.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
@@ -99,11 +192,15 @@ func GetDriverName(rawURL string) (string, error) {
 	return factory.DefaultIdentifier.Identify(host)
 }
 
-// HostnameFromURL returns the host from a URL.
+// HostnameFromURL returns the host from a URL, SSH URLs are mapped to the host
+// without the SSH port.
 func HostnameFromURL(rawURL string) (string, error) {
-	u, err := url.Parse(rawURL)
+	u, err := giturl.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
+	if giturl.IsSSH(rawURL) {
+		return strings.ToLower(u.Hostname()), nil
+	}
 	return strings.ToLower(u.Host), nil
 }
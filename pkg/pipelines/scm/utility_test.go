@@ -1,6 +1,7 @@
 package scm
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -36,12 +37,62 @@ func TestCreateEventInterceptor(t *testing.T) {
 			Overlays: branchRefOverlay,
 		},
 	}
-	eventInterceptor := createEventInterceptor("sampleFilter %s", "sample")
+	eventInterceptor := createEventInterceptor("sampleFilter %s", "sample", branchRefOverlay)
 	if diff := cmp.Diff(validEventInterceptor, *eventInterceptor); diff != "" {
 		t.Fatalf("createEventInterceptor() failed:\n%s", diff)
 	}
 }
 
+const testImageTagEscapes = ".replace('_', '_5f').replace('/', '__').replace('!', '_21').replace('\"', '_22').replace('#', '_23')" +
+	".replace('$', '_24').replace('%', '_25').replace('&', '_26').replace('\\'', '_27').replace('(', '_28').replace(')', '_29')" +
+	".replace('+', '_2b').replace(',', '_2c').replace(';', '_3b').replace('<', '_3c').replace('=', '_3d').replace('>', '_3e')" +
+	".replace('@', '_40').replace(']', '_5d').replace('`', '_60').replace('{', '_7b').replace('|', '_7c').replace('}', '_7d')"
+
+func TestRefOverlays(t *testing.T) {
+	want := []triggersv1.CELOverlay{
+		{Key: "ref", Expression: "(body.ref.startsWith('refs/heads/') ? body.ref.substring(11) : body.ref.startsWith('refs/tags/') ? body.ref.substring(10) : body.ref)"},
+		{Key: "ref_type", Expression: "body.ref.startsWith('refs/tags/') ? 'tag' : 'branch'"},
+		{Key: "image_tag", Expression: "('/' + (body.ref.startsWith('refs/heads/') ? body.ref.substring(11) : body.ref.startsWith('refs/tags/') ? body.ref.substring(10) : body.ref)" + testImageTagEscapes + ").replace('/-', '/_2d').substring(1).truncate(87)"},
+	}
+	if diff := cmp.Diff(want, refOverlays("body.ref")); diff != "" {
+		t.Fatalf("refOverlays() failed:\n%s", diff)
+	}
+}
+
+func TestImageTagOverlay(t *testing.T) {
+	want := []triggersv1.CELOverlay{
+		{Key: "image_tag", Expression: "('pr-' + body.pull_request.head.ref" + testImageTagEscapes + ").truncate(87)"},
+	}
+	if diff := cmp.Diff(want, imageTagOverlay("body.pull_request.head.ref")); diff != "" {
+		t.Fatalf("imageTagOverlay() failed:\n%s", diff)
+	}
+}
+
+func TestImageTagEscapes(t *testing.T) {
+	escape := func(s string) string {
+		for _, e := range imageTagEscapes() {
+			s = strings.ReplaceAll(s, e[0], e[1])
+		}
+		return s
+	}
+	escapeTests := []struct {
+		name string
+		want string
+	}{
+		{"feature/login", "feature__login"},
+		{"feature-login", "feature-login"},
+		{"feature_login", "feature_5flogin"},
+		{"v1.2.3+build", "v1.2.3_2bbuild"},
+		{"fix#12@it's", "fix_2312_40it_27s"},
+	}
+
+	for _, tt := range escapeTests {
+		if got := escape(tt.name); got != tt.want {
+			t.Errorf("escaping %q got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestHostnameFromURL(t *testing.T) {
 	hostTests := []struct {
 		repoURL  string
@@ -52,6 +103,8 @@ func TestHostnameFromURL(t *testing.T) {
 		{"https://example.com/example/example.git", "example.com", ""},
 		{"https:/%/", "", "parse \"https:/%/\": invalid URL escape \"%/\""},
 		{"https://GITHUB.COM/test/test.git", "github.com", ""},
+		{"git@github.com:example/example.git", "github.com", ""},
+		{"ssh://git@GitLab.com:2222/group/example.git", "gitlab.com", ""},
 	}
 
 	for _, tt := range hostTests {
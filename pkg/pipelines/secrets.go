@@ -0,0 +1,131 @@
+package pipelines
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+)
+
+const (
+	cicdSecretsPath = "09-secrets"
+
+	// externalSecretKeysPath is where the list of keys that must be populated
+	// in the external secret store is written, alongside any raw secrets.
+	externalSecretKeysPath = "secrets/external-secret-keys.yaml"
+)
+
+// secretOutputs records the secrets that are generated according to the
+// configured secrets mode.
+type secretOutputs struct {
+	mode    string
+	sealKey secrets.PublicKeyFunc
+	cert    string
+	store   secrets.SecretStoreRef
+	keyPath string
+
+	// remoteKeys are the keys that must be populated in the external secret
+	// store.
+	remoteKeys []secrets.RemoteKey
+}
+
+// newSecretOutputs creates a secretOutputs for the secrets configuration.
+//
+// If a certificate is provided, and no mode is configured, then secrets are
+// sealed. If no certificate is provided, the one recorded in the
+// configuration is used.
+func newSecretOutputs(fs afero.Fs, cfg *config.SecretsConfig, certFilename string) (*secretOutputs, error) {
+	mode := cfg.GetMode()
+	if certFilename == "" && cfg != nil {
+		certFilename = cfg.SealedSecretsCert
+	}
+	if certFilename != "" && mode == config.SecretsModeRaw {
+		mode = config.SecretsModeSealed
+	}
+	switch mode {
+	case config.SecretsModeRaw:
+		return &secretOutputs{mode: mode}, nil
+	case config.SecretsModeSealed:
+		if certFilename == "" {
+			return nil, errors.New("a Sealed Secrets certificate is required when the secrets mode is sealed")
+		}
+		key, err := secrets.CertificatePublicKey(fs, certFilename)
+		if err != nil {
+			return nil, err
+		}
+		return &secretOutputs{mode: mode, sealKey: key, cert: certFilename}, nil
+	case config.SecretsModeExternal:
+		if cfg.Store == nil || cfg.Store.Name == "" {
+			return nil, errors.New("a secret store is required when the secrets mode is external")
+		}
+		return &secretOutputs{
+			mode:    mode,
+			store:   secrets.SecretStoreRef{Name: cfg.Store.Name, Kind: cfg.Store.Kind},
+			keyPath: cfg.KeyPath,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown secrets mode %q", mode)
+}
+
+// isRaw returns true if secrets are written outside of the GitOps repository.
+func (s *secretOutputs) isRaw() bool {
+	return s.mode == config.SecretsModeRaw
+}
+
+// add records the secret in the appropriate set of resources.
+//
+// In raw mode, the secret is written to the "secrets" folder outside of the
+// GitOps repository.
+//
+// Otherwise, the secret is sealed, or replaced with an ExternalSecret, and
+// written to the CICD base folder, and the CICD-relative filename is returned,
+// which should be added to the CICD kustomization.
+func (s *secretOutputs) add(cicdOutputs, otherOutputs res.Resources, filename string, secret *corev1.Secret) (string, error) {
+	cicdFilename := filepath.ToSlash(filepath.Join(cicdSecretsPath, filename))
+	switch s.mode {
+	case config.SecretsModeSealed:
+		sealed, err := secrets.Seal(secret, s.sealKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to seal secret %s: %w", secret.Name, err)
+		}
+		cicdOutputs[cicdFilename] = sealed
+	case config.SecretsModeExternal:
+		external := secrets.External(secret, s.store, s.keyPath)
+		s.remoteKeys = append(s.remoteKeys, external.RemoteKeys()...)
+		cicdOutputs[cicdFilename] = external
+	default:
+		otherOutputs[filepath.ToSlash(filepath.Join("secrets", filename))] = secret
+		return "", nil
+	}
+	return cicdFilename, nil
+}
+
+// config returns the secrets configuration to record in the manifest, this is
+// nil for raw secrets.
+func (s *secretOutputs) config() *config.SecretsConfig {
+	switch s.mode {
+	case config.SecretsModeSealed:
+		return &config.SecretsConfig{Mode: s.mode, SealedSecretsCert: s.cert}
+	case config.SecretsModeExternal:
+		return &config.SecretsConfig{
+			Mode:    s.mode,
+			Store:   &config.SecretStoreRef{Name: s.store.Name, Kind: s.store.Kind},
+			KeyPath: s.keyPath,
+		}
+	}
+	return nil
+}
+
+func logRemoteKeys(keys []secrets.RemoteKey) {
+	log.Info("The following keys must be populated in the external secret store:")
+	for _, k := range keys {
+		log.Progressf("  %s (property %s)", k.Key, k.Property)
+	}
+}
@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// This is a minimal implementation of the External Secrets Operator
+// ExternalSecret resource, it's implemented here to avoid pulling in the
+// operator and its dependencies.
+
+const (
+	defaultRefreshInterval = "1h"
+	defaultStoreKind       = "SecretStore"
+)
+
+var externalSecretTypeMeta = meta.TypeMeta("ExternalSecret", "external-secrets.io/v1beta1")
+
+// ExternalSecret is the K8s representation of a Secret whose values are read
+// from an external secret store.
+type ExternalSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec ExternalSecretSpec `json:"spec"`
+}
+
+// ExternalSecretSpec is the specification of an ExternalSecret.
+type ExternalSecretSpec struct {
+	RefreshInterval string               `json:"refreshInterval,omitempty"`
+	SecretStoreRef  SecretStoreRef       `json:"secretStoreRef"`
+	Target          ExternalSecretTarget `json:"target"`
+	Data            []ExternalSecretData `json:"data"`
+}
+
+// SecretStoreRef identifies the store that values are read from.
+type SecretStoreRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// ExternalSecretTarget describes the Secret that will be created.
+type ExternalSecretTarget struct {
+	Name     string                      `json:"name"`
+	Template *ExternalSecretTemplateSpec `json:"template,omitempty"`
+}
+
+// ExternalSecretTemplateSpec describes the structure of the created Secret.
+type ExternalSecretTemplateSpec struct {
+	Type     corev1.SecretType          `json:"type,omitempty"`
+	Metadata ExternalSecretTemplateMeta `json:"metadata,omitempty"`
+}
+
+// ExternalSecretTemplateMeta is the metadata applied to the created Secret.
+type ExternalSecretTemplateMeta struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// ExternalSecretData maps a key in the created Secret to a value in the store.
+type ExternalSecretData struct {
+	SecretKey string    `json:"secretKey"`
+	RemoteRef RemoteKey `json:"remoteRef"`
+}
+
+// RemoteKey identifies a value in the external secret store.
+type RemoteKey struct {
+	Key      string `json:"key"`
+	Property string `json:"property,omitempty"`
+}
+
+// External creates an ExternalSecret that will populate a Secret with the same
+// name, type and keys as the provided Secret, from values in the store.
+//
+// The values are read from the property with the same name as the key, at
+// "<keyPath>/<namespace>/<name>" in the store, the values in the provided
+// secret are discarded.
+func External(secret *corev1.Secret, store SecretStoreRef, keyPath string) *ExternalSecret {
+	if store.Kind == "" {
+		store.Kind = defaultStoreKind
+	}
+	key := path.Join(keyPath, secret.Namespace, secret.Name)
+	data := []ExternalSecretData{}
+	for _, k := range secretKeys(secret) {
+		data = append(data, ExternalSecretData{
+			SecretKey: k,
+			RemoteRef: RemoteKey{Key: key, Property: k},
+		})
+	}
+	return &ExternalSecret{
+		TypeMeta:   externalSecretTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(secret.Namespace, secret.Name)),
+		Spec: ExternalSecretSpec{
+			RefreshInterval: defaultRefreshInterval,
+			SecretStoreRef:  store,
+			Target: ExternalSecretTarget{
+				Name: secret.Name,
+				Template: &ExternalSecretTemplateSpec{
+					Type: secret.Type,
+					Metadata: ExternalSecretTemplateMeta{
+						Annotations: secret.Annotations,
+						Labels:      secret.Labels,
+					},
+				},
+			},
+			Data: data,
+		},
+	}
+}
+
+// RemoteKeys returns the keys in the store that must be populated for the
+// ExternalSecret to be created.
+func (e *ExternalSecret) RemoteKeys() []RemoteKey {
+	keys := []RemoteKey{}
+	for _, d := range e.Spec.Data {
+		keys = append(keys, d.RemoteRef)
+	}
+	return keys
+}
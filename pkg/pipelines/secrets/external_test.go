@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+func TestExternal(t *testing.T) {
+	secret := createBasicAuthSecret(meta.NamespacedName("cicd", "github-auth"), testToken,
+		meta.AddAnnotations(map[string]string{"tekton.dev/git-0": "https://github.com"}))
+
+	external := External(secret, SecretStoreRef{Name: "vault-backend"}, "kam")
+
+	want := &ExternalSecret{
+		TypeMeta: externalSecretTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "github-auth",
+			Namespace: "cicd",
+		},
+		Spec: ExternalSecretSpec{
+			RefreshInterval: "1h",
+			SecretStoreRef:  SecretStoreRef{Name: "vault-backend", Kind: "SecretStore"},
+			Target: ExternalSecretTarget{
+				Name: "github-auth",
+				Template: &ExternalSecretTemplateSpec{
+					Type: corev1.SecretTypeBasicAuth,
+					Metadata: ExternalSecretTemplateMeta{
+						Annotations: map[string]string{"tekton.dev/git-0": "https://github.com"},
+					},
+				},
+			},
+			Data: []ExternalSecretData{
+				{SecretKey: "password", RemoteRef: RemoteKey{Key: "kam/cicd/github-auth", Property: "password"}},
+				{SecretKey: "username", RemoteRef: RemoteKey{Key: "kam/cicd/github-auth", Property: "username"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, external); diff != "" {
+		t.Fatalf("External() failed:\n%s", diff)
+	}
+
+	wantKeys := []RemoteKey{
+		{Key: "kam/cicd/github-auth", Property: "password"},
+		{Key: "kam/cicd/github-auth", Property: "username"},
+	}
+	if diff := cmp.Diff(wantKeys, external.RemoteKeys()); diff != "" {
+		t.Fatalf("RemoteKeys() failed:\n%s", diff)
+	}
+}
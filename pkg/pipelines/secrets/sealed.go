@@ -0,0 +1,192 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// This is a minimal implementation of the Bitnami SealedSecret resource and
+// its "strict" scope encryption, it's implemented here to avoid pulling in the
+// controller and its dependencies.
+
+const sessionKeyBytes = 32
+
+var (
+	sealedSecretTypeMeta = meta.TypeMeta("SealedSecret", "bitnami.com/v1alpha1")
+
+	// SealedSecretsController is the default service for the Sealed Secrets
+	// controller, this is passed to the PublicKeyFunc when sealing secrets.
+	SealedSecretsController = types.NamespacedName{Namespace: "kube-system", Name: "sealed-secrets-controller"}
+)
+
+// SealedSecret is the K8s representation of a "sealed Secret" - a regular k8s
+// Secret that has been sealed (encrypted) using the controller's key.
+type SealedSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec SealedSecretSpec `json:"spec"`
+}
+
+// SealedSecretSpec is the specification of a SealedSecret.
+type SealedSecretSpec struct {
+	// Template defines the structure of the Secret that will be created from
+	// this sealed secret.
+	Template SecretTemplateSpec `json:"template,omitempty"`
+
+	// EncryptedData is the base64 encoded encrypted value for each key.
+	EncryptedData map[string]string `json:"encryptedData"`
+}
+
+// SecretTemplateSpec describes the structure a Secret should have when created
+// from a template.
+type SecretTemplateSpec struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Type corev1.SecretType `json:"type,omitempty"`
+}
+
+// CertificatePublicKey reads a PEM encoded certificate, as provided by
+// "kubeseal --fetch-cert", and returns a PublicKeyFunc that provides the
+// certificate's key, this allows secrets to be sealed without access to the
+// cluster.
+func CertificatePublicKey(fs afero.Fs, filename string) (PublicKeyFunc, error) {
+	certPath, err := homedir.Expand(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate path to file: %v", err)
+	}
+	data, err := afero.ReadFile(fs, certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sealed secrets certificate %q: %w", certPath, err)
+	}
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sealed secrets certificate %q: %w", certPath, err)
+	}
+	return func(types.NamespacedName) (*rsa.PublicKey, error) {
+		return key, nil
+	}, nil
+}
+
+// Seal encrypts the provided Secret with the public key from the key func,
+// the encrypted values can only be decrypted in the Secret's namespace and
+// with the Secret's name.
+func Seal(secret *corev1.Secret, keyFunc PublicKeyFunc) (*SealedSecret, error) {
+	key, err := keyFunc(SealedSecretsController)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the public key for sealing secrets: %w", err)
+	}
+	label := []byte(secret.Namespace + "/" + secret.Name)
+	encrypted := map[string]string{}
+	for _, k := range secretKeys(secret) {
+		// StringData takes precedence over Data, as it does in the API server.
+		value := secret.Data[k]
+		if v, ok := secret.StringData[k]; ok {
+			value = []byte(v)
+		}
+		ciphertext, err := hybridEncrypt(rand.Reader, key, value, label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal the %q key of secret %s/%s: %w", k, secret.Namespace, secret.Name, err)
+		}
+		encrypted[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return &SealedSecret{
+		TypeMeta:   sealedSecretTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(secret.Namespace, secret.Name)),
+		Spec: SealedSecretSpec{
+			Template: SecretTemplateSpec{
+				ObjectMeta: *secret.ObjectMeta.DeepCopy(),
+				Type:       secret.Type,
+			},
+			EncryptedData: encrypted,
+		},
+	}, nil
+}
+
+func parsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if block.Type == "PUBLIC KEY" {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return asRSAKey(pub)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return asRSAKey(cert.PublicKey)
+}
+
+func asRSAKey(k interface{}) (*rsa.PublicKey, error) {
+	key, ok := k.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA public key, got %T", k)
+	}
+	return key, nil
+}
+
+// hybridEncrypt encrypts the plaintext with a random AES session key, and the
+// session key with the RSA public key, this is the format the Sealed Secrets
+// controller expects.
+func hybridEncrypt(rnd io.Reader, key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aed, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, 2)
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	// The session key is only used once, so a zero nonce is safe.
+	zeroNonce := make([]byte, aed.NonceSize())
+	return aed.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+func secretKeys(s *corev1.Secret) []string {
+	keys := []string{}
+	for k := range s.Data {
+		keys = append(keys, k)
+	}
+	for k := range s.StringData {
+		if _, ok := s.Data[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
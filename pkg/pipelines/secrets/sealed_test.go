@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestSeal(t *testing.T) {
+	key := generateKey(t)
+	fs := afero.NewMemMapFs()
+	writeCertificate(t, fs, "/tmp/cert.pem", key)
+	keyFunc, err := CertificatePublicKey(fs, "/tmp/cert.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := createBasicAuthSecret(meta.NamespacedName("cicd", "github-auth"), testToken,
+		meta.AddAnnotations(map[string]string{"tekton.dev/git-0": "https://github.com"}))
+
+	sealed, err := Seal(secret, keyFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &SealedSecret{
+		TypeMeta: sealedSecretTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "github-auth",
+			Namespace: "cicd",
+		},
+		Spec: SealedSecretSpec{
+			Template: SecretTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "github-auth",
+					Namespace:   "cicd",
+					Annotations: map[string]string{"tekton.dev/git-0": "https://github.com"},
+				},
+				Type: corev1.SecretTypeBasicAuth,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, sealed, cmp.FilterPath(func(p cmp.Path) bool {
+		return p.String() == "Spec.EncryptedData"
+	}, cmp.Ignore())); diff != "" {
+		t.Fatalf("Seal() failed:\n%s", diff)
+	}
+	decrypted := map[string]string{}
+	for k, v := range sealed.Spec.EncryptedData {
+		decrypted[k] = string(decrypt(t, key, v, "cicd/github-auth"))
+	}
+	if diff := cmp.Diff(secret.StringData, decrypted); diff != "" {
+		t.Fatalf("failed to decrypt the sealed data:\n%s", diff)
+	}
+}
+
+func TestSealWithDataSecret(t *testing.T) {
+	key := generateKey(t)
+	secret, err := createOpaqueSecret(meta.NamespacedName("cicd", "webhook-secret"), testToken, "webhook-secret-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := Seal(secret, func(n types.NamespacedName) (*rsa.PublicKey, error) {
+		return &key.PublicKey, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := decrypt(t, key, sealed.Spec.EncryptedData["webhook-secret-key"], "cicd/webhook-secret")
+	if string(got) != testToken {
+		t.Fatalf("got %q, want %q", got, testToken)
+	}
+}
+
+func TestCertificatePublicKeyWithMissingFile(t *testing.T) {
+	_, err := CertificatePublicKey(afero.NewMemMapFs(), "/tmp/missing.pem")
+	test.AssertErrorMatch(t, "failed to read sealed secrets certificate.*missing.pem", err)
+}
+
+func TestCertificatePublicKeyWithInvalidCertificate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/tmp/cert.pem", []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CertificatePublicKey(fs, "/tmp/cert.pem")
+	test.AssertErrorMatch(t, "failed to parse sealed secrets certificate.*no PEM data found", err)
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writeCertificate(t *testing.T, fs afero.Fs, filename string, key *rsa.PrivateKey) {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, filename, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// decrypt reverses hybridEncrypt, as the Sealed Secrets controller would.
+func decrypt(t *testing.T, key *rsa.PrivateKey, encoded, label string) []byte {
+	t.Helper()
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:rsaLen+2], []byte(label))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aed, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := aed.Open(nil, make([]byte, aed.NonceSize()), ciphertext[rsaLen+2:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plaintext
+}
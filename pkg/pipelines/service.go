@@ -15,6 +15,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
@@ -26,14 +27,21 @@ type AddServiceOptions struct {
 	AppName             string
 	EnvName             string
 	GitRepoURL          string
+	SourcePath          string
+	BuildStrategy       string
+	BuilderImage        string
 	ImageRepo           string
 	PipelinesFolderPath string
 	ServiceName         string
 	WebhookSecret       string
+	SealedSecretsCert   string
 }
 
 // AddService is the entry-point from the CLI for adding new services.
 func AddService(o *AddServiceOptions, appFs afero.Fs) error {
+	if err := normalizeGitURLs(&o.GitRepoURL); err != nil {
+		return err
+	}
 	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
 	if err != nil {
 		return err
@@ -70,6 +78,12 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	files := res.Resources{}
 	otherResources := res.Resources{}
 	svc := createService(o.ServiceName, o.GitRepoURL)
+	if svc.SourceURL != "" {
+		svc.SourcePath = o.SourcePath
+		if o.BuildStrategy != "" || o.BuilderImage != "" {
+			svc.Build = &config.Build{Strategy: o.BuildStrategy, BuilderImage: o.BuilderImage}
+		}
+	}
 	cfg := m.GetPipelinesConfig()
 	if cfg != nil && o.WebhookSecret == "" && o.GitRepoURL != "" {
 		gitSecret, err := secrets.GenerateString(webhookSecretLength)
@@ -102,17 +116,35 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 				Namespace: cfg.Name,
 			},
 		}
-		secretFilename := filepath.ToSlash(filepath.Join("secrets", secretName+".yaml"))
-		otherResources[secretFilename] = opaqueSecret
+		secretsOut, err := newSecretOutputs(appFs, m.GetSecretsConfig(), o.SealedSecretsCert)
+		if err != nil {
+			return nil, nil, err
+		}
+		if secretsOut.mode == config.SecretsModeSealed {
+			// record the certificate so that it needn't be provided again
+			m.Config.Secrets = secretsOut.config()
+		}
+		secretFiles := res.Resources{}
+		if _, err := secretsOut.add(secretFiles, otherResources, secretName+".yaml", opaqueSecret); err != nil {
+			return nil, nil, err
+		}
+		files = res.Merge(addPrefixToResources(filepath.Join(config.PathForPipelines(cfg), "base"), secretFiles), files)
+		if len(secretsOut.remoteKeys) > 0 {
+			logRemoteKeys(secretsOut.remoteKeys)
+		}
 
 		if m.Config.Pipelines != nil {
 			// add the default pipelines if they're absent
-			if env.Pipelines == nil {
+			if env.Pipelines == nil || env.Pipelines.Integration == nil {
 				repo, err := scm.NewRepository(m.GitOpsURL)
 				if err != nil {
 					return nil, nil, err
 				}
-				env.Pipelines = defaultPipelines(repo)
+				if env.Pipelines == nil {
+					env.Pipelines = defaultPipelines(repo)
+				} else {
+					env.Pipelines.Integration = defaultPipelines(repo).Integration
+				}
 			}
 
 			// use internal registry if no input image registry is provided
@@ -148,7 +180,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	}
 
 	files[filepath.Base(filepath.Join(o.PipelinesFolderPath, pipelinesFile))] = m // Don't call filepath.ToSlash
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, o.PipelinesFolderPath, m)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -156,7 +188,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 }
 
 func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, env *config.Environment, p *AddServiceOptions) ([]string, res.Resources, string, error) {
-	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(p.ImageRepo)
+	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepoForPlatform(p.ImageRepo, m.GetPlatform())
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -221,7 +253,11 @@ func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment,
 	name := makeSvcImageBindingName(env.Name, appName, svcName)
 	filename := makeSvcImageBindingFilename(name)
 	resourceFilePath := makeImageBindingPath(cfg, filename)
-	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))}
+	binding := triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))
+	if cfg.GetTektonAPI() == config.TektonAPIV1 {
+		return name, filename, res.Resources{resourceFilePath: tekton.ConvertTriggerBinding(&binding)}
+	}
+	return name, filename, res.Resources{resourceFilePath: binding}
 }
 
 func createConfigFolder(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions) error {
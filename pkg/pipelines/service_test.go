@@ -2,7 +2,9 @@ package pipelines
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,6 +17,7 @@ import (
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
 	"github.com/spf13/afero"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,7 +49,8 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
 				Pipelines: &config.PipelinesConfig{
-					Name: "cicd",
+					Name:         "cicd",
+					CatalogTasks: map[string]string{"buildah": "0.2", "git-clone": "0.4"},
 				},
 			},
 			GitOpsURL: "http://github.com/org/test",
@@ -114,6 +118,106 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesWithExternalSecrets(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, false)
+	m.Config.Secrets = &config.SecretsConfig{
+		Mode:    config.SecretsModeExternal,
+		Store:   &config.SecretStoreRef{Name: "vault-backend"},
+		KeyPath: "kam",
+	}
+
+	got, otherResources, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+	})
+	assertNoError(t, err)
+
+	if diff := cmp.Diff(res.Resources{}, otherResources); diff != "" {
+		t.Fatalf("raw secrets were generated:\n%s", diff)
+	}
+	external, ok := got["config/cicd/base/09-secrets/webhook-secret-test-dev-test.yaml"].(*secrets.ExternalSecret)
+	if !ok {
+		t.Fatal("failed to find the external secret for the webhook")
+	}
+	want := []secrets.RemoteKey{{Key: "kam/cicd/webhook-secret-test-dev-test", Property: eventlisteners.WebhookSecretKey}}
+	if diff := cmp.Diff(want, external.RemoteKeys()); diff != "" {
+		t.Fatalf("external secret keys failed:\n%s", diff)
+	}
+}
+
+func TestServiceResourcesWithSealedSecretsModeAndNoCert(t *testing.T) {
+	m := buildManifest(true, false)
+	m.Config.Secrets = &config.SecretsConfig{Mode: config.SecretsModeSealed}
+
+	_, _, err := serviceResources(m, ioutils.NewMemoryFilesystem(), &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+	})
+	test.AssertErrorMatch(t, "a Sealed Secrets certificate is required", err)
+}
+
+func TestServiceResourcesWithSealedSecretsCert(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	cert, err := ioutil.ReadFile("testdata/sealed-secrets-cert.pem")
+	assertNoError(t, err)
+	assertNoError(t, fakeFs.WriteFile("/tmp/cert.pem", cert, 0644))
+
+	certTests := []struct {
+		name       string
+		secrets    *config.SecretsConfig
+		flagCert   string
+		wantConfig *config.SecretsConfig
+	}{
+		{
+			"certificate recorded in the manifest",
+			&config.SecretsConfig{Mode: config.SecretsModeSealed, SealedSecretsCert: "/tmp/cert.pem"},
+			"",
+			&config.SecretsConfig{Mode: config.SecretsModeSealed, SealedSecretsCert: "/tmp/cert.pem"},
+		},
+		{
+			"certificate provided is recorded in the manifest",
+			nil,
+			"/tmp/cert.pem",
+			&config.SecretsConfig{Mode: config.SecretsModeSealed, SealedSecretsCert: "/tmp/cert.pem"},
+		},
+	}
+
+	for _, tt := range certTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			m := buildManifest(true, false)
+			m.Config.Secrets = tt.secrets
+			got, otherResources, err := serviceResources(m, fakeFs, &AddServiceOptions{
+				AppName:             "test-app",
+				EnvName:             "test-dev",
+				GitRepoURL:          "http://github.com/org/test",
+				PipelinesFolderPath: pipelinesFile,
+				WebhookSecret:       "123",
+				ServiceName:         "test",
+				SealedSecretsCert:   tt.flagCert,
+			})
+			assertNoError(rt, err)
+			if diff := cmp.Diff(res.Resources{}, otherResources); diff != "" {
+				rt.Fatalf("unsealed secrets were generated:\n%s", diff)
+			}
+			if _, ok := got["config/cicd/base/09-secrets/webhook-secret-test-dev-test.yaml"].(*secrets.SealedSecret); !ok {
+				rt.Fatal("failed to find the sealed secret for the webhook")
+			}
+			if diff := cmp.Diff(tt.wantConfig, m.GetSecretsConfig()); diff != "" {
+				rt.Fatalf("secrets config failed:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestServiceResourcesWithArgoCD(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	m := buildManifest(false, true)
@@ -304,6 +408,94 @@ func TestAddServiceWithoutApp(t *testing.T) {
 	}
 }
 
+func TestAddServiceWithSourcePath(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := AddService(&AddServiceOptions{
+		AppName:             "app-http-api",
+		EnvName:             "tst-dev",
+		GitRepoURL:          "https://github.com/my-org/monorepo.git",
+		SourcePath:          "services/web",
+		ImageRepo:           "quay.io/org/web",
+		PipelinesFolderPath: "/gitops",
+		WebhookSecret:       "123",
+		ServiceName:         "web",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := &config.Service{
+		Name:       "web",
+		SourceURL:  "https://github.com/my-org/monorepo.git",
+		SourcePath: "services/web",
+		Webhook: &config.Webhook{
+			Secret: &config.Secret{Name: "webhook-secret-tst-dev-web", Namespace: "tst-cicd"},
+		},
+		Pipelines: &config.Pipelines{
+			Integration: &config.TemplateBinding{
+				Bindings: []string{"tst-dev-app-http-api-web-binding", "github-push-binding"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, m.GetEnvironment("tst-dev").Apps[0].Services[1]); diff != "" {
+		t.Fatalf("service was not added to the manifest:\n%s", diff)
+	}
+}
+
+func TestAddServiceWithSourcePathInRootServiceRepository(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := AddService(&AddServiceOptions{
+		AppName:             "app-http-api",
+		EnvName:             "tst-dev",
+		GitRepoURL:          testSvcRepo,
+		SourcePath:          "services/web",
+		ImageRepo:           "quay.io/org/web",
+		PipelinesFolderPath: "/gitops",
+		WebhookSecret:       "123",
+		ServiceName:         "web",
+	}, fakeFs)
+	wantErr := "services built from the root of a repository cannot share the repository with services built from a source path"
+	if !test.ErrorMatch(t, wantErr, err) {
+		t.Fatalf("AddService() got error %v, want %s", err, wantErr)
+	}
+}
+
+func TestAddServiceWithBuildStrategy(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	err := AddService(&AddServiceOptions{
+		AppName:             "app-http-api",
+		EnvName:             "tst-dev",
+		GitRepoURL:          "https://github.com/my-org/web.git",
+		BuildStrategy:       "s2i",
+		BuilderImage:        "registry.access.redhat.com/ubi8/nodejs-14",
+		ImageRepo:           "quay.io/org/web",
+		PipelinesFolderPath: "/gitops",
+		WebhookSecret:       "123",
+		ServiceName:         "web",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	assertNoError(t, err)
+	want := &config.Build{Strategy: "s2i", BuilderImage: "registry.access.redhat.com/ubi8/nodejs-14"}
+	if diff := cmp.Diff(want, m.GetEnvironment("tst-dev").Apps[0].Services[1].Build); diff != "" {
+		t.Fatalf("service build was not added to the manifest:\n%s", diff)
+	}
+	exists, err := afero.Exists(fakeFs, "/gitops/config/tst-cicd/base/04-pipelines/app-ci-pipeline-s2i.yaml")
+	assertNoError(t, err)
+	if !exists {
+		t.Fatal("the s2i pipeline was not written")
+	}
+	b, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml")
+	assertNoError(t, err)
+	if !strings.Contains(string(b), "04-pipelines/app-ci-pipeline-s2i.yaml") {
+		t.Fatalf("the s2i pipeline is not in the kustomization:\n%s", b)
+	}
+}
+
 func TestAddServiceFilePaths(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	outputPath := afero.GetTempDir(fakeFs, "test")
@@ -384,7 +576,8 @@ func TestServiceWithArgoCD(t *testing.T) {
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
 				Pipelines: &config.PipelinesConfig{
-					Name: "cicd",
+					Name:         "cicd",
+					CatalogTasks: map[string]string{"buildah": "0.2", "git-clone": "0.4"},
 				},
 				ArgoCD: &config.ArgoCDConfig{
 					Namespace: argocd.ArgoCDNamespace,
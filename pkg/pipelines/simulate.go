@@ -0,0 +1,124 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/simulator"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
+)
+
+// SimulateWebhookOptions provides the event to simulate.
+type SimulateWebhookOptions struct {
+	PipelinesFolderPath string
+	Event               simulator.Event
+}
+
+// SimulateWebhook is the entry-point from the CLI for simulating the delivery
+// of a webhook event to the EventListener, without a cluster.
+//
+// The triggers are built from the manifest, and the TriggerBindings and
+// TriggerTemplates that they reference are read from the CI/CD environment in
+// the GitOps repository, the result of processing the event with each trigger
+// is returned.
+func SimulateWebhook(o *SimulateWebhookOptions, appFs afero.Fs) ([]*simulator.Result, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a pipelines configuration in %s", o.PipelinesFolderPath)
+	}
+	built, err := buildEventListenerResources(m.GitOpsURL, m)
+	if err != nil {
+		return nil, err
+	}
+	el, ok := built[getEventListenerPath(config.PathForPipelines(cfg))].(*triggersv1.EventListener)
+	if !ok {
+		return nil, fmt.Errorf("failed to build the EventListener, the manifest has no GitOps repository URL")
+	}
+	bindings, templates, err := readTriggerResources(appFs, filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base"))
+	if err != nil {
+		return nil, err
+	}
+	// The TriggerTemplates for services with their own workspaces are
+	// written by the build, so they take precedence over the files.
+	for _, v := range built {
+		t, ok := v.(triggersv1.TriggerTemplate)
+		if !ok {
+			continue
+		}
+		if cfg.GetTektonAPI() == config.TektonAPIV1 {
+			converted, err := tekton.ConvertTriggerTemplate(&t)
+			if err != nil {
+				return nil, err
+			}
+			t.Spec = converted.Spec
+		}
+		templates = append(templates, &t)
+	}
+	s, err := simulator.New(bindings, templates)
+	if err != nil {
+		return nil, err
+	}
+	return s.Simulate(el, o.Event)
+}
+
+// readTriggerResources reads the TriggerBindings and TriggerTemplates in the
+// CI/CD environment.
+//
+// The params of the triggers.tekton.dev/v1beta1 resources are the same as
+// v1alpha1, so both versions are read as v1alpha1.
+func readTriggerResources(appFs afero.Fs, base string) ([]*triggersv1.TriggerBinding, []*triggersv1.TriggerTemplate, error) {
+	bindings := []*triggersv1.TriggerBinding{}
+	templates := []*triggersv1.TriggerTemplate{}
+	err := afero.Walk(appFs, base, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".yaml") {
+			return nil
+		}
+		data, err := afero.ReadFile(appFs, path)
+		if err != nil {
+			return err
+		}
+		var typeMeta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		}
+		if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if !strings.HasPrefix(typeMeta.APIVersion, triggersv1.SchemeGroupVersion.Group+"/") {
+			return nil
+		}
+		switch typeMeta.Kind {
+		case "TriggerBinding":
+			var b triggersv1.TriggerBinding
+			if err := yaml.Unmarshal(data, &b); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			bindings = append(bindings, &b)
+		case "TriggerTemplate":
+			var t triggersv1.TriggerTemplate
+			if err := yaml.Unmarshal(data, &t); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			templates = append(templates, &t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the CI/CD environment: %w", err)
+	}
+	return bindings, templates, nil
+}
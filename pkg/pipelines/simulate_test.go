@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/simulator"
+	"github.com/redhat-developer/kam/test"
+)
+
+const testPushPayload = `{
+  "ref": "refs/tags/v1.0.0",
+  "repository": {
+    "full_name": "my-org/http-api",
+    "clone_url": "https://github.com/my-org/http-api.git"
+  },
+  "head_commit": {
+    "id": "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+    "message": "Release 1.0.0",
+    "timestamp": "2021-05-10T12:00:00Z",
+    "author": {"name": "A User"}
+  }
+}`
+
+func TestSimulateWebhook(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	results, err := SimulateWebhook(&SimulateWebhookOptions{
+		PipelinesFolderPath: "/gitops",
+		Event: simulator.Event{
+			Header: http.Header{"X-Github-Event": []string{"push"}},
+			Body:   []byte(testPushPayload),
+		},
+	}, fakeFs)
+	assertNoError(t, err)
+
+	matched := []*simulator.Result{}
+	for _, r := range results {
+		if r.Matched {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) != 1 || matched[0].Trigger != "app-ci-build-from-push-http-api" {
+		t.Fatalf("got %d matched triggers, want app-ci-build-from-push-http-api: %#v", len(matched), matched)
+	}
+	var run pipelinev1.PipelineRun
+	assertNoError(t, json.Unmarshal(matched[0].Resources[0], &run))
+	want := "image-registry.openshift-image-registry.svc:5000/image/repo:v1.0.0-6113728f27ae82c7b1a177c8d03f9e96e0adf246"
+	for _, p := range run.Spec.Params {
+		if p.Name == "IMAGE" && p.Value.StringVal != want {
+			t.Fatalf("got IMAGE %s, want %s", p.Value.StringVal, want)
+		}
+	}
+}
+
+func TestSimulateWebhookWithMissingManifest(t *testing.T) {
+	fakeFs := bootstrapForRemoval(t)
+
+	_, err := SimulateWebhook(&SimulateWebhookOptions{PipelinesFolderPath: "/missing"}, fakeFs)
+	test.AssertErrorMatch(t, "failed to load manifest", err)
+}
@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/interpreter/functions"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// celEnv creates the CEL environment that the Triggers CEL interceptor
+// evaluates expressions in, with the string extensions, the "match" function
+// for headers, and the "truncate" function for strings.
+//
+// The other functions that Triggers provides are not used by the triggers
+// that are generated, and are not available.
+func celEnv() (*cel.Env, error) {
+	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
+	return cel.NewEnv(
+		ext.Strings(),
+		cel.Declarations(
+			decls.NewVar("body", mapStrDyn),
+			decls.NewVar("header", mapStrDyn),
+			decls.NewVar("extensions", mapStrDyn),
+			decls.NewVar("requestURL", decls.String),
+			decls.NewFunction("match",
+				decls.NewInstanceOverload("match_map_string_string",
+					[]*exprpb.Type{mapStrDyn, decls.String, decls.String}, decls.Bool)),
+			decls.NewFunction("truncate",
+				decls.NewInstanceOverload("truncate_string_uint",
+					[]*exprpb.Type{decls.String, decls.Int}, decls.String)),
+		))
+}
+
+// celInterceptor evaluates the filter of a CEL interceptor, and if it
+// matches, the overlays, which are returned as the extensions.
+//
+// If the filter doesn't match, this returns a reason.
+func celInterceptor(env *cel.Env, i *triggersv1.CELInterceptor, vars map[string]interface{}) (map[string]interface{}, string, error) {
+	if i.Filter != "" {
+		out, err := evaluate(env, i.Filter, vars)
+		if err != nil {
+			return nil, "", err
+		}
+		if out != types.True {
+			return nil, "the CEL filter did not match", nil
+		}
+	}
+	extensions := map[string]interface{}{}
+	for _, o := range i.Overlays {
+		out, err := evaluate(env, o.Expression, vars)
+		if err != nil {
+			return nil, "", err
+		}
+		setExtension(extensions, o.Key, nativeValue(out))
+	}
+	return extensions, "", nil
+}
+
+func evaluate(env *cel.Env, expr string, vars map[string]interface{}) (ref.Val, error) {
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile expression %q: %w", expr, issues.Err())
+	}
+	prg, err := env.Program(ast, cel.Functions(
+		&functions.Overload{
+			Operator: "match",
+			Function: matchHeader,
+		},
+		&functions.Overload{
+			Operator: "truncate",
+			Binary:   truncateString,
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a program for expression %q: %w", expr, err)
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression %q: %w", expr, err)
+	}
+	return out, nil
+}
+
+// setExtension sets the value for an overlay key, keys with "." are set in
+// nested objects.
+func setExtension(extensions map[string]interface{}, key string, v interface{}) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := extensions[p].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			extensions[p] = next
+		}
+		extensions = next
+	}
+	extensions[parts[len(parts)-1]] = v
+}
+
+// nativeValue converts a CEL value to the value that it would be decoded to
+// from JSON.
+func nativeValue(v ref.Val) interface{} {
+	switch t := v.(type) {
+	case types.Null:
+		return nil
+	case traits.Lister:
+		l := []interface{}{}
+		for it := t.Iterator(); it.HasNext() == types.True; {
+			l = append(l, nativeValue(it.Next()))
+		}
+		return l
+	case traits.Mapper:
+		m := map[string]interface{}{}
+		for it := t.Iterator(); it.HasNext() == types.True; {
+			k := it.Next()
+			m[fmt.Sprint(k.Value())] = nativeValue(t.Get(k))
+		}
+		return m
+	}
+	return v.Value()
+}
+
+func matchHeader(vals ...ref.Val) ref.Val {
+	h, err := vals[0].ConvertToNative(reflect.TypeOf(http.Header{}))
+	if err != nil {
+		return types.NewErr("failed to convert to http.Header: %v", err)
+	}
+	key, ok := vals[1].(types.String)
+	if !ok {
+		return types.ValOrErr(key, "unexpected type '%v' passed to match", vals[1].Type())
+	}
+	val, ok := vals[2].(types.String)
+	if !ok {
+		return types.ValOrErr(val, "unexpected type '%v' passed to match", vals[2].Type())
+	}
+	return types.Bool(h.(http.Header).Get(string(key)) == string(val))
+}
+
+func truncateString(lhs, rhs ref.Val) ref.Val {
+	str, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(str, "unexpected type '%v' passed to truncate", lhs.Type())
+	}
+	n, ok := rhs.(types.Int)
+	if !ok {
+		return types.ValOrErr(n, "unexpected type '%v' passed to truncate", rhs.Type())
+	}
+	if n < types.Int(len(str)) {
+		return str[:n]
+	}
+	return str
+}
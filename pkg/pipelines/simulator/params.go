@@ -0,0 +1,132 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// jsonPathExpression matches the expressions in binding values e.g.
+// $(body.head_commit.id).
+var jsonPathExpression = regexp.MustCompile(`\$\(([^)]+)\)`)
+
+// resolveParams resolves the values of the bindings params from the event,
+// params that aren't bound take the default value from the template.
+//
+// The values are resolved in the same way as Triggers, strings are inserted
+// without quotes, and other values are inserted as JSON.
+func resolveParams(bindings []triggersv1.Param, tt *triggersv1.TriggerTemplate, e Event, extensions map[string]interface{}) ([]triggersv1.Param, error) {
+	event, err := eventData(e, extensions)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, p := range tt.Spec.Params {
+		if p.Default != nil {
+			values[p.Name] = *p.Default
+		}
+	}
+	for _, b := range bindings {
+		v, err := resolveValue(b.Value, event)
+		if err != nil {
+			d, ok := values[b.Name]
+			if !ok {
+				return nil, fmt.Errorf("failed to resolve the value for param %s: %w", b.Name, err)
+			}
+			v = d
+		}
+		values[b.Name] = v
+	}
+	params := make([]triggersv1.Param, 0, len(values))
+	for k, v := range values {
+		params = append(params, triggersv1.Param{Name: k, Value: v})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params, nil
+}
+
+// eventData is the event that JSONPath expressions are evaluated against,
+// the header values are joined with ",".
+func eventData(e Event, extensions map[string]interface{}) (map[string]interface{}, error) {
+	var body interface{}
+	if len(e.Body) > 0 {
+		if err := json.Unmarshal(e.Body, &body); err != nil {
+			return nil, fmt.Errorf("failed to parse the event body: %w", err)
+		}
+	}
+	header := map[string]interface{}{}
+	for k, v := range e.Header {
+		header[k] = strings.Join(v, ",")
+	}
+	return map[string]interface{}{
+		"body":       body,
+		"header":     header,
+		"extensions": extensions,
+	}, nil
+}
+
+func resolveValue(value string, event map[string]interface{}) (string, error) {
+	var resolveErr error
+	resolved := jsonPathExpression.ReplaceAllStringFunc(value, func(expr string) string {
+		path := jsonPathExpression.FindStringSubmatch(expr)[1]
+		if strings.HasPrefix(path, "header.") {
+			path = "header." + textproto.CanonicalMIMEHeaderKey(strings.TrimPrefix(path, "header."))
+		}
+		v, err := findJSONPath(event, path)
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		return v
+	})
+	return resolved, resolveErr
+}
+
+func findJSONPath(event map[string]interface{}, path string) (string, error) {
+	j := jsonpath.New("").AllowMissingKeys(false)
+	if err := j.Parse("{." + strings.TrimPrefix(path, ".") + "}"); err != nil {
+		return "", err
+	}
+	results, err := j.FindResults(event)
+	if err != nil {
+		return "", err
+	}
+	values := []interface{}{}
+	for _, r := range results {
+		for _, v := range r {
+			values = append(values, v.Interface())
+		}
+	}
+	if len(values) == 1 {
+		if s, ok := values[0].(string); ok {
+			return s, nil
+		}
+		b, err := json.Marshal(values[0])
+		return string(b), err
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+// renderResources replaces the params in the resource templates of a
+// TriggerTemplate with their values.
+//
+// The $(uid) is generated for each event by the EventListener, and is not
+// replaced.
+func renderResources(tt *triggersv1.TriggerTemplate, params []triggersv1.Param) []json.RawMessage {
+	resources := make([]json.RawMessage, len(tt.Spec.ResourceTemplates))
+	for i, rt := range tt.Spec.ResourceTemplates {
+		raw := rt.Raw
+		for _, p := range params {
+			raw = bytes.ReplaceAll(raw, []byte(fmt.Sprintf("$(tt.params.%s)", p.Name)), []byte(p.Value))
+		}
+		resources[i] = raw
+	}
+	return resources
+}
@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/cel-go/cel"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+// The headers that the Git hosting services send the type of the event in.
+const (
+	gitHubEventHeader    = "X-GitHub-Event"
+	gitLabEventHeader    = "X-GitLab-Event"
+	bitbucketEventHeader = "X-Event-Key"
+)
+
+// Event is a webhook event that is delivered to an EventListener.
+type Event struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Result is the outcome of processing an event with a trigger.
+type Result struct {
+	Trigger string `json:"trigger"`
+	// Matched is true if the event passed all the interceptors of the
+	// trigger.
+	Matched bool `json:"matched"`
+	// Reason is why the event didn't match the trigger.
+	Reason     string                 `json:"reason,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	// Params are the params that are passed to the TriggerTemplate, sorted by
+	// name.
+	Params []triggersv1.Param `json:"params,omitempty"`
+	// Resources are the resources rendered from the TriggerTemplate e.g. the
+	// PipelineRun.
+	Resources []json.RawMessage `json:"resources,omitempty"`
+}
+
+// Simulator processes events in the same way as an EventListener, without
+// creating the resources.
+//
+// The webhook secrets are not available offline, so the signatures of the
+// events are not validated.
+type Simulator struct {
+	env       *cel.Env
+	bindings  map[string]*triggersv1.TriggerBinding
+	templates map[string]*triggersv1.TriggerTemplate
+}
+
+// New creates a Simulator that resolves the bindings and templates that are
+// referenced by triggers from the provided resources.
+func New(bindings []*triggersv1.TriggerBinding, templates []*triggersv1.TriggerTemplate) (*Simulator, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CEL environment: %w", err)
+	}
+	s := &Simulator{
+		env:       env,
+		bindings:  map[string]*triggersv1.TriggerBinding{},
+		templates: map[string]*triggersv1.TriggerTemplate{},
+	}
+	for _, b := range bindings {
+		s.bindings[b.Name] = b
+	}
+	for _, t := range templates {
+		s.templates[t.Name] = t
+	}
+	return s, nil
+}
+
+// Simulate processes the event with each of the triggers of the
+// EventListener, and returns the results in the order of the triggers.
+func (s *Simulator) Simulate(el *triggersv1.EventListener, e Event) ([]*Result, error) {
+	results := []*Result{}
+	for _, t := range el.Spec.Triggers {
+		r, err := s.process(t, e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process trigger %s: %w", t.Name, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (s *Simulator) process(t triggersv1.EventListenerTrigger, e Event) (*Result, error) {
+	r := &Result{Trigger: t.Name}
+	vars, err := celVars(e)
+	if err != nil {
+		return nil, err
+	}
+	extensions := map[string]interface{}{}
+	for _, i := range t.Interceptors {
+		var reason string
+		switch {
+		case i.GitHub != nil:
+			reason = eventTypeReason(e.Header.Get(gitHubEventHeader), i.GitHub.EventTypes)
+		case i.GitLab != nil:
+			reason = eventTypeReason(e.Header.Get(gitLabEventHeader), i.GitLab.EventTypes)
+		case i.Bitbucket != nil:
+			reason = eventTypeReason(e.Header.Get(bitbucketEventHeader), i.Bitbucket.EventTypes)
+		case i.CEL != nil:
+			var overlays map[string]interface{}
+			overlays, reason, err = celInterceptor(s.env, i.CEL, vars)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range overlays {
+				extensions[k] = v
+			}
+			vars["extensions"] = extensions
+		default:
+			return nil, fmt.Errorf("interceptors of this type can't be simulated")
+		}
+		if reason != "" {
+			r.Reason = reason
+			return r, nil
+		}
+	}
+	r.Matched = true
+	r.Extensions = extensions
+
+	bindings, err := s.bindingParams(t.Bindings)
+	if err != nil {
+		return nil, err
+	}
+	tt, err := s.template(t.Template)
+	if err != nil {
+		return nil, err
+	}
+	r.Params, err = resolveParams(bindings, tt, e, extensions)
+	if err != nil {
+		return nil, err
+	}
+	r.Resources = renderResources(tt, r.Params)
+	return r, nil
+}
+
+// bindingParams returns the params of the bindings of a trigger, in order.
+func (s *Simulator) bindingParams(bindings []*triggersv1.EventListenerBinding) ([]triggersv1.Param, error) {
+	params := []triggersv1.Param{}
+	for _, b := range bindings {
+		switch {
+		case b.Name != "" && b.Value != nil:
+			params = append(params, triggersv1.Param{Name: b.Name, Value: *b.Value})
+		case b.Ref != "" && b.Kind == triggersv1.ClusterTriggerBindingKind:
+			return nil, fmt.Errorf("failed to find ClusterTriggerBinding %s, ClusterTriggerBindings can't be simulated", b.Ref)
+		case b.Ref != "":
+			tb, ok := s.bindings[b.Ref]
+			if !ok {
+				return nil, fmt.Errorf("failed to find TriggerBinding %s", b.Ref)
+			}
+			params = append(params, tb.Spec.Params...)
+		default:
+			return nil, fmt.Errorf("invalid binding: %v", b)
+		}
+	}
+	seen := map[string]bool{}
+	for _, p := range params {
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate param name: %s", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return params, nil
+}
+
+func (s *Simulator) template(t *triggersv1.EventListenerTemplate) (*triggersv1.TriggerTemplate, error) {
+	if t == nil {
+		return nil, fmt.Errorf("the trigger has no template")
+	}
+	if t.Spec != nil {
+		return &triggersv1.TriggerTemplate{Spec: *t.Spec}, nil
+	}
+	var name string
+	if t.Ref != nil {
+		name = *t.Ref
+	}
+	tt, ok := s.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("failed to find TriggerTemplate %s", name)
+	}
+	return tt, nil
+}
+
+func celVars(e Event) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	if len(e.Body) > 0 {
+		if err := json.Unmarshal(e.Body, &body); err != nil {
+			return nil, fmt.Errorf("failed to parse the event body as JSON: %w", err)
+		}
+	}
+	header := e.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return map[string]interface{}{
+		"body":       body,
+		"header":     header,
+		"extensions": map[string]interface{}{},
+		"requestURL": "",
+	}, nil
+}
+
+// eventTypeReason returns why the event type is not accepted by an
+// interceptor, if the interceptor has no event types, all events are
+// accepted.
+func eventTypeReason(eventType string, eventTypes []string) string {
+	if len(eventTypes) == 0 {
+		return ""
+	}
+	for _, t := range eventTypes {
+		if t == eventType {
+			return ""
+		}
+	}
+	return fmt.Sprintf("event type %s is not allowed", eventType)
+}
@@ -0,0 +1,203 @@
+package simulator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestSimulate(t *testing.T) {
+	s, el := testSimulator(t)
+
+	results, err := s.Simulate(el, Event{
+		Header: http.Header{"X-Github-Event": []string{"push"}},
+		Body:   readPayload(t, "testdata/github-push.json"),
+	})
+	test.AssertNoError(t, err)
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	pr := results[1]
+	if pr.Matched || pr.Reason == "" {
+		t.Fatalf("pull request trigger matched a push: %#v", pr)
+	}
+	push := results[0]
+	if !push.Matched {
+		t.Fatalf("push trigger didn't match: %s", push.Reason)
+	}
+	wantExtensions := map[string]interface{}{
+		"ref":       "feature/login",
+		"ref_type":  "branch",
+		"image_tag": "feature__login",
+	}
+	if diff := cmp.Diff(wantExtensions, push.Extensions); diff != "" {
+		t.Fatalf("extensions didn't match:\n%s", diff)
+	}
+	wantParams := []triggersv1.Param{
+		{Name: triggers.BuildExtraArgs, Value: ""},
+		{Name: triggers.BuilderImage, Value: ""},
+		{Name: triggers.BuildPipeline, Value: "app-ci-pipeline"},
+		{Name: triggers.ContextPath, Value: "services/api"},
+		{Name: triggers.Dockerfile, Value: "Dockerfile"},
+		{Name: "fullname", Value: "org/test"},
+		{Name: "gitrepositoryurl", Value: "https://github.com/org/test.git"},
+		{Name: "imageRepo", Value: "quay.io/org/test"},
+		{Name: triggers.ImageTag, Value: "feature__login"},
+		{Name: triggers.GitCommitAuthor, Value: "A User"},
+		{Name: triggers.GitCommitDate, Value: "2021-05-10T12:00:00Z"},
+		{Name: triggers.GitCommitID, Value: "6113728f27ae82c7b1a177c8d03f9e96e0adf246"},
+		{Name: triggers.GitCommitMessage, Value: "Add the login form"},
+		{Name: triggers.GitRef, Value: "feature/login"},
+		{Name: triggers.GitRefType, Value: "branch"},
+	}
+	if diff := cmp.Diff(wantParams, push.Params); diff != "" {
+		t.Fatalf("params didn't match:\n%s", diff)
+	}
+	if len(push.Resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(push.Resources))
+	}
+	var run pipelinev1.PipelineRun
+	test.AssertNoError(t, json.Unmarshal(push.Resources[0], &run))
+	if run.Name != "app-ci-$(uid)" {
+		t.Fatalf("got PipelineRun %s, want app-ci-$(uid)", run.Name)
+	}
+	for _, p := range run.Spec.Params {
+		if p.Name == "IMAGE" && p.Value.StringVal != "quay.io/org/test:feature__login-6113728f27ae82c7b1a177c8d03f9e96e0adf246" {
+			t.Fatalf("got IMAGE %s", p.Value.StringVal)
+		}
+	}
+}
+
+func TestSimulateWithUnmatchedPath(t *testing.T) {
+	s, el := testSimulator(t)
+	body := readPayload(t, "testdata/github-push.json")
+	var payload map[string]interface{}
+	test.AssertNoError(t, json.Unmarshal(body, &payload))
+	payload["commits"] = []interface{}{map[string]interface{}{
+		"added":    []string{},
+		"removed":  []string{},
+		"modified": []string{"services/web/main.go"},
+	}}
+	body, err := json.Marshal(payload)
+	test.AssertNoError(t, err)
+
+	results, err := s.Simulate(el, Event{
+		Header: http.Header{"X-Github-Event": []string{"push"}},
+		Body:   body,
+	})
+	test.AssertNoError(t, err)
+
+	for _, r := range results {
+		if r.Matched {
+			t.Fatalf("trigger %s matched a push that doesn't change the service", r.Trigger)
+		}
+	}
+}
+
+func TestSimulateWithEventTypes(t *testing.T) {
+	s, el := testSimulator(t)
+	el.Spec.Triggers[0].Interceptors[0].GitHub.EventTypes = []string{"pull_request"}
+
+	results, err := s.Simulate(el, Event{
+		Header: http.Header{"X-Github-Event": []string{"push"}},
+		Body:   readPayload(t, "testdata/github-push.json"),
+	})
+	test.AssertNoError(t, err)
+
+	if r := results[0]; r.Matched || r.Reason != "event type push is not allowed" {
+		t.Fatalf("got %#v", r)
+	}
+}
+
+func TestSimulateWithMissingBinding(t *testing.T) {
+	s, el := testSimulator(t)
+	delete(s.bindings, "github-push-binding")
+
+	_, err := s.Simulate(el, Event{
+		Header: http.Header{"X-Github-Event": []string{"push"}},
+		Body:   readPayload(t, "testdata/github-push.json"),
+	})
+	test.AssertErrorMatch(t, "failed to process trigger app-ci-build-from-push-test: failed to find TriggerBinding github-push-binding", err)
+}
+
+func TestSimulateWithInvalidBody(t *testing.T) {
+	s, el := testSimulator(t)
+
+	_, err := s.Simulate(el, Event{Body: []byte("push")})
+	test.AssertErrorMatch(t, "failed to parse the event body as JSON", err)
+}
+
+func TestResolveValue(t *testing.T) {
+	event := map[string]interface{}{
+		"body": map[string]interface{}{
+			"repository": map[string]interface{}{"name": "test", "private": true},
+			"commits":    []interface{}{"a", "b"},
+		},
+		"header": map[string]interface{}{"X-Github-Event": "push"},
+	}
+	valueTests := []struct {
+		value string
+		want  string
+		err   string
+	}{
+		{"$(body.repository.name)", "test", ""},
+		{"org/$(body.repository.name)", "org/test", ""},
+		{"$(body.repository.private)", "true", ""},
+		{"$(body.commits)", `["a","b"]`, ""},
+		{"$(body.commits[1])", "b", ""},
+		{"$(header.x-github-event)", "push", ""},
+		{"$(body.missing)", "", "missing is not found"},
+		{"unchanged", "unchanged", ""},
+	}
+
+	for _, tt := range valueTests {
+		t.Run(tt.value, func(rt *testing.T) {
+			got, err := resolveValue(tt.value, event)
+			if !test.ErrorMatch(rt, tt.err, err) {
+				rt.Fatalf("error mismatch: got %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				rt.Fatalf("resolveValue(%q) got %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func testSimulator(t *testing.T) (*Simulator, *triggersv1.EventListener) {
+	t.Helper()
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	test.AssertNoError(t, err)
+	contextPath := "services/api"
+	imageRepo := "quay.io/org/test"
+	push := repo.CreatePushTriggerForPath("app-ci-build-from-push-test", "webhook-secret", "cicd", "app-ci-template", []string{repo.PushBindingName()}, contextPath)
+	push.Bindings = append(push.Bindings,
+		&triggersv1.EventListenerBinding{Name: triggers.ContextPath, Value: &contextPath},
+		&triggersv1.EventListenerBinding{Name: "imageRepo", Value: &imageRepo})
+	pr := repo.CreatePullRequestTrigger("app-ci-build-from-pr-test", "webhook-secret", "cicd", "app-ci-template", []string{repo.PullRequestBindingName()})
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{push, pr})
+
+	pushBinding, _ := repo.CreatePushBinding("cicd")
+	prBinding, _ := repo.CreatePullRequestBinding("cicd")
+	template := triggers.CreateDevCIBuildPRTemplate("cicd", "pipeline")
+	s, err := New([]*triggersv1.TriggerBinding{&pushBinding, &prBinding}, []*triggersv1.TriggerTemplate{&template})
+	test.AssertNoError(t, err)
+	return s, el
+}
+
+func readPayload(t *testing.T, filename string) []byte {
+	t.Helper()
+	b, err := ioutil.ReadFile(filename)
+	test.AssertNoError(t, err)
+	return b
+}
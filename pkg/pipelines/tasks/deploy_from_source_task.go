@@ -20,13 +20,34 @@ func CreateDeployFromSourceTask(ns, script string) pipelinev1.Task {
 	return task
 }
 
+// CreateDeployFromSourceTaskWithWorkspace creates DeployFromSourceTask, which
+// reads the source from the "source" workspace, rather than a git
+// PipelineResource.
+func CreateDeployFromSourceTaskWithWorkspace(ns, script string) pipelinev1.Task {
+	return pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "deploy-from-source-task")),
+		Spec: pipelinev1.TaskSpec{
+			Params: paramsForDeploymentFromSourceTask(),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The source to deploy."},
+			},
+			Steps: createStepsForDeployFromSourceTaskInDir(script, "$(workspaces.source.path)"),
+		},
+	}
+}
+
 func createStepsForDeployFromSourceTask(script string) []pipelinev1.Step {
+	return createStepsForDeployFromSourceTaskInDir(script, "/workspace/source")
+}
+
+func createStepsForDeployFromSourceTaskInDir(script, workingDir string) []pipelinev1.Step {
 	return []pipelinev1.Step{
 		{
 			Container: createContainer(
 				"run-kubectl",
 				"quay.io/redhat-developer/k8s-kubectl",
-				"/workspace/source",
+				workingDir,
 				nil,
 				nil,
 			),
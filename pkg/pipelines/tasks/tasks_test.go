@@ -93,3 +93,22 @@ func TestCreateTaskResource(t *testing.T) {
 		t.Fatalf("createTaskResource() failed:\n%s", diff)
 	}
 }
+
+func TestCreateUpdateGitOpsTask(t *testing.T) {
+	task := CreateUpdateGitOpsTask(testNS)
+
+	if task.ObjectMeta.Name != "update-gitops" || task.ObjectMeta.Namespace != testNS {
+		t.Fatalf("CreateUpdateGitOpsTask() got %s/%s", task.ObjectMeta.Namespace, task.ObjectMeta.Name)
+	}
+	params := []string{}
+	for _, p := range task.Spec.Params {
+		params = append(params, p.Name)
+	}
+	wantParams := []string{"GITOPS_REPO", "GITOPS_BRANCH", "PULL_REQUEST", "ENVIRONMENT", "SERVICE", "IMAGE", "BUILD_STATUS", "GIT_TOKEN_SECRET_NAME", "GIT_TOKEN_SECRET_KEY", "KAM_IMAGE"}
+	if diff := cmp.Diff(wantParams, params); diff != "" {
+		t.Fatalf("CreateUpdateGitOpsTask() params failed:\n%s", diff)
+	}
+	if task.Spec.Steps[0].Script != updateGitOpsScript {
+		t.Fatalf("CreateUpdateGitOpsTask() script got %q", task.Spec.Steps[0].Script)
+	}
+}
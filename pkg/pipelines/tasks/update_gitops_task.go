@@ -0,0 +1,77 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// KamImage is the image that the update-gitops task runs kam from, it needs
+// git to be available.
+//
+// Releases pin the image by digest, it's populated at compile time, see the
+// KAM_IMAGE variable in the Makefile.
+var KamImage = "quay.io/redhat-developer/kam:latest"
+
+const updateGitOpsScript = `#!/bin/sh
+set -e
+if [ "$(params.BUILD_STATUS)" != "Succeeded" ]; then
+  echo "The image was not built, the GitOps repository is not updated."
+  exit 0
+fi
+kam service update-image \
+  --pipelines-folder /workspace/gitops \
+  --gitops-repo-url "$(params.GITOPS_REPO)" \
+  --gitops-branch "$(params.GITOPS_BRANCH)" \
+  --pull-request="$(params.PULL_REQUEST)" \
+  --env-name "$(params.ENVIRONMENT)" \
+  --service-name "$(params.SERVICE)" \
+  --image "$(params.IMAGE)" \
+  --git-host-access-token "${GITHOSTACCESSTOKEN}"
+`
+
+// CreateUpdateGitOpsTask creates a task that updates the image of a service in
+// the GitOps repository, by pushing a commit or by opening a pull request.
+func CreateUpdateGitOpsTask(namespace string) *pipelinev1.Task {
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(namespace, "update-gitops")),
+		Spec: pipelinev1.TaskSpec{
+			Params: []pipelinev1.ParamSpec{
+				createTaskParam("GITOPS_REPO", "The URL of the GitOps repository.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("GITOPS_BRANCH", "The branch of the GitOps repository to update.", pipelinev1.ParamTypeString, "main"),
+				createTaskParamWithDefault("PULL_REQUEST", "If true open a pull request rather than pushing to the branch.", pipelinev1.ParamTypeString, "false"),
+				createTaskParam("ENVIRONMENT", "The environment that the service is deployed to.", pipelinev1.ParamTypeString),
+				createTaskParam("SERVICE", "The service to update the image for.", pipelinev1.ParamTypeString),
+				createTaskParam("IMAGE", "The image that was built.", pipelinev1.ParamTypeString),
+				createTaskParam("BUILD_STATUS", "The status of the task that built the image.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
+				createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
+				createTaskParamWithDefault("KAM_IMAGE", "The image to run kam from.", pipelinev1.ParamTypeString, KamImage),
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: v1.Container{
+						Name:  "update-gitops",
+						Image: "$(params.KAM_IMAGE)",
+						Env: []v1.EnvVar{
+							{
+								Name: "GITHOSTACCESSTOKEN",
+								ValueFrom: &v1.EnvVarSource{
+									SecretKeyRef: &v1.SecretKeySelector{
+										LocalObjectReference: v1.LocalObjectReference{
+											Name: "$(params.GIT_TOKEN_SECRET_NAME)",
+										},
+										Key: "$(params.GIT_TOKEN_SECRET_KEY)",
+									},
+								},
+							},
+						},
+					},
+					Script: updateGitOpsScript,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,424 @@
+package tekton
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+// ClusterTasks are not available with tekton.dev/v1, the Tasks that are
+// installed by OpenShift Pipelines are resolved from this namespace.
+const clusterTasksNamespace = "openshift-pipelines"
+
+// ConvertResources converts the Tekton resources to tekton.dev/v1 and
+// triggers.tekton.dev/v1beta1, other resources are unchanged.
+func ConvertResources(files res.Resources) (res.Resources, error) {
+	converted := res.Resources{}
+	for k, v := range files {
+		c, err := Convert(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", k, err)
+		}
+		converted[k] = c
+	}
+	return converted, nil
+}
+
+// Convert converts a tekton.dev/v1beta1 or triggers.tekton.dev/v1alpha1
+// resource to tekton.dev/v1 or triggers.tekton.dev/v1beta1, other values are
+// returned unchanged.
+//
+// Resources that use PipelineResources, or fields that have no equivalent in
+// the converted resource, can't be converted.
+func Convert(v interface{}) (interface{}, error) {
+	switch r := v.(type) {
+	case *pipelinev1beta1.Pipeline:
+		return ConvertPipeline(r)
+	case pipelinev1beta1.Pipeline:
+		return ConvertPipeline(&r)
+	case *pipelinev1beta1.Task:
+		return ConvertTask(r)
+	case pipelinev1beta1.Task:
+		return ConvertTask(&r)
+	case *triggersv1alpha1.EventListener:
+		return ConvertEventListener(r)
+	case triggersv1alpha1.EventListener:
+		return ConvertEventListener(&r)
+	case *triggersv1alpha1.TriggerBinding:
+		return ConvertTriggerBinding(r), nil
+	case triggersv1alpha1.TriggerBinding:
+		return ConvertTriggerBinding(&r), nil
+	case *triggersv1alpha1.TriggerTemplate:
+		return ConvertTriggerTemplate(r)
+	case triggersv1alpha1.TriggerTemplate:
+		return ConvertTriggerTemplate(&r)
+	}
+	return v, nil
+}
+
+// ConvertPipeline converts a tekton.dev/v1beta1 Pipeline to tekton.dev/v1.
+func ConvertPipeline(p *pipelinev1beta1.Pipeline) (*Pipeline, error) {
+	if len(p.Spec.Resources) > 0 {
+		return nil, pipelineResourcesError("Pipeline", p.Name)
+	}
+	remaining := p.Spec
+	remaining.Params, remaining.Workspaces, remaining.Tasks, remaining.Finally = nil, nil, nil, nil
+	if err := unsupportedFields("Pipeline "+p.Name, remaining); err != nil {
+		return nil, err
+	}
+	tasks, err := convertPipelineTasks(p.Name, p.Spec.Tasks)
+	if err != nil {
+		return nil, err
+	}
+	finally, err := convertPipelineTasks(p.Name, p.Spec.Finally)
+	if err != nil {
+		return nil, err
+	}
+	return &Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: p.ObjectMeta,
+		Spec: PipelineSpec{
+			Params:     p.Spec.Params,
+			Workspaces: p.Spec.Workspaces,
+			Tasks:      tasks,
+			Finally:    finally,
+		},
+	}, nil
+}
+
+// ConvertTask converts a tekton.dev/v1beta1 Task to tekton.dev/v1.
+func ConvertTask(t *pipelinev1beta1.Task) (*Task, error) {
+	if t.Spec.Resources != nil {
+		return nil, pipelineResourcesError("Task", t.Name)
+	}
+	spec, err := convertTaskSpec("Task "+t.Name, t.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: t.ObjectMeta,
+		Spec:       spec,
+	}, nil
+}
+
+// ConvertPipelineRun converts a tekton.dev/v1beta1 PipelineRun to
+// tekton.dev/v1.
+func ConvertPipelineRun(pr *pipelinev1beta1.PipelineRun) (*PipelineRun, error) {
+	if len(pr.Spec.Resources) > 0 {
+		return nil, pipelineResourcesError("PipelineRun", pr.Name)
+	}
+	remaining := pr.Spec
+	remaining.PipelineRef, remaining.Params, remaining.Workspaces, remaining.ServiceAccountName = nil, nil, nil, ""
+	if err := unsupportedFields("PipelineRun "+pr.Name, remaining); err != nil {
+		return nil, err
+	}
+	converted := &PipelineRun{
+		TypeMeta:   pipelineRunTypeMeta,
+		ObjectMeta: pr.ObjectMeta,
+		Spec: PipelineRunSpec{
+			PipelineRef: pr.Spec.PipelineRef,
+			Params:      pr.Spec.Params,
+			Workspaces:  pr.Spec.Workspaces,
+		},
+	}
+	if pr.Spec.ServiceAccountName != "" {
+		converted.Spec.TaskRunTemplate = &PipelineTaskRunTemplate{ServiceAccountName: pr.Spec.ServiceAccountName}
+	}
+	return converted, nil
+}
+
+// ConvertEventListener converts a triggers.tekton.dev/v1alpha1 EventListener
+// to triggers.tekton.dev/v1beta1, the interceptors are replaced with
+// references to the ClusterInterceptors.
+func ConvertEventListener(el *triggersv1alpha1.EventListener) (*EventListener, error) {
+	remaining := el.Spec
+	remaining.ServiceAccountName, remaining.Triggers = "", nil
+	if err := unsupportedFields("EventListener "+el.Name, remaining); err != nil {
+		return nil, err
+	}
+	triggers := make([]EventListenerTrigger, len(el.Spec.Triggers))
+	for i, t := range el.Spec.Triggers {
+		remaining := t
+		remaining.Name, remaining.Interceptors, remaining.Bindings, remaining.Template = "", nil, nil, nil
+		if err := unsupportedFields(fmt.Sprintf("EventListener %s trigger %s", el.Name, t.Name), remaining); err != nil {
+			return nil, err
+		}
+		interceptors := make([]*TriggerInterceptor, len(t.Interceptors))
+		for j, interceptor := range t.Interceptors {
+			converted, err := convertInterceptor(interceptor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert trigger %s: %w", t.Name, err)
+			}
+			interceptors[j] = converted
+		}
+		triggers[i] = EventListenerTrigger{
+			Name:         t.Name,
+			Interceptors: interceptors,
+			Bindings:     t.Bindings,
+			Template:     t.Template,
+		}
+	}
+	return &EventListener{
+		TypeMeta:   eventListenerTypeMeta,
+		ObjectMeta: el.ObjectMeta,
+		Spec: EventListenerSpec{
+			ServiceAccountName: el.Spec.ServiceAccountName,
+			Triggers:           triggers,
+		},
+	}, nil
+}
+
+// ConvertTriggerBinding converts a triggers.tekton.dev/v1alpha1
+// TriggerBinding to triggers.tekton.dev/v1beta1.
+func ConvertTriggerBinding(tb *triggersv1alpha1.TriggerBinding) *TriggerBinding {
+	return &TriggerBinding{
+		TypeMeta:   triggerBindingTypeMeta,
+		ObjectMeta: tb.ObjectMeta,
+		Spec:       tb.Spec,
+	}
+}
+
+// ConvertTriggerTemplate converts a triggers.tekton.dev/v1alpha1
+// TriggerTemplate to triggers.tekton.dev/v1beta1, the PipelineRuns that are
+// created by the template are converted to tekton.dev/v1.
+func ConvertTriggerTemplate(tt *triggersv1alpha1.TriggerTemplate) (*TriggerTemplate, error) {
+	templates := make([]triggersv1alpha1.TriggerResourceTemplate, len(tt.Spec.ResourceTemplates))
+	for i, rt := range tt.Spec.ResourceTemplates {
+		raw, err := convertResourceTemplate(rt.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert TriggerTemplate %s: %w", tt.Name, err)
+		}
+		templates[i] = triggersv1alpha1.TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: raw}}
+	}
+	return &TriggerTemplate{
+		TypeMeta:   triggerTemplateTypeMeta,
+		ObjectMeta: tt.ObjectMeta,
+		Spec: triggersv1alpha1.TriggerTemplateSpec{
+			Params:            tt.Spec.Params,
+			ResourceTemplates: templates,
+		},
+	}, nil
+}
+
+func convertResourceTemplate(raw []byte) ([]byte, error) {
+	var pr pipelinev1beta1.PipelineRun
+	if err := json.Unmarshal(raw, &pr); err != nil {
+		return nil, err
+	}
+	if pr.Kind != "PipelineRun" {
+		return raw, nil
+	}
+	converted, err := ConvertPipelineRun(&pr)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(converted)
+}
+
+func convertPipelineTasks(pipelineName string, tasks []pipelinev1beta1.PipelineTask) ([]PipelineTask, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	converted := make([]PipelineTask, len(tasks))
+	for i, t := range tasks {
+		if t.Resources != nil {
+			return nil, pipelineResourcesError("Pipeline", pipelineName)
+		}
+		remaining := t
+		remaining.Name, remaining.TaskRef, remaining.TaskSpec, remaining.RunAfter, remaining.Params, remaining.Workspaces = "", nil, nil, nil, nil, nil
+		if err := unsupportedFields(fmt.Sprintf("Pipeline %s task %s", pipelineName, t.Name), remaining); err != nil {
+			return nil, err
+		}
+		converted[i] = PipelineTask{
+			Name:       t.Name,
+			TaskRef:    convertTaskRef(t.TaskRef),
+			RunAfter:   t.RunAfter,
+			Params:     t.Params,
+			Workspaces: t.Workspaces,
+		}
+		if t.TaskSpec != nil {
+			spec, err := convertTaskSpec(fmt.Sprintf("Pipeline %s task %s", pipelineName, t.Name), t.TaskSpec.TaskSpec)
+			if err != nil {
+				return nil, err
+			}
+			converted[i].TaskSpec = &spec
+		}
+	}
+	return converted, nil
+}
+
+// convertTaskRef replaces references to ClusterTasks with the cluster
+// resolver.
+func convertTaskRef(ref *pipelinev1beta1.TaskRef) *TaskRef {
+	if ref == nil {
+		return nil
+	}
+	if ref.Kind != pipelinev1beta1.ClusterTaskKind {
+		return &TaskRef{Name: ref.Name, Kind: string(ref.Kind)}
+	}
+	return &TaskRef{
+		Resolver: "cluster",
+		Params: []pipelinev1beta1.Param{
+			stringParam("kind", "task"),
+			stringParam("name", ref.Name),
+			stringParam("namespace", clusterTasksNamespace),
+		},
+	}
+}
+
+// convertTaskSpec converts the spec of a Task, the description identifies the
+// Task in errors.
+func convertTaskSpec(description string, spec pipelinev1beta1.TaskSpec) (TaskSpec, error) {
+	remaining := spec
+	remaining.Description, remaining.Params, remaining.Workspaces, remaining.Results, remaining.Steps, remaining.Volumes = "", nil, nil, nil, nil, nil
+	if spec.StepTemplate != nil && isEnvOnly(*spec.StepTemplate) {
+		remaining.StepTemplate = nil
+	}
+	if err := unsupportedFields(description, remaining); err != nil {
+		return TaskSpec{}, err
+	}
+	steps := make([]Step, len(spec.Steps))
+	for i, s := range spec.Steps {
+		remaining := s
+		remaining.Name, remaining.Image, remaining.ImagePullPolicy, remaining.Command, remaining.Args = "", "", "", nil, nil
+		remaining.WorkingDir, remaining.Env, remaining.VolumeMounts, remaining.SecurityContext, remaining.Script = "", nil, nil, nil, ""
+		remaining.Resources = corev1.ResourceRequirements{}
+		if err := unsupportedFields(fmt.Sprintf("%s step %s", description, s.Name), remaining); err != nil {
+			return TaskSpec{}, err
+		}
+		steps[i] = Step{
+			Name:            s.Name,
+			Image:           s.Image,
+			ImagePullPolicy: s.ImagePullPolicy,
+			Command:         s.Command,
+			Args:            s.Args,
+			WorkingDir:      s.WorkingDir,
+			Env:             s.Env,
+			VolumeMounts:    s.VolumeMounts,
+			SecurityContext: s.SecurityContext,
+			Script:          s.Script,
+		}
+		if len(s.Resources.Limits) > 0 || len(s.Resources.Requests) > 0 {
+			resources := s.Resources
+			steps[i].ComputeResources = &resources
+		}
+	}
+	converted := TaskSpec{
+		Description: spec.Description,
+		Params:      spec.Params,
+		Workspaces:  spec.Workspaces,
+		Results:     spec.Results,
+		Steps:       steps,
+		Volumes:     spec.Volumes,
+	}
+	if spec.StepTemplate != nil && len(spec.StepTemplate.Env) > 0 {
+		converted.StepTemplate = &StepTemplate{Env: spec.StepTemplate.Env}
+	}
+	return converted, nil
+}
+
+// isEnvOnly returns true if the StepTemplate only sets the environment, which
+// is all that is converted.
+func isEnvOnly(c corev1.Container) bool {
+	c.Env = nil
+	return equality.Semantic.DeepEqual(c, corev1.Container{})
+}
+
+func convertInterceptor(i *triggersv1alpha1.EventInterceptor) (*TriggerInterceptor, error) {
+	switch {
+	case i.GitHub != nil:
+		return webhookInterceptor("github", i.GitHub.SecretRef, i.GitHub.EventTypes), nil
+	case i.GitLab != nil:
+		return webhookInterceptor("gitlab", i.GitLab.SecretRef, i.GitLab.EventTypes), nil
+	case i.Bitbucket != nil:
+		return webhookInterceptor("bitbucket", i.Bitbucket.SecretRef, i.Bitbucket.EventTypes), nil
+	case i.CEL != nil:
+		params := []InterceptorParams{{Name: "filter", Value: i.CEL.Filter}}
+		if len(i.CEL.Overlays) > 0 {
+			params = append(params, InterceptorParams{Name: "overlays", Value: i.CEL.Overlays})
+		}
+		return &TriggerInterceptor{Ref: clusterInterceptorRef("cel"), Params: params}, nil
+	}
+	return nil, fmt.Errorf("unsupported interceptor %#v", i)
+}
+
+func webhookInterceptor(name string, secretRef *triggersv1alpha1.SecretRef, eventTypes []string) *TriggerInterceptor {
+	params := []InterceptorParams{}
+	if secretRef != nil {
+		params = append(params, InterceptorParams{Name: "secretRef", Value: secretRef})
+	}
+	if len(eventTypes) > 0 {
+		params = append(params, InterceptorParams{Name: "eventTypes", Value: eventTypes})
+	}
+	return &TriggerInterceptor{Ref: clusterInterceptorRef(name), Params: params}
+}
+
+func clusterInterceptorRef(name string) InterceptorRef {
+	return InterceptorRef{Name: name, Kind: "ClusterInterceptor"}
+}
+
+func stringParam(name, value string) pipelinev1beta1.Param {
+	return pipelinev1beta1.Param{
+		Name:  name,
+		Value: pipelinev1beta1.ArrayOrString{Type: pipelinev1beta1.ParamTypeString, StringVal: value},
+	}
+}
+
+// unsupportedFields returns an error if any of the fields of the resource that
+// remain after clearing the converted fields are set, as these would be
+// silently dropped from the converted resource.
+func unsupportedFields(description string, remaining interface{}) error {
+	b, err := json.Marshal(remaining)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	fields := []string{}
+	for k, v := range values {
+		if isEmptyValue(v) {
+			continue
+		}
+		fields = append(fields, k)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Strings(fields)
+	return fmt.Errorf("%s uses %s, which can't be converted to tekton.dev/v1", description, strings.Join(fields, ", "))
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		for _, e := range t {
+			if !isEmptyValue(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func pipelineResourcesError(kind, name string) error {
+	return fmt.Errorf("%s %s uses PipelineResources, which are not supported by tekton.dev/v1", kind, name)
+}
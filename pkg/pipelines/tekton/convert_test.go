@@ -0,0 +1,421 @@
+package tekton
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestConvertPipeline(t *testing.T) {
+	p := &pipelinev1beta1.Pipeline{
+		TypeMeta:   meta.TypeMeta("Pipeline", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "app-ci-pipeline")),
+		Spec: pipelinev1beta1.PipelineSpec{
+			Params:     []pipelinev1beta1.ParamSpec{{Name: "GIT_REPO", Type: pipelinev1beta1.ParamTypeString}},
+			Workspaces: []pipelinev1beta1.PipelineWorkspaceDeclaration{{Name: "shared-data"}},
+			Tasks: []pipelinev1beta1.PipelineTask{
+				{
+					Name:       "clone-source",
+					TaskRef:    &pipelinev1beta1.TaskRef{Name: "git-clone", Kind: pipelinev1beta1.ClusterTaskKind},
+					Params:     []pipelinev1beta1.Param{stringParam("url", "$(params.GIT_REPO)")},
+					Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{{Name: "output", Workspace: "shared-data"}},
+				},
+				{
+					Name:     "lint",
+					RunAfter: []string{"clone-source"},
+					TaskSpec: &pipelinev1beta1.EmbeddedTask{
+						TaskSpec: pipelinev1beta1.TaskSpec{
+							Steps: []pipelinev1beta1.Step{
+								{Container: corev1.Container{Name: "lint", Image: "golangci/golangci-lint"}, Script: "golangci-lint run"},
+							},
+						},
+					},
+				},
+			},
+			Finally: []pipelinev1beta1.PipelineTask{
+				{Name: "set-final-status", TaskRef: &pipelinev1beta1.TaskRef{Name: "set-commit-status", Kind: pipelinev1beta1.NamespacedTaskKind}},
+			},
+		},
+	}
+
+	converted, err := ConvertPipeline(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "app-ci-pipeline")),
+		Spec: PipelineSpec{
+			Params:     []pipelinev1beta1.ParamSpec{{Name: "GIT_REPO", Type: pipelinev1beta1.ParamTypeString}},
+			Workspaces: []pipelinev1beta1.PipelineWorkspaceDeclaration{{Name: "shared-data"}},
+			Tasks: []PipelineTask{
+				{
+					Name: "clone-source",
+					TaskRef: &TaskRef{
+						Resolver: "cluster",
+						Params: []pipelinev1beta1.Param{
+							stringParam("kind", "task"),
+							stringParam("name", "git-clone"),
+							stringParam("namespace", "openshift-pipelines"),
+						},
+					},
+					Params:     []pipelinev1beta1.Param{stringParam("url", "$(params.GIT_REPO)")},
+					Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{{Name: "output", Workspace: "shared-data"}},
+				},
+				{
+					Name:     "lint",
+					RunAfter: []string{"clone-source"},
+					TaskSpec: &TaskSpec{
+						Steps: []Step{{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"}},
+					},
+				},
+			},
+			Finally: []PipelineTask{
+				{Name: "set-final-status", TaskRef: &TaskRef{Name: "set-commit-status", Kind: "Task"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, converted); diff != "" {
+		t.Fatalf("ConvertPipeline() failed:\n%s", diff)
+	}
+}
+
+func TestConvertPipelineWithResources(t *testing.T) {
+	p := &pipelinev1beta1.Pipeline{
+		TypeMeta:   meta.TypeMeta("Pipeline", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "ci-dryrun-from-push-pipeline")),
+		Spec: pipelinev1beta1.PipelineSpec{
+			Resources: []pipelinev1beta1.PipelineDeclaredResource{{Name: "source-repo", Type: "git"}},
+		},
+	}
+
+	_, err := ConvertPipeline(p)
+	test.AssertErrorMatch(t, "Pipeline ci-dryrun-from-push-pipeline uses PipelineResources", err)
+}
+
+func TestConvertWithUnsupportedFields(t *testing.T) {
+	convertTests := []struct {
+		name    string
+		v       interface{}
+		wantErr string
+	}{
+		{
+			"pipeline results",
+			&pipelinev1beta1.Pipeline{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "app-ci-pipeline")),
+				Spec: pipelinev1beta1.PipelineSpec{
+					Results: []pipelinev1beta1.PipelineResult{{Name: "digest", Value: "$(tasks.build.results.digest)"}},
+				},
+			},
+			"Pipeline app-ci-pipeline uses results, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"pipeline task retries",
+			&pipelinev1beta1.Pipeline{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "app-ci-pipeline")),
+				Spec: pipelinev1beta1.PipelineSpec{
+					Tasks: []pipelinev1beta1.PipelineTask{{Name: "build", Retries: 2, TaskRef: &pipelinev1beta1.TaskRef{Name: "buildah"}}},
+				},
+			},
+			"Pipeline app-ci-pipeline task build uses retries, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"task sidecars",
+			&pipelinev1beta1.Task{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "deploy")),
+				Spec: pipelinev1beta1.TaskSpec{
+					Sidecars: []pipelinev1beta1.Sidecar{{Container: corev1.Container{Name: "docker"}}},
+				},
+			},
+			"Task deploy uses sidecars, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"step ports",
+			&pipelinev1beta1.Task{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "deploy")),
+				Spec: pipelinev1beta1.TaskSpec{
+					Steps: []pipelinev1beta1.Step{{Container: corev1.Container{Name: "apply", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}}},
+				},
+			},
+			"Task deploy step apply uses ports, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"step template",
+			&pipelinev1beta1.Task{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "deploy")),
+				Spec: pipelinev1beta1.TaskSpec{
+					StepTemplate: &corev1.Container{Image: "quay.io/example/image"},
+				},
+			},
+			"Task deploy uses stepTemplate, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"event listener replicas",
+			&triggersv1alpha1.EventListener{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "cicd-event-listener")),
+				Spec:       triggersv1alpha1.EventListenerSpec{Replicas: int32Ptr(2)},
+			},
+			"EventListener cicd-event-listener uses replicas, which can't be converted to tekton.dev/v1",
+		},
+		{
+			"trigger ref",
+			&triggersv1alpha1.EventListener{
+				ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "cicd-event-listener")),
+				Spec: triggersv1alpha1.EventListenerSpec{
+					Triggers: []triggersv1alpha1.EventListenerTrigger{{Name: "ci", TriggerRef: "ci-trigger"}},
+				},
+			},
+			"EventListener cicd-event-listener trigger ci uses triggerRef, which can't be converted to tekton.dev/v1",
+		},
+	}
+
+	for _, tt := range convertTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			_, err := Convert(tt.v)
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
+
+func TestConvertPipelineRunWithTimeout(t *testing.T) {
+	pr := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-ci-$(uid)"},
+		Spec: pipelinev1beta1.PipelineRunSpec{
+			PipelineRef: &pipelinev1beta1.PipelineRef{Name: "app-ci-pipeline"},
+			Timeout:     &metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	_, err := ConvertPipelineRun(pr)
+	test.AssertErrorMatch(t, "PipelineRun app-ci-\\$\\(uid\\) uses timeout", err)
+}
+
+func TestConvertTask(t *testing.T) {
+	privileged := true
+	task := &pipelinev1beta1.Task{
+		TypeMeta:   meta.TypeMeta("Task", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "buildah")),
+		Spec: pipelinev1beta1.TaskSpec{
+			Description: "Buildah task builds source into a container image.",
+			Params:      []pipelinev1beta1.ParamSpec{{Name: "IMAGE"}},
+			Workspaces:  []pipelinev1beta1.WorkspaceDeclaration{{Name: "source"}},
+			Results:     []pipelinev1beta1.TaskResult{{Name: "IMAGE_DIGEST"}},
+			Steps: []pipelinev1beta1.Step{
+				{
+					Container: corev1.Container{
+						Name:            "build",
+						Image:           "quay.io/buildah/stable:v1.17.0",
+						VolumeMounts:    []corev1.VolumeMount{{Name: "varlibcontainers", MountPath: "/var/lib/containers"}},
+						SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+					},
+					Script: "buildah bud -t $(params.IMAGE) .",
+				},
+			},
+			Volumes: []corev1.Volume{{Name: "varlibcontainers", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+		},
+	}
+
+	converted, err := ConvertTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "buildah")),
+		Spec: TaskSpec{
+			Description: "Buildah task builds source into a container image.",
+			Params:      []pipelinev1beta1.ParamSpec{{Name: "IMAGE"}},
+			Workspaces:  []pipelinev1beta1.WorkspaceDeclaration{{Name: "source"}},
+			Results:     []pipelinev1beta1.TaskResult{{Name: "IMAGE_DIGEST"}},
+			Steps: []Step{
+				{
+					Name:            "build",
+					Image:           "quay.io/buildah/stable:v1.17.0",
+					VolumeMounts:    []corev1.VolumeMount{{Name: "varlibcontainers", MountPath: "/var/lib/containers"}},
+					SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+					Script:          "buildah bud -t $(params.IMAGE) .",
+				},
+			},
+			Volumes: []corev1.Volume{{Name: "varlibcontainers", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+		},
+	}
+	if diff := cmp.Diff(want, converted); diff != "" {
+		t.Fatalf("ConvertTask() failed:\n%s", diff)
+	}
+}
+
+func TestConvertEventListener(t *testing.T) {
+	el := &triggersv1alpha1.EventListener{
+		TypeMeta:   meta.TypeMeta("EventListener", "triggers.tekton.dev/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "cicd-event-listener")),
+		Spec: triggersv1alpha1.EventListenerSpec{
+			ServiceAccountName: "pipeline",
+			Triggers: []triggersv1alpha1.EventListenerTrigger{
+				{
+					Name: "ci-dryrun-from-push",
+					Interceptors: []*triggersv1alpha1.EventInterceptor{
+						{
+							GitHub: &triggersv1alpha1.GitHubInterceptor{
+								SecretRef: &triggersv1alpha1.SecretRef{SecretName: "gitops-webhook-secret", SecretKey: "webhook-secret-key"},
+							},
+						},
+						{
+							CEL: &triggersv1alpha1.CELInterceptor{
+								Filter:   "header.match('X-GitHub-Event', 'push')",
+								Overlays: []triggersv1alpha1.CELOverlay{{Key: "ref", Expression: "split(body.ref,'/')[2]"}},
+							},
+						},
+					},
+					Bindings: []*triggersv1alpha1.EventListenerBinding{{Ref: "github-push-binding"}},
+					Template: &triggersv1alpha1.EventListenerTemplate{Ref: ptr("ci-dryrun-from-push-template")},
+				},
+			},
+		},
+	}
+
+	converted, err := ConvertEventListener(el)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &EventListener{
+		TypeMeta:   eventListenerTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "cicd-event-listener")),
+		Spec: EventListenerSpec{
+			ServiceAccountName: "pipeline",
+			Triggers: []EventListenerTrigger{
+				{
+					Name: "ci-dryrun-from-push",
+					Interceptors: []*TriggerInterceptor{
+						{
+							Ref: InterceptorRef{Name: "github", Kind: "ClusterInterceptor"},
+							Params: []InterceptorParams{
+								{Name: "secretRef", Value: &triggersv1alpha1.SecretRef{SecretName: "gitops-webhook-secret", SecretKey: "webhook-secret-key"}},
+							},
+						},
+						{
+							Ref: InterceptorRef{Name: "cel", Kind: "ClusterInterceptor"},
+							Params: []InterceptorParams{
+								{Name: "filter", Value: "header.match('X-GitHub-Event', 'push')"},
+								{Name: "overlays", Value: []triggersv1alpha1.CELOverlay{{Key: "ref", Expression: "split(body.ref,'/')[2]"}}},
+							},
+						},
+					},
+					Bindings: []*triggersv1alpha1.EventListenerBinding{{Ref: "github-push-binding"}},
+					Template: &triggersv1alpha1.EventListenerTemplate{Ref: ptr("ci-dryrun-from-push-template")},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, converted); diff != "" {
+		t.Fatalf("ConvertEventListener() failed:\n%s", diff)
+	}
+}
+
+func TestConvertTriggerTemplate(t *testing.T) {
+	pr := pipelinev1beta1.PipelineRun{
+		TypeMeta:   meta.TypeMeta("PipelineRun", "tekton.dev/v1beta1"),
+		ObjectMeta: metav1.ObjectMeta{Name: "app-ci-$(uid)"},
+		Spec: pipelinev1beta1.PipelineRunSpec{
+			ServiceAccountName: "pipeline",
+			PipelineRef:        &pipelinev1beta1.PipelineRef{Name: "app-ci-pipeline"},
+			Params:             []pipelinev1beta1.Param{stringParam("GIT_REPO", "$(tt.params.gitrepositoryurl)")},
+		},
+	}
+	tt := &triggersv1alpha1.TriggerTemplate{
+		TypeMeta:   meta.TypeMeta("TriggerTemplate", "triggers.tekton.dev/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "app-ci-template")),
+		Spec: triggersv1alpha1.TriggerTemplateSpec{
+			Params: []triggersv1alpha1.ParamSpec{{Name: "gitrepositoryurl"}},
+			ResourceTemplates: []triggersv1alpha1.TriggerResourceTemplate{
+				{RawExtension: runtime.RawExtension{Raw: mustMarshal(t, pr)}},
+			},
+		},
+	}
+
+	converted, err := ConvertTriggerTemplate(tt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(triggerTemplateTypeMeta, converted.TypeMeta); diff != "" {
+		t.Fatalf("ConvertTriggerTemplate() failed:\n%s", diff)
+	}
+	var got PipelineRun
+	if err := json.Unmarshal(converted.Spec.ResourceTemplates[0].Raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := PipelineRun{
+		TypeMeta:   pipelineRunTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "app-ci-$(uid)"},
+		Spec: PipelineRunSpec{
+			PipelineRef:     &pipelinev1beta1.PipelineRef{Name: "app-ci-pipeline"},
+			Params:          []pipelinev1beta1.Param{stringParam("GIT_REPO", "$(tt.params.gitrepositoryurl)")},
+			TaskRunTemplate: &PipelineTaskRunTemplate{ServiceAccountName: "pipeline"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ConvertTriggerTemplate() failed:\n%s", diff)
+	}
+}
+
+func TestConvertResources(t *testing.T) {
+	binding := triggersv1alpha1.TriggerBinding{
+		TypeMeta:   meta.TypeMeta("TriggerBinding", "triggers.tekton.dev/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "github-push-binding")),
+		Spec: triggersv1alpha1.TriggerBindingSpec{
+			Params: []triggersv1alpha1.Param{{Name: "gitrepositoryurl", Value: "$(body.repository.clone_url)"}},
+		},
+	}
+	kustomization := res.Kustomization{Resources: []string{"05-bindings/github-push-binding.yaml"}}
+
+	converted, err := ConvertResources(res.Resources{
+		"05-bindings/github-push-binding.yaml": binding,
+		"kustomization.yaml":                   kustomization,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"05-bindings/github-push-binding.yaml": &TriggerBinding{
+			TypeMeta:   triggerBindingTypeMeta,
+			ObjectMeta: binding.ObjectMeta,
+			Spec:       binding.Spec,
+		},
+		"kustomization.yaml": kustomization,
+	}
+	if diff := cmp.Diff(want, converted); diff != "" {
+		t.Fatalf("ConvertResources() failed:\n%s", diff)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
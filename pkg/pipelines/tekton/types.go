@@ -0,0 +1,184 @@
+package tekton
+
+import (
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// This is a minimal implementation of the tekton.dev/v1 and
+// triggers.tekton.dev/v1beta1 resources that are generated, the vendored
+// Tekton packages predate these APIs.
+//
+// The parts of the resources that are unchanged from the older APIs use the
+// vendored types.
+
+var (
+	pipelineTypeMeta        = meta.TypeMeta("Pipeline", "tekton.dev/v1")
+	taskTypeMeta            = meta.TypeMeta("Task", "tekton.dev/v1")
+	pipelineRunTypeMeta     = meta.TypeMeta("PipelineRun", "tekton.dev/v1")
+	eventListenerTypeMeta   = meta.TypeMeta("EventListener", "triggers.tekton.dev/v1beta1")
+	triggerBindingTypeMeta  = meta.TypeMeta("TriggerBinding", "triggers.tekton.dev/v1beta1")
+	triggerTemplateTypeMeta = meta.TypeMeta("TriggerTemplate", "triggers.tekton.dev/v1beta1")
+)
+
+// Pipeline is the tekton.dev/v1 representation of a Pipeline.
+type Pipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PipelineSpec `json:"spec"`
+}
+
+// PipelineSpec is the specification of a Pipeline.
+type PipelineSpec struct {
+	Params     []pipelinev1beta1.ParamSpec                    `json:"params,omitempty"`
+	Workspaces []pipelinev1beta1.PipelineWorkspaceDeclaration `json:"workspaces,omitempty"`
+	Tasks      []PipelineTask                                 `json:"tasks,omitempty"`
+	Finally    []PipelineTask                                 `json:"finally,omitempty"`
+}
+
+// PipelineTask is a Task that is run within a Pipeline.
+type PipelineTask struct {
+	Name       string                                         `json:"name"`
+	TaskRef    *TaskRef                                       `json:"taskRef,omitempty"`
+	TaskSpec   *TaskSpec                                      `json:"taskSpec,omitempty"`
+	RunAfter   []string                                       `json:"runAfter,omitempty"`
+	Params     []pipelinev1beta1.Param                        `json:"params,omitempty"`
+	Workspaces []pipelinev1beta1.WorkspacePipelineTaskBinding `json:"workspaces,omitempty"`
+}
+
+// TaskRef refers to a Task, either by name in the namespace of the Pipeline,
+// or through a resolver.
+type TaskRef struct {
+	Name     string                  `json:"name,omitempty"`
+	Kind     string                  `json:"kind,omitempty"`
+	Resolver string                  `json:"resolver,omitempty"`
+	Params   []pipelinev1beta1.Param `json:"params,omitempty"`
+}
+
+// Task is the tekton.dev/v1 representation of a Task.
+type Task struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TaskSpec `json:"spec"`
+}
+
+// TaskSpec is the specification of a Task.
+type TaskSpec struct {
+	Description  string                                 `json:"description,omitempty"`
+	Params       []pipelinev1beta1.ParamSpec            `json:"params,omitempty"`
+	Workspaces   []pipelinev1beta1.WorkspaceDeclaration `json:"workspaces,omitempty"`
+	Results      []pipelinev1beta1.TaskResult           `json:"results,omitempty"`
+	StepTemplate *StepTemplate                          `json:"stepTemplate,omitempty"`
+	Steps        []Step                                 `json:"steps"`
+	Volumes      []corev1.Volume                        `json:"volumes,omitempty"`
+}
+
+// Step is a container that is run as part of a Task, unlike the older APIs,
+// this doesn't embed the Container.
+type Step struct {
+	Name             string                       `json:"name"`
+	Image            string                       `json:"image,omitempty"`
+	ImagePullPolicy  corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Command          []string                     `json:"command,omitempty"`
+	Args             []string                     `json:"args,omitempty"`
+	WorkingDir       string                       `json:"workingDir,omitempty"`
+	Env              []corev1.EnvVar              `json:"env,omitempty"`
+	ComputeResources *corev1.ResourceRequirements `json:"computeResources,omitempty"`
+	VolumeMounts     []corev1.VolumeMount         `json:"volumeMounts,omitempty"`
+	SecurityContext  *corev1.SecurityContext      `json:"securityContext,omitempty"`
+	Script           string                       `json:"script,omitempty"`
+}
+
+// StepTemplate is the template that the Steps of a Task are based on.
+type StepTemplate struct {
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// PipelineRun is the tekton.dev/v1 representation of a PipelineRun.
+type PipelineRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PipelineRunSpec `json:"spec"`
+}
+
+// PipelineRunSpec is the specification of a PipelineRun.
+type PipelineRunSpec struct {
+	PipelineRef     *pipelinev1beta1.PipelineRef       `json:"pipelineRef,omitempty"`
+	Params          []pipelinev1beta1.Param            `json:"params,omitempty"`
+	Workspaces      []pipelinev1beta1.WorkspaceBinding `json:"workspaces,omitempty"`
+	TaskRunTemplate *PipelineTaskRunTemplate           `json:"taskRunTemplate,omitempty"`
+}
+
+// PipelineTaskRunTemplate configures the TaskRuns of a PipelineRun.
+type PipelineTaskRunTemplate struct {
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// EventListener is the triggers.tekton.dev/v1beta1 representation of an
+// EventListener.
+type EventListener struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EventListenerSpec `json:"spec"`
+}
+
+// EventListenerSpec is the specification of an EventListener.
+type EventListenerSpec struct {
+	ServiceAccountName string                 `json:"serviceAccountName,omitempty"`
+	Triggers           []EventListenerTrigger `json:"triggers"`
+}
+
+// EventListenerTrigger processes events, the bindings and template are
+// unchanged from the older API.
+type EventListenerTrigger struct {
+	Name         string                                   `json:"name,omitempty"`
+	Interceptors []*TriggerInterceptor                    `json:"interceptors,omitempty"`
+	Bindings     []*triggersv1alpha1.EventListenerBinding `json:"bindings,omitempty"`
+	Template     *triggersv1alpha1.EventListenerTemplate  `json:"template,omitempty"`
+}
+
+// TriggerInterceptor refers to a ClusterInterceptor, with the parameters that
+// configure it.
+type TriggerInterceptor struct {
+	Ref    InterceptorRef      `json:"ref"`
+	Params []InterceptorParams `json:"params,omitempty"`
+}
+
+// InterceptorRef is a reference to a ClusterInterceptor.
+type InterceptorRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// InterceptorParams is a parameter for an interceptor, the value can be any
+// JSON value.
+type InterceptorParams struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// TriggerBinding is the triggers.tekton.dev/v1beta1 representation of a
+// TriggerBinding.
+type TriggerBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec triggersv1alpha1.TriggerBindingSpec `json:"spec"`
+}
+
+// TriggerTemplate is the triggers.tekton.dev/v1beta1 representation of a
+// TriggerTemplate.
+type TriggerTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec triggersv1alpha1.TriggerTemplateSpec `json:"spec"`
+}
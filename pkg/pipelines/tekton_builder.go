@@ -3,18 +3,42 @@ package pipelines
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type tektonBuilder struct {
-	files      res.Resources
-	gitOpsRepo string
-	triggers   []v1alpha1.EventListenerTrigger
+	files        res.Resources
+	gitOpsRepo   string
+	triggers     []v1alpha1.EventListenerTrigger
+	strategies   map[string]bool
+	catalogTasks map[string]bool
+	cfg          *config.PipelinesConfig
+	// repos are the repositories that trigger builds, keyed by the name of
+	// their push binding, the TriggerBindings are created for each driver.
+	repos map[string]scm.Repository
+}
+
+// appCIPipelineFactories creates the AppCIPipelines for the build strategies,
+// the AppCIPipeline for the default buildah strategy is created at bootstrap,
+// unless a service has tests.
+var appCIPipelineFactories = map[string]func(types.NamespacedName, ...*config.TestStep) *pipelinev1.Pipeline{
+	config.BuildStrategyBuildah:    pipelines.CreateAppCIPipeline,
+	config.BuildStrategyS2I:        pipelines.CreateS2IAppCIPipeline,
+	config.BuildStrategyBuildpacks: pipelines.CreateBuildpacksAppCIPipeline,
+	config.BuildStrategyKaniko:     pipelines.CreateKanikoAppCIPipeline,
 }
 
 func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Resources, error) {
@@ -26,19 +50,60 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, nil
 	}
 	files := make(res.Resources)
-	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo}
-	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
+	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo, strategies: map[string]bool{}, catalogTasks: map[string]bool{}, cfg: cfg, repos: map[string]scm.Repository{}}
+	for _, name := range defaultCatalogTasks {
+		tb.catalogTasks[name] = true
+	}
+	for name := range cfg.CatalogTasks {
+		tb.catalogTasks[name] = true
+	}
+	repo, err := scm.NewRepository(tb.gitOpsRepo)
+	if err != nil {
+		return nil, err
+	}
+	tb.repos[repo.PushBindingName()] = repo
+	cicdTriggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
 	if err != nil {
 		return nil, err
 	}
-	tb.triggers = append(tb.triggers, triggers...)
+	tb.triggers = append(tb.triggers, cicdTriggers...)
 	err = m.Walk(tb)
 	if err != nil {
 		return nil, err
 	}
 	cicdPath := config.PathForPipelines(cfg)
+	for _, r := range tb.repos {
+		pushBinding, pushBindingName := r.CreatePushBinding(cfg.Name)
+		files[getBindingPath(cicdPath, pushBindingName)] = pushBinding
+		prBinding, prBindingName := r.CreatePullRequestBinding(cfg.Name)
+		files[getBindingPath(cicdPath, prBindingName)] = prBinding
+	}
 	files[getEventListenerPath(cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, tb.triggers)
-	return files, nil
+	// The default AppCIPipeline and its TriggerTemplate are created at
+	// bootstrap, they're regenerated so that GitOps repositories that were
+	// bootstrapped by earlier releases are passed the build parameters.
+	files[getAppCIPipelinePath(cicdPath, appCIPipelineName(config.BuildStrategyBuildah))] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cfg.Name, appCIPipelineName(config.BuildStrategyBuildah)))
+	files[filepath.ToSlash(filepath.Join(cicdPath, "base", appCIPushTemplatePath))] = triggers.CreateDevCIBuildPRTemplate(cfg.Name, saName)
+	for strategy := range tb.strategies {
+		name := appCIPipelineName(strategy)
+		files[getAppCIPipelinePath(cicdPath, name)] = appCIPipelineFactories[strategy](meta.NamespacedName(cfg.Name, name))
+	}
+	catalogTasks, err := createCatalogTasks(cfg, tb.catalogTaskNames()...)
+	if err != nil {
+		return nil, err
+	}
+	return res.Merge(addPrefixToResources(filepath.Join(cicdPath, "base"), catalogTasks), files), nil
+}
+
+// catalogTaskNames returns the names of the catalog Tasks that are used by
+// the Pipelines, sorted by name.
+func (tb *tektonBuilder) catalogTaskNames() []string {
+	names := make([]string, 0, len(tb.catalogTasks))
+	for k := range tb.catalogTasks {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (tb *tektonBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
@@ -50,11 +115,183 @@ func (tb *tektonBuilder) Service(app *config.Application, env *config.Environmen
 		return err
 	}
 	pipelines := getPipelines(env, svc, repo)
-	ciTrigger := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+	strategy := svc.GetBuildStrategy()
+	tb.catalogTasks[strategyCatalogTasks[strategy]] = true
+	pipelineName := appCIPipelineName(strategy)
+	if len(pipelines.Tests) > 0 || hasKanikoBuildArgs(svc) {
+		// Tests and kaniko build args are specific to the service, so the
+		// service needs its own AppCIPipeline.
+		pipelineName = serviceCIPipelineName(env, svc)
+		tb.files[getAppCIPipelinePath(config.PathForPipelines(tb.cfg), pipelineName)] = servicePipeline(meta.NamespacedName(tb.cfg.Name, pipelineName), strategy, svc, pipelines)
+	} else if strategy != config.BuildStrategyBuildah {
+		tb.strategies[strategy] = true
+	}
+	if pipelines.Workspaces != nil {
+		if pipelines.Integration.Template != appCITemplateName {
+			return fmt.Errorf("failed to configure the workspaces for service %s, workspaces can't be configured for the template %s", svc.Name, pipelines.Integration.Template)
+		}
+		// The workspaces are bound in the TriggerTemplate, so the service
+		// needs its own TriggerTemplate.
+		templateName := serviceCITemplateName(env, svc)
+		tb.files[filepath.ToSlash(filepath.Join(config.PathForPipelines(tb.cfg), "base", serviceCITemplatePath(templateName)))] = triggers.CreateDevCIBuildPRTemplateWithWorkspaces(tb.cfg.Name, templateName, saName, ciWorkspaceBindings(pipelines.Workspaces))
+		pipelines.Integration.Template = templateName
+	}
+	tb.repos[repo.PushBindingName()] = repo
+	sourcePath := config.CleanSourcePath(svc.SourcePath)
+	filter := pushFilter(sourcePath, svc.Triggers)
+	if pipelines.UpdateGitOps != nil {
+		// Only images built from a push to the default branch are promoted,
+		// these pushes are built with a Pipeline that also updates the GitOps
+		// repository.
+		promoteFilter := filter
+		promoteFilter.DefaultBranch = boolPtr(true)
+		filter.DefaultBranch = boolPtr(false)
+		promotePipelineName := serviceCIPipelineName(env, svc) + "-promote"
+		tb.files[getAppCIPipelinePath(config.PathForPipelines(tb.cfg), promotePipelineName)] = promotionPipeline(meta.NamespacedName(tb.cfg.Name, promotePipelineName), strategy, tb.gitOpsRepo, env, svc, pipelines)
+		tb.files[getUpdateGitOpsTaskPath(config.PathForPipelines(tb.cfg))] = tasks.CreateUpdateGitOpsTask(tb.cfg.Name)
+		promoteTrigger := repo.CreateFilteredPushTrigger(promoteTriggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings, promoteFilter)
+		promoteTrigger.Bindings = append(promoteTrigger.Bindings, buildBindings(sourcePath, svc.Build, promotePipelineName)...)
+		tb.triggers = append(tb.triggers, promoteTrigger)
+	}
+	ciTrigger := repo.CreateFilteredPushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings, filter)
+	ciTrigger.Bindings = append(ciTrigger.Bindings, buildBindings(sourcePath, svc.Build, pipelineName)...)
 	tb.triggers = append(tb.triggers, ciTrigger)
+	// Pull request events don't list the changed files, so they can't be
+	// matched to the services that are built from a path in the repository,
+	// these services are only built from pushes.
+	if sourcePath == "" {
+		prTrigger := repo.CreateFilteredPullRequestTrigger(pullRequestTriggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pullRequestBindings(repo, pipelines.Integration.Bindings), pullRequestFilter(svc.Triggers))
+		prTrigger.Bindings = append(prTrigger.Bindings, buildBindings(sourcePath, svc.Build, pipelineName)...)
+		tb.triggers = append(tb.triggers, prTrigger)
+	}
 	return nil
 }
 
+// pushFilter returns the filter for the pushes that build a service.
+func pushFilter(sourcePath string, t *config.Triggers) scm.PushFilter {
+	filter := scm.PushFilter{Path: sourcePath}
+	if t == nil {
+		return filter
+	}
+	filter.Tags = t.Tags
+	if t.Branches != nil {
+		filter.Branches = t.Branches.Include
+		filter.ExcludeBranches = t.Branches.Exclude
+	}
+	return filter
+}
+
+// pullRequestFilter returns the filter for the pull requests that build a
+// service, the branch filters apply to the target branch of the pull request.
+func pullRequestFilter(t *config.Triggers) scm.PullRequestFilter {
+	if t == nil || t.Branches == nil {
+		return scm.PullRequestFilter{}
+	}
+	return scm.PullRequestFilter{Branches: t.Branches.Include, ExcludeBranches: t.Branches.Exclude}
+}
+
+// promotionPipeline creates an AppCIPipeline for a service that updates the
+// image for the service in the GitOps repository after it is built.
+func promotionPipeline(name types.NamespacedName, strategy, gitOpsRepo string, env *config.Environment, svc *config.Service, p *config.Pipelines) *pipelinev1.Pipeline {
+	pipeline := servicePipeline(name, strategy, svc, p)
+	pipelines.AddUpdateGitOpsTask(pipeline, gitOpsRepo, env.Name, svc.Name, p.UpdateGitOps)
+	return pipeline
+}
+
+// servicePipeline creates an AppCIPipeline for a service with its tests, the
+// kaniko Task accepts the build args as an array, which can't be passed
+// through the TriggerTemplate, so they're set in the service's AppCIPipeline.
+func servicePipeline(name types.NamespacedName, strategy string, svc *config.Service, p *config.Pipelines) *pipelinev1.Pipeline {
+	pipeline := appCIPipelineFactories[strategy](name, p.Tests...)
+	if hasKanikoBuildArgs(svc) {
+		pipelines.SetKanikoExtraArgs(pipeline, buildArgs(svc.Build.BuildArgs))
+	}
+	return pipeline
+}
+
+// hasKanikoBuildArgs returns true if the service is built with kaniko and has
+// build args.
+func hasKanikoBuildArgs(svc *config.Service) bool {
+	return svc.GetBuildStrategy() == config.BuildStrategyKaniko && svc.Build != nil && len(svc.Build.BuildArgs) > 0
+}
+
+// buildBindings provides the path within the source repository to build the
+// service from, the build configuration for the service and the Pipeline that
+// builds it, these are passed to the TriggerTemplate as inline bindings on the
+// service's triggers.
+func buildBindings(sourcePath string, build *config.Build, pipelineName string) []*v1alpha1.EventListenerBinding {
+	bindings := []*v1alpha1.EventListenerBinding{}
+	if sourcePath != "" {
+		bindings = append(bindings, inlineBinding(triggers.ContextPath, sourcePath))
+	}
+	if pipelineName != appCIPipelineName(config.BuildStrategyBuildah) {
+		bindings = append(bindings, inlineBinding(triggers.BuildPipeline, pipelineName))
+	}
+	if build == nil {
+		return bindings
+	}
+	if build.Dockerfile != "" {
+		bindings = append(bindings, inlineBinding(triggers.Dockerfile, build.Dockerfile))
+	}
+	if build.BuilderImage != "" {
+		bindings = append(bindings, inlineBinding(triggers.BuilderImage, build.BuilderImage))
+	}
+	// The kaniko build args are set in the service's AppCIPipeline.
+	if len(build.BuildArgs) > 0 && build.Strategy != config.BuildStrategyKaniko {
+		bindings = append(bindings, inlineBinding(triggers.BuildExtraArgs, strings.Join(buildArgs(build.BuildArgs), " ")))
+	}
+	return bindings
+}
+
+func inlineBinding(name, value string) *v1alpha1.EventListenerBinding {
+	return &v1alpha1.EventListenerBinding{
+		Name:  name,
+		Value: &value,
+	}
+}
+
+// buildArgs returns the build args as command-line arguments, sorted by name
+// so that the generated resources are stable.
+func buildArgs(args map[string]string) []string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	formatted := make([]string, len(keys))
+	for i, k := range keys {
+		formatted[i] = fmt.Sprintf("--build-arg=%s=%s", k, args[k])
+	}
+	return formatted
+}
+
+// appCIPipelineName returns the name of the AppCIPipeline for a build
+// strategy.
+func appCIPipelineName(strategy string) string {
+	if strategy == config.BuildStrategyBuildah {
+		return "app-ci-pipeline"
+	}
+	return "app-ci-pipeline-" + strategy
+}
+
+// serviceCIPipelineName returns the name of the AppCIPipeline for a service
+// with tests.
+func serviceCIPipelineName(env *config.Environment, svc *config.Service) string {
+	return fmt.Sprintf("app-ci-pipeline-%s-%s", env.Name, svc.Name)
+}
+
+func getAppCIPipelinePath(cicdPath, name string) string {
+	return filepath.ToSlash(filepath.Join(cicdPath, "base", "04-pipelines", name+".yaml"))
+}
+
+func getUpdateGitOpsTaskPath(cicdPath string) string {
+	return filepath.ToSlash(filepath.Join(cicdPath, "base", updateGitOpsTaskPath))
+}
+
+func getBindingPath(cicdPath, name string) string {
+	return filepath.ToSlash(filepath.Join(cicdPath, "base", bindingsPath, name+".yaml"))
+}
+
 func getEventListenerPath(cicdPath string) string {
 	return filepath.ToSlash(filepath.Join(cicdPath, "base", eventListenerPath))
 }
@@ -66,35 +303,78 @@ func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
 	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
-	triggers = append(triggers, ciTrigger)
+	prTrigger := repo.CreatePullRequestTrigger("ci-dryrun-from-pr", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-push-template", []string{repo.PullRequestBindingName()})
+	triggers = append(triggers, ciTrigger, prTrigger)
 	return triggers, nil
 }
 
 func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository) *config.Pipelines {
 	pipelines := defaultPipelines(r)
 	if env.Pipelines != nil {
-		pipelines = clonePipelines(env.Pipelines)
+		envPipelines := clonePipelines(env.Pipelines)
+		if envPipelines.Integration != nil {
+			pipelines.Integration = envPipelines.Integration
+		}
+		pipelines.Tests = envPipelines.Tests
+		pipelines.UpdateGitOps = envPipelines.UpdateGitOps
+		pipelines.Workspaces = envPipelines.Workspaces
 	}
 	if svc.Pipelines != nil {
-		if len(svc.Pipelines.Integration.Bindings) > 0 {
-			pipelines.Integration.Bindings = svc.Pipelines.Integration.Bindings
+		if svc.Pipelines.Integration != nil {
+			if len(svc.Pipelines.Integration.Bindings) > 0 {
+				pipelines.Integration.Bindings = svc.Pipelines.Integration.Bindings
+			}
+			if svc.Pipelines.Integration.Template != "" {
+				pipelines.Integration.Template = svc.Pipelines.Integration.Template
+			}
+		}
+		if len(svc.Pipelines.Tests) > 0 {
+			pipelines.Tests = svc.Pipelines.Tests
 		}
-		if svc.Pipelines.Integration.Template != "" {
-			pipelines.Integration.Template = svc.Pipelines.Integration.Template
+		if svc.Pipelines.UpdateGitOps != nil {
+			pipelines.UpdateGitOps = svc.Pipelines.UpdateGitOps
 		}
+		pipelines.Workspaces = mergeWorkspaces(pipelines.Workspaces, svc.Pipelines.Workspaces)
 	}
 	return pipelines
 }
 
 func clonePipelines(p *config.Pipelines) *config.Pipelines {
-	return &config.Pipelines{
-		Integration: &config.TemplateBinding{
+	cloned := &config.Pipelines{Tests: p.Tests, UpdateGitOps: p.UpdateGitOps, Workspaces: p.Workspaces}
+	if p.Integration != nil {
+		cloned.Integration = &config.TemplateBinding{
 			Bindings: p.Integration.Bindings,
 			Template: p.Integration.Template,
-		},
+		}
 	}
+	return cloned
 }
 
 func triggerName(svc string) string {
 	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
 }
+
+func promoteTriggerName(svc string) string {
+	return fmt.Sprintf("app-ci-promote-from-push-%s", svc)
+}
+
+func pullRequestTriggerName(svc string) string {
+	return fmt.Sprintf("app-ci-build-from-pr-%s", svc)
+}
+
+// pullRequestBindings replaces the repository's push binding with the pull
+// request binding, other bindings e.g. for the image repository are kept.
+func pullRequestBindings(r scm.Repository, bindings []string) []string {
+	prBindings := make([]string, len(bindings))
+	for i, b := range bindings {
+		if b == r.PushBindingName() {
+			b = r.PullRequestBindingName()
+		}
+		prBindings[i] = b
+	}
+	return prBindings
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -6,11 +6,20 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/catalog"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	tektonpipelines "github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const testRepoName = "http://github.com/org/gitops.git"
@@ -31,14 +40,426 @@ func TestBuildEventListener(t *testing.T) {
 	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
 	got, err := buildEventListenerResources(testRepoName, m)
 	assertNoError(t, err)
-	want := res.Resources{
+	want := res.Merge(res.Merge(testCICDResources(t, "test-cicd", testRepoName), testCatalogTasks(t, "test-cicd", "buildah", "git-clone")), res.Resources{
 		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, testRepoName)),
+	})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
 	}
+}
+
+func TestBuildEventListenerWithSourcePath(t *testing.T) {
+	svc := testService()
+	svc.SourcePath = "./services/api/"
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	cicdTriggers, err := createTriggersForCICD(testRepoName, m.GetPipelinesConfig())
+	assertNoError(t, err)
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	pipelines := getPipelines(m.Environments[0], svc, repo)
+	sourcePath := "services/api"
+	ciTrigger := repo.CreatePushTriggerForPath("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings, sourcePath)
+	ciTrigger.Bindings = append(ciTrigger.Bindings, &triggersv1.EventListenerBinding{Name: "contextpath", Value: &sourcePath})
+	want := res.Merge(res.Merge(testCICDResources(t, "test-cicd", testRepoName), testCatalogTasks(t, "test-cicd", "buildah", "git-clone")), res.Resources{
+		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, append(cicdTriggers, ciTrigger)),
+	})
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("resources didn't match:%s\n", diff)
 	}
 }
 
+func TestBuildEventListenerWithTriggers(t *testing.T) {
+	svc := testService()
+	svc.Triggers = &config.Triggers{
+		Branches: &config.BranchFilter{Include: []string{"main"}, Exclude: []string{"main-old"}},
+		Tags:     "v*",
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	pipelines := getPipelines(m.Environments[0], svc, repo)
+	want := repo.CreateFilteredPushTrigger("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings, scm.PushFilter{
+		Branches:        []string{"main"},
+		ExcludeBranches: []string{"main-old"},
+		Tags:            "v*",
+	})
+	el := got[getEventListenerPath("config/test-cicd")].(*triggersv1.EventListener)
+	if diff := cmp.Diff(want, el.Spec.Triggers[2]); diff != "" {
+		t.Fatalf("push trigger didn't match:%s\n", diff)
+	}
+	wantPR := repo.CreateFilteredPullRequestTrigger("app-ci-build-from-pr-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings, scm.PullRequestFilter{
+		Branches:        []string{"main"},
+		ExcludeBranches: []string{"main-old"},
+	})
+	if diff := cmp.Diff(wantPR, el.Spec.Triggers[3]); diff != "" {
+		t.Fatalf("pull request trigger didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithServiceOnAnotherDriver(t *testing.T) {
+	svc := testService()
+	svc.SourceURL = "https://gitlab.com/org/test.git"
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	for k, v := range testCICDResources(t, "test-cicd", testRepoName, svc.SourceURL) {
+		if diff := cmp.Diff(v, got[k]); diff != "" {
+			t.Fatalf("binding %s didn't match:%s\n", k, diff)
+		}
+	}
+}
+
+func TestPushFilter(t *testing.T) {
+	filterTests := []struct {
+		desc       string
+		sourcePath string
+		triggers   *config.Triggers
+		want       scm.PushFilter
+	}{
+		{
+			"no triggers",
+			"services/api",
+			nil,
+			scm.PushFilter{Path: "services/api"},
+		},
+		{
+			"only tags",
+			"",
+			&config.Triggers{Tags: "v*"},
+			scm.PushFilter{Tags: "v*"},
+		},
+		{
+			"branches and tags",
+			"services/api",
+			&config.Triggers{
+				Branches: &config.BranchFilter{Include: []string{"main", "release/**"}, Exclude: []string{"release/old"}},
+				Tags:     "v*",
+			},
+			scm.PushFilter{
+				Path:            "services/api",
+				Branches:        []string{"main", "release/**"},
+				ExcludeBranches: []string{"release/old"},
+				Tags:            "v*",
+			},
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, pushFilter(tt.sourcePath, tt.triggers)); diff != "" {
+				rt.Fatalf("pushFilter() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildEventListenerWithBuildStrategy(t *testing.T) {
+	svc := testService()
+	svc.Build = &config.Build{
+		Strategy:     "s2i",
+		BuilderImage: "registry.access.redhat.com/ubi8/nodejs-14",
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	cicdTriggers, err := createTriggersForCICD(testRepoName, m.GetPipelinesConfig())
+	assertNoError(t, err)
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	pipelines := getPipelines(m.Environments[0], svc, repo)
+	builderImage := "registry.access.redhat.com/ubi8/nodejs-14"
+	buildPipeline := "app-ci-pipeline-s2i"
+	bindings := []*triggersv1.EventListenerBinding{
+		{Name: "buildpipeline", Value: &buildPipeline},
+		{Name: "builderimage", Value: &builderImage},
+	}
+	ciTrigger := repo.CreatePushTrigger("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings)
+	ciTrigger.Bindings = append(ciTrigger.Bindings, bindings...)
+	prTrigger := repo.CreatePullRequestTrigger("app-ci-build-from-pr-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings)
+	prTrigger.Bindings = append(prTrigger.Bindings, bindings...)
+	want := res.Merge(res.Merge(testCICDResources(t, "test-cicd", testRepoName), testCatalogTasks(t, "test-cicd", "buildah", "git-clone", "s2i")), res.Resources{
+		getEventListenerPath(cicdPath):                                eventlisteners.CreateELFromTriggers("test-cicd", saName, append(cicdTriggers, ciTrigger, prTrigger)),
+		"config/test-cicd/base/04-pipelines/app-ci-pipeline-s2i.yaml": tektonpipelines.CreateS2IAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-s2i")),
+	})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+	wantVersions := map[string]string{"buildah": "0.2", "git-clone": "0.4", "s2i": "0.2"}
+	if diff := cmp.Diff(wantVersions, m.GetPipelinesConfig().CatalogTasks); diff != "" {
+		t.Fatalf("catalog task versions didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithTests(t *testing.T) {
+	tests := []*config.TestStep{
+		{Name: "unit", Image: "golang:1.16", Script: "go test ./..."},
+	}
+	svc := testService()
+	svc.Build = &config.Build{Strategy: "kaniko"}
+	env := testEnv(svc, "dev")
+	env.Pipelines.Tests = tests
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	want := tektonpipelines.CreateKanikoAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-dev-test-svc"), tests...)
+	if diff := cmp.Diff(want, got["config/test-cicd/base/04-pipelines/app-ci-pipeline-test-dev-test-svc.yaml"]); diff != "" {
+		t.Fatalf("service pipeline didn't match:%s\n", diff)
+	}
+	if _, ok := got["config/test-cicd/base/04-pipelines/app-ci-pipeline-kaniko.yaml"]; ok {
+		t.Fatal("the kaniko pipeline should not be generated for a service with tests")
+	}
+	el := got[getEventListenerPath("config/test-cicd")].(*triggersv1.EventListener)
+	for _, trigger := range el.Spec.Triggers[2:] {
+		binding := trigger.Bindings[len(trigger.Bindings)-1]
+		if binding.Name != "buildpipeline" || *binding.Value != "app-ci-pipeline-test-dev-test-svc" {
+			t.Fatalf("trigger %s does not use the service pipeline: %#v", trigger.Name, binding)
+		}
+	}
+}
+
+func TestBuildEventListenerWithKanikoBuildArgs(t *testing.T) {
+	svc := testService()
+	svc.Build = &config.Build{Strategy: "kaniko", BuildArgs: map[string]string{"VERSION": "1.0", "APP": "api"}}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{testEnv(svc, "dev")},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	want := tektonpipelines.CreateKanikoAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-dev-test-svc"))
+	tektonpipelines.SetKanikoExtraArgs(want, []string{"--build-arg=APP=api", "--build-arg=VERSION=1.0"})
+	if diff := cmp.Diff(want, got["config/test-cicd/base/04-pipelines/app-ci-pipeline-test-dev-test-svc.yaml"]); diff != "" {
+		t.Fatalf("service pipeline didn't match:%s\n", diff)
+	}
+	if _, ok := got["config/test-cicd/base/04-pipelines/app-ci-pipeline-kaniko.yaml"]; ok {
+		t.Fatal("the kaniko pipeline should not be generated for a service with build args")
+	}
+}
+
+func TestBuildEventListenerWithUpdateGitOps(t *testing.T) {
+	svc := testService()
+	env := testEnv(svc, "dev")
+	env.Pipelines.UpdateGitOps = &config.UpdateGitOps{Branch: "production"}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	want := tektonpipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-dev-test-svc-promote"))
+	tektonpipelines.AddUpdateGitOpsTask(want, testRepoName, "test-dev", "test-svc", env.Pipelines.UpdateGitOps)
+	if diff := cmp.Diff(want, got["config/test-cicd/base/04-pipelines/app-ci-pipeline-test-dev-test-svc-promote.yaml"]); diff != "" {
+		t.Fatalf("promotion pipeline didn't match:%s\n", diff)
+	}
+	if diff := cmp.Diff(tasks.CreateUpdateGitOpsTask("test-cicd"), got["config/test-cicd/base/03-tasks/update-gitops-task.yaml"]); diff != "" {
+		t.Fatalf("update-gitops task didn't match:%s\n", diff)
+	}
+	el := got[getEventListenerPath("config/test-cicd")].(*triggersv1.EventListener)
+	promoteTrigger, ciTrigger, prTrigger := el.Spec.Triggers[2], el.Spec.Triggers[3], el.Spec.Triggers[4]
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	pipelines := getPipelines(env, svc, repo)
+	wantPromote := repo.CreateFilteredPushTrigger("app-ci-promote-from-push-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings, scm.PushFilter{DefaultBranch: boolPtr(true)})
+	promotePipeline := "app-ci-pipeline-test-dev-test-svc-promote"
+	wantPromote.Bindings = append(wantPromote.Bindings, &triggersv1.EventListenerBinding{Name: "buildpipeline", Value: &promotePipeline})
+	if diff := cmp.Diff(wantPromote, promoteTrigger); diff != "" {
+		t.Fatalf("promotion trigger didn't match:%s\n", diff)
+	}
+	wantCI := repo.CreateFilteredPushTrigger("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", pipelines.Integration.Template, pipelines.Integration.Bindings, scm.PushFilter{DefaultBranch: boolPtr(false)})
+	if diff := cmp.Diff(wantCI, ciTrigger); diff != "" {
+		t.Fatalf("push trigger didn't match:%s\n", diff)
+	}
+	for _, b := range prTrigger.Bindings {
+		if b.Name == "buildpipeline" {
+			t.Fatalf("trigger %s should use the default pipeline: %#v", prTrigger.Name, b)
+		}
+	}
+}
+
+func TestBuildEventListenerWithWorkspaces(t *testing.T) {
+	svc := testService()
+	svc.Pipelines = &config.Pipelines{
+		Workspaces: &config.Workspaces{
+			Source: &config.WorkspaceStorage{Size: "10Gi", StorageClass: "fast"},
+		},
+	}
+	env := testEnv(svc, "dev")
+	env.Pipelines = &config.Pipelines{
+		Workspaces: &config.Workspaces{
+			Cache: &config.WorkspaceStorage{ClaimName: "maven-cache"},
+		},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	storageClass := "fast"
+	want := triggers.CreateDevCIBuildPRTemplateWithWorkspaces("test-cicd", "app-ci-template-test-dev-test-svc", saName, []pipelinev1.WorkspaceBinding{
+		{
+			Name: "shared-data",
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: &storageClass,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{"storage": resource.MustParse("10Gi")},
+					},
+				},
+			},
+		},
+		{
+			Name:                  "build-cache",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "maven-cache"},
+		},
+	})
+	if diff := cmp.Diff(want, got["config/test-cicd/base/06-templates/app-ci-template-test-dev-test-svc.yaml"]); diff != "" {
+		t.Fatalf("service template didn't match:%s\n", diff)
+	}
+	el := got[getEventListenerPath("config/test-cicd")].(*triggersv1.EventListener)
+	for _, trigger := range el.Spec.Triggers[2:] {
+		if *trigger.Template.Ref != "app-ci-template-test-dev-test-svc" {
+			t.Fatalf("trigger %s does not use the service template: %s", trigger.Name, *trigger.Template.Ref)
+		}
+	}
+}
+
+func TestBuildEventListenerWithWorkspacesAndTemplate(t *testing.T) {
+	svc := testService()
+	env := testEnv(svc, "dev")
+	env.Pipelines.Workspaces = &config.Workspaces{
+		Source: &config.WorkspaceStorage{ClaimName: "source"},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	_, err := buildEventListenerResources(testRepoName, m)
+	test.AssertErrorMatch(t, "workspaces can't be configured for the template test-ci-template", err)
+}
+
+func TestBuildBindings(t *testing.T) {
+	bindingValues := func(bindings []*triggersv1.EventListenerBinding) map[string]string {
+		values := map[string]string{}
+		for _, b := range bindings {
+			values[b.Name] = *b.Value
+		}
+		return values
+	}
+	bindingTests := []struct {
+		name         string
+		sourcePath   string
+		build        *config.Build
+		pipelineName string
+		want         map[string]string
+	}{
+		{"no build", "", nil, "app-ci-pipeline", map[string]string{}},
+		{"source path", "services/api", nil, "app-ci-pipeline", map[string]string{"contextpath": "services/api"}},
+		{"buildah", "", &config.Build{Strategy: "buildah", Dockerfile: "Containerfile", BuildArgs: map[string]string{"VERSION": "1.0", "APP": "api"}}, "app-ci-pipeline",
+			map[string]string{"dockerfile": "Containerfile", "build_extra_args": "--build-arg=APP=api --build-arg=VERSION=1.0"}},
+		{"kaniko", "api", &config.Build{Strategy: "kaniko"}, "app-ci-pipeline-kaniko",
+			map[string]string{"contextpath": "api", "buildpipeline": "app-ci-pipeline-kaniko"}},
+		{"kaniko with build args", "", &config.Build{Strategy: "kaniko", BuildArgs: map[string]string{"VERSION": "1.0"}}, "app-ci-pipeline-dev-api",
+			map[string]string{"buildpipeline": "app-ci-pipeline-dev-api"}},
+		{"buildpacks", "", &config.Build{Strategy: "buildpacks", BuilderImage: "paketobuildpacks/builder:base"}, "app-ci-pipeline-buildpacks",
+			map[string]string{"builderimage": "paketobuildpacks/builder:base", "buildpipeline": "app-ci-pipeline-buildpacks"}},
+		{"tests", "", nil, "app-ci-pipeline-dev-api",
+			map[string]string{"buildpipeline": "app-ci-pipeline-dev-api"}},
+	}
+
+	for _, tt := range bindingTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got := bindingValues(buildBindings(tt.sourcePath, tt.build, tt.pipelineName))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("buildBindings() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	m := &config.Manifest{
 
@@ -55,9 +476,9 @@ func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	gitOpsRepo := "http://github.com/org/gitops.git"
 	got, err := buildEventListenerResources(gitOpsRepo, m)
 	assertNoError(t, err)
-	want := res.Resources{
+	want := res.Merge(res.Merge(testCICDResources(t, "test-cicd", testRepoName), testCatalogTasks(t, "test-cicd", "buildah", "git-clone")), res.Resources{
 		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, gitOpsRepo)),
-	}
+	})
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("resources didn't match:%s\n", diff)
 	}
@@ -81,6 +502,23 @@ func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 	}
 }
 
+func TestBuildEventListenerWithRecordedCatalogTaskVersion(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:         "test-cicd",
+				CatalogTasks: map[string]string{"git-clone": "0.1"},
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(testService(), "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	_, err := buildEventListenerResources(testRepoName, m)
+	test.AssertErrorMatch(t, `version "0.1" of task git-clone is not in the catalog`, err)
+}
+
 func TestGetPipelines(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -167,6 +605,75 @@ func TestGetPipelines(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Tests are provided by environment with default integration",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Tests: []*config.TestStep{{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"}},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "app-ci-template",
+					Bindings: []string{"github-push-binding"},
+				},
+				Tests: []*config.TestStep{{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"}},
+			},
+		},
+		{
+			"Override the tests in the service",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Integration: testPipelines("env").Integration,
+					Tests:       []*config.TestStep{{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"}},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Tests: []*config.TestStep{{Name: "unit", Image: "golang:1.16", Script: "go test ./..."}},
+				},
+			},
+			&config.Pipelines{
+				Integration: testPipelines("env").Integration,
+				Tests:       []*config.TestStep{{Name: "unit", Image: "golang:1.16", Script: "go test ./..."}},
+			},
+		},
+		{
+			"Override the source workspace in the service",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Workspaces: &config.Workspaces{
+						Source: &config.WorkspaceStorage{Size: "5Gi"},
+						Cache:  &config.WorkspaceStorage{ClaimName: "maven-cache"},
+					},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Workspaces: &config.Workspaces{
+						Source: &config.WorkspaceStorage{Size: "10Gi"},
+					},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "app-ci-template",
+					Bindings: []string{"github-push-binding"},
+				},
+				Workspaces: &config.Workspaces{
+					Source: &config.WorkspaceStorage{Size: "10Gi"},
+					Cache:  &config.WorkspaceStorage{ClaimName: "maven-cache"},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(rt *testing.T) {
@@ -186,6 +693,17 @@ func TestGetPipelines(t *testing.T) {
 	}
 }
 
+func TestPullRequestBindings(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/foo/bar")
+	assertNoError(t, err)
+
+	got := pullRequestBindings(repo, []string{"dev-app-svc-binding", "github-push-binding"})
+	want := []string{"dev-app-svc-binding", "github-pull-request-binding"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("pullRequestBindings() failed:\n%s", diff)
+	}
+}
+
 func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []triggersv1.EventListenerTrigger {
 	triggers := []triggersv1.EventListenerTrigger{}
 	cfg := m.GetPipelinesConfig()
@@ -198,7 +716,8 @@ func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []trigger
 		assertNoError(t, err)
 		pipelines := getPipelines(env, svc, repo)
 		devCITrigger := repo.CreatePushTrigger(fmt.Sprintf("app-ci-build-from-push-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
-		triggers = append(triggers, devCITrigger)
+		devPRTrigger := repo.CreatePullRequestTrigger(fmt.Sprintf("app-ci-build-from-pr-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+		triggers = append(triggers, devCITrigger, devPRTrigger)
 	}
 
 	return triggers
@@ -241,3 +760,36 @@ func testPipelines(name string) *config.Pipelines {
 		},
 	}
 }
+
+// testBindings returns the push and pull request TriggerBindings for the
+// drivers of the repositories.
+// testCICDResources returns the TriggerBindings for the repositories, and the
+// default AppCIPipeline and TriggerTemplate, that are generated for the CI/CD
+// environment.
+func testCICDResources(t *testing.T, ns string, repoURLs ...string) res.Resources {
+	t.Helper()
+	files := res.Resources{
+		filepath.ToSlash(filepath.Join("config", ns, "base", "04-pipelines", "app-ci-pipeline.yaml")):                 tektonpipelines.CreateAppCIPipeline(meta.NamespacedName(ns, "app-ci-pipeline")),
+		filepath.ToSlash(filepath.Join("config", ns, "base", "06-templates", "app-ci-build-from-push-template.yaml")): triggers.CreateDevCIBuildPRTemplate(ns, saName),
+	}
+	for _, u := range repoURLs {
+		repo, err := scm.NewRepository(u)
+		assertNoError(t, err)
+		pushBinding, pushBindingName := repo.CreatePushBinding(ns)
+		files[filepath.ToSlash(filepath.Join("config", ns, "base", "05-bindings", pushBindingName+".yaml"))] = pushBinding
+		prBinding, prBindingName := repo.CreatePullRequestBinding(ns)
+		files[filepath.ToSlash(filepath.Join("config", ns, "base", "05-bindings", prBindingName+".yaml"))] = prBinding
+	}
+	return files
+}
+
+func testCatalogTasks(t *testing.T, ns string, names ...string) res.Resources {
+	t.Helper()
+	files := res.Resources{}
+	for _, name := range names {
+		task, err := catalog.Task(name, catalog.PinnedVersion(name), ns)
+		assertNoError(t, err)
+		files[filepath.ToSlash(filepath.Join("config", ns, "base", catalogTaskPath(name)))] = task
+	}
+	return files
+}
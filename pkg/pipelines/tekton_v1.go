@@ -0,0 +1,103 @@
+package pipelines
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+const bindingsPath = "05-bindings"
+
+// buildTektonV1Resources creates the Tekton resources for the CI/CD
+// environment that are otherwise only created at bootstrap, these use
+// workspaces rather than PipelineResources, so that they can be converted to
+// tekton.dev/v1.
+//
+// The existing TriggerBindings are read from the CI/CD environment in the
+// GitOps repository in root, as the image repositories for the services are
+// not recorded in the manifest.
+func buildTektonV1Resources(fs afero.Fs, root string, m *config.Manifest) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || m.GitOpsURL == "" {
+		return res.Resources{}, nil
+	}
+	repo, err := scm.NewRepository(m.GitOpsURL)
+	if err != nil {
+		return nil, err
+	}
+	script, err := dryrun.MakeScript("kubectl", cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base"))
+	files, err := existingTriggerBindings(fs, filepath.Join(root, base))
+	if err != nil {
+		return nil, err
+	}
+	files[gitopsTasksPath] = tasks.CreateDeployFromSourceTaskWithWorkspace(cfg.Name, script)
+	files[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cfg.Name)
+	files[ciPipelinesPath] = pipelines.CreateCIPipelineWithWorkspace(meta.NamespacedName(cfg.Name, "ci-dryrun-from-push-pipeline"), cfg.Name)
+	files[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cfg.Name, "app-ci-pipeline"))
+	pushBinding, pushBindingName := repo.CreatePushBinding(cfg.Name)
+	files[filepath.ToSlash(filepath.Join(bindingsPath, pushBindingName+".yaml"))] = pushBinding
+	prBinding, prBindingName := repo.CreatePullRequestBinding(cfg.Name)
+	files[filepath.ToSlash(filepath.Join(bindingsPath, prBindingName+".yaml"))] = prBinding
+	files[pushTemplatePath] = triggers.CreateCIDryRunTemplateWithWorkspace(cfg.Name, saName)
+	files[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cfg.Name, saName)
+
+	prefixed := res.Resources{}
+	for k, v := range files {
+		prefixed[filepath.ToSlash(filepath.Join(base, k))] = v
+	}
+	return prefixed, nil
+}
+
+// existingTriggerBindings reads the TriggerBindings in the CI/CD environment,
+// these are either in the bindings folder, or for the images of services, in
+// the base of the environment.
+//
+// The returned paths are relative to the base of the environment.
+func existingTriggerBindings(fs afero.Fs, base string) (res.Resources, error) {
+	bindings := res.Resources{}
+	for _, dir := range []string{"", bindingsPath} {
+		infos, err := afero.ReadDir(fs, filepath.Join(base, dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.IsDir() || filepath.Ext(info.Name()) != ".yaml" {
+				continue
+			}
+			filename := filepath.Join(base, dir, info.Name())
+			var typeMeta metav1.TypeMeta
+			if err := yaml.UnmarshalItemFromFile(fs, filename, &typeMeta); err != nil {
+				return nil, err
+			}
+			if typeMeta.Kind != "TriggerBinding" {
+				continue
+			}
+			var binding triggersv1.TriggerBinding
+			if err := yaml.UnmarshalItemFromFile(fs, filename, &binding); err != nil {
+				return nil, err
+			}
+			bindings[filepath.ToSlash(filepath.Join(dir, info.Name()))] = binding
+		}
+	}
+	return bindings, nil
+}
@@ -26,36 +26,49 @@ func createDevCDPipelineRun(saName string) pipelinev1.PipelineRun {
 }
 
 func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
+	return createDevCIPipelineRunWithWorkspaces(saName, []pipelinev1.WorkspaceBinding{createSharedDataWorkspace()})
+}
+
+// createDevCIPipelineRunWithWorkspaces creates the PipelineRun for the
+// AppCIPipelines with the provided workspace bindings.
+func createDevCIPipelineRunWithWorkspaces(saName string, workspaces []pipelinev1.WorkspaceBinding) pipelinev1.PipelineRun {
 	return pipelinev1.PipelineRun{
 		TypeMeta: pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
 			meta.NamespacedName("", "app-ci-$(uid)")),
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
-			PipelineRef:        createPipelineRef("app-ci-pipeline"),
+			PipelineRef:        createPipelineRef("$(tt.params." + BuildPipeline + ")"),
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
 				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
-				createPipelineBindingParam("BUILD_EXTRA_ARGS", "$(tt.params.build_extra_args)"),
-				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+GitRef+")-$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("BUILD_EXTRA_ARGS", "$(tt.params."+BuildExtraArgs+")"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+ImageTag+")-$(tt.params."+GitCommitID+")"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params."+GitCommitID+")"),
 				createPipelineBindingParam("GIT_REF", "$(tt.params."+GitRef+")"),
 				createPipelineBindingParam("COMMIT_DATE", "$(tt.params."+GitCommitDate+")"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params."+GitCommitAuthor+")"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(tt.params."+GitCommitMessage+")"),
+				createPipelineBindingParam("CONTEXT", "$(tt.params."+ContextPath+")"),
+				createPipelineBindingParam("DOCKERFILE", "$(tt.params."+ContextPath+")/$(tt.params."+Dockerfile+")"),
+				createPipelineBindingParam("BUILDER_IMAGE", "$(tt.params."+BuilderImage+")"),
 			},
-			Workspaces: []pipelinev1.WorkspaceBinding{
-				{
-					Name: "shared-data",
-					VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
-						Spec: corev1.PersistentVolumeClaimSpec{
-							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
-							},
-						},
-					},
+			Workspaces: workspaces,
+		},
+	}
+}
+
+// createSharedDataWorkspace creates the workspace that the source is cloned
+// into, a volume is claimed for each PipelineRun.
+func createSharedDataWorkspace() pipelinev1.WorkspaceBinding {
+	return pipelinev1.WorkspaceBinding{
+		Name: "shared-data",
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
 				},
 			},
 		},
@@ -92,6 +105,26 @@ func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
 	}
 }
 
+func createCIPipelineRunWithWorkspace(saName string) pipelinev1.PipelineRun {
+	return pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "ci-dryrun-from-push-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: saName,
+			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createSharedDataWorkspace(),
+			},
+		},
+	}
+}
+
 func createDevResource(revision string) []pipelinev1.PipelineResourceBinding {
 	return []pipelinev1.PipelineResourceBinding{
 		{
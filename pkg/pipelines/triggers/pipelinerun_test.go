@@ -40,7 +40,7 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 			meta.NamespacedName("", "app-ci-$(uid)")),
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: sName,
-			PipelineRef:        createPipelineRef("app-ci-pipeline"),
+			PipelineRef:        createPipelineRef("$(tt.params.buildpipeline)"),
 			Workspaces: []pipelinev1.WorkspaceBinding{
 				{
 					Name: "shared-data",
@@ -59,12 +59,15 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
 				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
 				createPipelineBindingParam("BUILD_EXTRA_ARGS", "$(tt.params.build_extra_args)"),
-				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+GitRef+")-$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params.imagetag)-$(tt.params."+GitCommitID+")"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
 				createPipelineBindingParam("GIT_REF", "$(tt.params.io.openshift.build.commit.ref)"),
 				createPipelineBindingParam("COMMIT_DATE", "$(tt.params.io.openshift.build.commit.date)"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params.io.openshift.build.commit.author)"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(tt.params.io.openshift.build.commit.message)"),
+				createPipelineBindingParam("CONTEXT", "$(tt.params.contextpath)"),
+				createPipelineBindingParam("DOCKERFILE", "$(tt.params.contextpath)/$(tt.params.dockerfile)"),
+				createPipelineBindingParam("BUILDER_IMAGE", "$(tt.params.builderimage)"),
 			},
 		},
 	}
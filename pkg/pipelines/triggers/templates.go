@@ -3,6 +3,7 @@ package triggers
 import (
 	"encoding/json"
 
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -16,6 +17,12 @@ var (
 const (
 	// GitRef is a label representing the source-ref for this build.
 	GitRef = "io.openshift.build.commit.ref"
+	// GitRefType is a parameter representing whether the source-ref for this
+	// build is a "branch" or a "tag".
+	GitRefType = "io.openshift.build.commit.ref_type"
+	// ImageTag is a parameter representing the source-ref for this build,
+	// sanitised for use in an image tag.
+	ImageTag = "imagetag"
 	// GitCommitID is a label representing the commit SHA for this build.
 	GitCommitID = "io.openshift.build.commit.id"
 	// GitCommitAuthor is a label representing the commit author for this build.
@@ -26,6 +33,24 @@ const (
 	// GitCommitDate is a label representing the commit timestamp for this
 	// build.
 	GitCommitDate = "io.openshift.build.commit.date"
+	// PullRequestNumber is a parameter representing the pull request, or
+	// merge request, that triggered this build.
+	PullRequestNumber = "pullrequestnumber"
+	// ContextPath is a parameter representing the directory within the source
+	// repository that is built.
+	ContextPath = "contextpath"
+	// Dockerfile is a parameter representing the path to the Dockerfile,
+	// relative to the ContextPath.
+	Dockerfile = "dockerfile"
+	// BuilderImage is a parameter representing the builder image for
+	// strategies that build images from source.
+	BuilderImage = "builderimage"
+	// BuildExtraArgs is a parameter representing the extra arguments passed
+	// to the image build.
+	BuildExtraArgs = "build_extra_args"
+	// BuildPipeline is a parameter representing the name of the Pipeline that
+	// builds the image.
+	BuildPipeline = "buildpipeline"
 )
 
 // GenerateTemplates will return a slice of trigger templates
@@ -68,6 +93,8 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
 				createTemplateParamSpec(GitRef, "The git branch for this PR."),
+				createTemplateParamSpecDefault(GitRefType, "Whether the git ref is a branch or a tag.", "branch"),
+				createTemplateParamSpecDefault(ImageTag, "The git ref sanitised for use in an image tag.", "latest"),
 				createTemplateParamSpec(GitCommitID, "the specific commit SHA."),
 				createTemplateParamSpec(GitCommitDate, "The date at which the commit was made"),
 				createTemplateParamSpec(GitCommitAuthor, "The name of the github user handle that made the commit"),
@@ -76,7 +103,11 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpec("fullname", "The repository name for this PullRequest."),
 				createTemplateParamSpec("imageRepo", "The repository to push built images to."),
 				createTemplateParamSpec("tlsVerify", "Enable image repository TLS certification verification."),
-				createTemplateParamSpec("build_extra_args", "Extra parameters passed for the push command when pushing images."),
+				createTemplateParamSpecDefault(BuildExtraArgs, "Extra parameters passed for the push command when pushing images.", ""),
+				createTemplateParamSpecDefault(ContextPath, "The path within the repository to build.", "."),
+				createTemplateParamSpecDefault(Dockerfile, "The path to the Dockerfile within the context path.", "Dockerfile"),
+				createTemplateParamSpecDefault(BuilderImage, "The builder image for source to image builds.", ""),
+				createTemplateParamSpecDefault(BuildPipeline, "The Pipeline that builds the image.", "app-ci-pipeline"),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -89,6 +120,23 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	}
 }
 
+// CreateDevCIBuildPRTemplateWithWorkspaces creates a DevCIBuildPRTemplate
+// with the provided name, the workspaces of the PipelineRun are bound to the
+// provided storage, rather than a volume that is claimed for each
+// PipelineRun.
+func CreateDevCIBuildPRTemplateWithWorkspaces(ns, name, saName string, workspaces []pipelinev1.WorkspaceBinding) triggersv1.TriggerTemplate {
+	template := CreateDevCIBuildPRTemplate(ns, saName)
+	template.Name = name
+	template.Spec.ResourceTemplates = []triggersv1.TriggerResourceTemplate{
+		{
+			RawExtension: runtime.RawExtension{
+				Raw: createDevCIResourceTemplateWithWorkspaces(saName, workspaces),
+			},
+		},
+	}
+	return template
+}
+
 // CreateCDPushTemplate returns TriggerTemplate for CD Push Request
 func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
@@ -137,6 +185,20 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	}
 }
 
+// CreateCIDryRunTemplateWithWorkspace returns TriggerTemplate for CI Dry Run,
+// the source is cloned into a workspace, rather than a git PipelineResource.
+func CreateCIDryRunTemplateWithWorkspace(ns, saName string) triggersv1.TriggerTemplate {
+	template := CreateCIDryRunTemplate(ns, saName)
+	template.Spec.ResourceTemplates = []triggersv1.TriggerResourceTemplate{
+		{
+			RawExtension: runtime.RawExtension{
+				Raw: createCIResourceTemplateWithWorkspace(saName),
+			},
+		},
+	}
+	return template
+}
+
 func createTemplateParamSpecDefault(name, description, value string) triggersv1.ParamSpec {
 	return triggersv1.ParamSpec{
 		Name:        name,
@@ -162,6 +224,11 @@ func createDevCIResourceTemplate(saName string) []byte {
 	return byteTemplateCI
 }
 
+func createDevCIResourceTemplateWithWorkspaces(saName string, workspaces []pipelinev1.WorkspaceBinding) []byte {
+	byteTemplateCI, _ := json.Marshal(createDevCIPipelineRunWithWorkspaces(saName, workspaces))
+	return byteTemplateCI
+}
+
 func createCDResourceTemplate(saName string) []byte {
 	byteStageCD, _ := json.Marshal(createCDPipelineRun(saName))
 	return byteStageCD
@@ -172,6 +239,11 @@ func createCIResourceTemplate(saName string) []byte {
 	return byteStageCI
 }
 
+func createCIResourceTemplateWithWorkspace(saName string) []byte {
+	byteStageCI, _ := json.Marshal(createCIPipelineRunWithWorkspace(saName))
+	return byteStageCI
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -1,10 +1,13 @@
 package triggers
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
@@ -56,6 +59,16 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Name:        GitRef,
 					Description: "The git branch for this PR.",
 				},
+				{
+					Name:        GitRefType,
+					Description: "Whether the git ref is a branch or a tag.",
+					Default:     strPtr("branch"),
+				},
+				{
+					Name:        ImageTag,
+					Description: "The git ref sanitised for use in an image tag.",
+					Default:     strPtr("latest"),
+				},
 				{
 					Name:        GitCommitID,
 					Description: "the specific commit SHA.",
@@ -89,8 +102,29 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Description: "Enable image repository TLS certification verification.",
 				},
 				{
-					Name:        "build_extra_args",
+					Name:        BuildExtraArgs,
 					Description: "Extra parameters passed for the push command when pushing images.",
+					Default:     strPtr(""),
+				},
+				{
+					Name:        ContextPath,
+					Description: "The path within the repository to build.",
+					Default:     strPtr("."),
+				},
+				{
+					Name:        Dockerfile,
+					Description: "The path to the Dockerfile within the context path.",
+					Default:     strPtr("Dockerfile"),
+				},
+				{
+					Name:        BuilderImage,
+					Description: "The builder image for source to image builds.",
+					Default:     strPtr(""),
+				},
+				{
+					Name:        BuildPipeline,
+					Description: "The Pipeline that builds the image.",
+					Default:     strPtr("app-ci-pipeline"),
 				},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
@@ -108,6 +142,30 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 	}
 }
 
+func TestCreateDevCIBuildPRTemplateWithWorkspaces(t *testing.T) {
+	workspaces := []pipelinev1.WorkspaceBinding{
+		{
+			Name:                  "shared-data",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "source"},
+		},
+	}
+	template := CreateDevCIBuildPRTemplateWithWorkspaces("testns", "app-ci-template-dev-api", serviceAccName, workspaces)
+
+	if template.Name != "app-ci-template-dev-api" {
+		t.Fatalf("CreateDevCIBuildPRTemplateWithWorkspaces() got name %s, want app-ci-template-dev-api", template.Name)
+	}
+	var run pipelinev1.PipelineRun
+	if err := json.Unmarshal(template.Spec.ResourceTemplates[0].Raw, &run); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(workspaces, run.Spec.Workspaces); diff != "" {
+		t.Fatalf("CreateDevCIBuildPRTemplateWithWorkspaces() failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(CreateDevCIBuildPRTemplate("testns", serviceAccName).Spec.Params, template.Spec.Params); diff != "" {
+		t.Fatalf("CreateDevCIBuildPRTemplateWithWorkspaces() params failed:\n%s", diff)
+	}
+}
+
 func TestCreateCDPushTemplate(t *testing.T) {
 	ValidStageCDPushTemplate := triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
@@ -0,0 +1,201 @@
+package pipelines
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+const (
+	defaultGitOpsBranch = "main"
+	commitAuthorName    = "kam"
+	commitAuthorEmail   = "kam@localhost"
+)
+
+var invalidBranchChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// UpdateImageOptions control how the image of a service is updated in the
+// GitOps repository.
+type UpdateImageOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+	ServiceName         string
+	// Image is the new image for the service e.g. quay.io/org/app:main-a1b2c3
+	Image string
+	// ImageName is the name of the image in the service's resources that is
+	// replaced, this defaults to the name of the Image without the tag.
+	ImageName string
+
+	// The following options are used to update a remote GitOps repository,
+	// which is cloned into the PipelinesFolderPath.
+	GitOpsRepoURL      string
+	GitOpsBranch       string
+	PullRequest        bool
+	GitHostAccessToken string
+}
+
+// UpdateImage sets the image for a service with a Kustomize image override
+// in the service's overlay.
+func UpdateImage(o *UpdateImageOptions, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	env := m.GetEnvironment(o.EnvName)
+	if env == nil {
+		return fmt.Errorf("environment %s does not exist", o.EnvName)
+	}
+	app := findApplicationForService(env, o.ServiceName)
+	if app == nil {
+		return fmt.Errorf("service %s does not exist in environment %s", o.ServiceName, o.EnvName)
+	}
+	overlay := filepath.Join(o.PipelinesFolderPath, config.PathForService(app, env, o.ServiceName), "overlays", Kustomize)
+	var k res.Kustomization
+	if err := yaml.UnmarshalItemFromFile(appFs, overlay, &k); err != nil {
+		return err
+	}
+	image := res.ParseImage(o.Image)
+	if o.ImageName != "" {
+		image.NewName = image.Name
+		image.Name = o.ImageName
+	}
+	k.SetImage(image)
+	return yaml.MarshalItemToFile(appFs, overlay, k)
+}
+
+// PromoteImage clones the GitOps repository, updates the image for a service,
+// and pushes the change to the GitOps branch, or opens a pull request for it.
+func PromoteImage(o *UpdateImageOptions, f clientFactory, e executor, appFs afero.Fs) error {
+	branch := o.GitOpsBranch
+	if branch == "" {
+		branch = defaultGitOpsBranch
+	}
+	remote, err := giturl.Normalize(o.GitOpsRepoURL)
+	if err != nil {
+		return err
+	}
+	auth, err := gitAuthArgs(f, remote, o.GitHostAccessToken)
+	if err != nil {
+		return err
+	}
+	if out, err := e.execute("", "git", append(auth, "clone", "--depth", "1", "--branch", branch, remote, o.PipelinesFolderPath)...); err != nil {
+		return fmt.Errorf("failed to clone %q %q: %s", remote, string(out), err)
+	}
+	if err := UpdateImage(o, appFs); err != nil {
+		return err
+	}
+	out, err := e.execute(o.PipelinesFolderPath, "git", "status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to get the status of the repository in %q %q: %s", o.PipelinesFolderPath, string(out), err)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		log.Progressf("The image for %s in %s is already %s", o.ServiceName, o.EnvName, o.Image)
+		return nil
+	}
+	message := fmt.Sprintf("Update the image for %s in %s to %s", o.ServiceName, o.EnvName, o.Image)
+	if out, err := e.execute(o.PipelinesFolderPath, "git", "-c", "user.name="+commitAuthorName, "-c", "user.email="+commitAuthorEmail, "commit", "-a", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit files to repository in %q %q: %s", o.PipelinesFolderPath, string(out), err)
+	}
+	if !o.PullRequest {
+		if out, err := e.execute(o.PipelinesFolderPath, "git", append(auth, "push", "origin", "HEAD:"+branch)...); err != nil {
+			return fmt.Errorf("failed to push to %q in %q %q: %s", branch, remote, string(out), err)
+		}
+		return nil
+	}
+	head := promotionBranch(o.EnvName, o.ServiceName, o.Image)
+	if out, err := e.execute(o.PipelinesFolderPath, "git", append(auth, "push", "origin", "HEAD:refs/heads/"+head)...); err != nil {
+		return fmt.Errorf("failed to push to %q in %q %q: %s", head, remote, string(out), err)
+	}
+	return openPullRequest(f, remote, o.GitHostAccessToken, &scm.PullRequestInput{
+		Title: message,
+		Body:  "This pull request was opened by the update-gitops task after the image was built.",
+		Head:  head,
+		Base:  branch,
+	})
+}
+
+// gitAuthArgs returns the git configuration that authenticates the clone of,
+// and the push to, the GitOps repository with the access token, the token is
+// passed in a header, so that it isn't recorded in the remote URL.
+//
+// Bitbucket Server accepts access tokens as bearer tokens, the other drivers
+// accept them as the password for basic auth.
+func gitAuthArgs(f clientFactory, remote, token string) ([]string, error) {
+	if token == "" {
+		return []string{}, nil
+	}
+	client, err := newClient(f, remote, token)
+	if err != nil {
+		return nil, err
+	}
+	header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:"+token))
+	if client.Driver == scm.DriverStash {
+		header = "Authorization: Bearer " + token
+	}
+	return []string{"-c", "http.extraHeader=" + header}, nil
+}
+
+func newClient(f clientFactory, remote, token string) (*scm.Client, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitOps repo URL %q: %w", remote, err)
+	}
+	u.User = url.UserPassword("", token)
+	client, err := f(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client to access %q: %w", remote, err)
+	}
+	return client, nil
+}
+
+func openPullRequest(f clientFactory, remote, token string, input *scm.PullRequestInput) error {
+	repo, err := orgRepoFromURL(remote)
+	if err != nil {
+		return err
+	}
+	client, err := newClient(f, remote, token)
+	if err != nil {
+		return err
+	}
+	pr, _, err := client.PullRequests.Create(context.Background(), repo, input)
+	if err != nil {
+		return fmt.Errorf("failed to open a pull request in %q: %w", repo, err)
+	}
+	log.Successf("Opened pull request %s", pr.Link)
+	return nil
+}
+
+// promotionBranch returns the name of the branch for a pull request that
+// promotes the image.
+func promotionBranch(envName, serviceName, image string) string {
+	ref := res.ParseImage(image)
+	version := ref.NewTag
+	if version == "" {
+		version = ref.Digest
+	}
+	return "kam/" + invalidBranchChars.ReplaceAllString(fmt.Sprintf("%s-%s-%s", envName, serviceName, version), "-")
+}
+
+func findApplicationForService(env *config.Environment, serviceName string) *config.Application {
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name == serviceName {
+				return app
+			}
+		}
+	}
+	return nil
+}
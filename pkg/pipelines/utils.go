@@ -1,14 +1,21 @@
 package pipelines
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/afero"
 )
@@ -28,16 +35,19 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		return nil
 	}
 
-	u, err := url.Parse(o.GitOpsRepoURL)
+	normalized, err := giturl.Normalize(o.GitOpsRepoURL)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(normalized)
 	if err != nil {
 		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
 	}
-	parts := strings.Split(u.Path, "/")
-	org := parts[1]
-	repoName := strings.TrimSuffix(strings.Join(parts[2:], "/"), ".git")
-	u.User = url.UserPassword("", o.GitHostAccessToken)
-
-	client, err := f(u.String())
+	org, repoName, err := splitRepositoryPath(u)
+	if err != nil {
+		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
+	}
+	client, err := repositoryClient(f, u, o.GitHostAccessToken)
 	if err != nil {
 		return fmt.Errorf("failed to create a client to access %q: %w", o.GitOpsRepoURL, err)
 	}
@@ -59,7 +69,7 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		Namespace:   org,
 		Name:        repoName,
 	}
-	created, _, err := client.Repositories.Create(context.Background(), ri)
+	created, err := createRepository(ctx, client, ri)
 	if err != nil {
 		repo := fmt.Sprintf("%s/%s", org, repoName)
 		if org == "" {
@@ -76,6 +86,130 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 	return err
 }
 
+// splitRepositoryPath splits the path of a repository URL into the namespace
+// and the name of the repository, the namespace can be a nested GitLab group
+// e.g. "group/subgroup/team".
+//
+// Gitea and Bitbucket Server can be hosted on a base path, which is not part
+// of the namespace, nor is the /scm/ prefix of Bitbucket Server clone URLs.
+func splitRepositoryPath(u *url.URL) (string, string, error) {
+	if driver, err := factory.DefaultIdentifier.Identify(u.Host); err == nil && git.HasBasePath(driver) {
+		_, fullName, err := git.SplitServerURL(u, driver)
+		if err != nil {
+			return "", "", err
+		}
+		i := strings.LastIndex(fullName, "/")
+		return fullName[:i], fullName[i+1:], nil
+	}
+	var parts []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("failed to get the namespace and repository from %q", u.Path)
+	}
+	name := strings.TrimSuffix(parts[len(parts)-1], ".git")
+	return strings.Join(parts[:len(parts)-1], "/"), name, nil
+}
+
+// repositoryClient creates a client for the Git hosting service of the
+// repository.
+//
+// The client factory creates clients for the root of the host, so the clients
+// for Gitea and Bitbucket Server are created with the server URL, which
+// includes the base path.
+func repositoryClient(f clientFactory, u *url.URL, token string) (*scm.Client, error) {
+	driver, err := factory.DefaultIdentifier.Identify(u.Host)
+	if err != nil || !git.HasBasePath(driver) {
+		authenticated := *u
+		authenticated.User = url.UserPassword("", token)
+		return f(authenticated.String())
+	}
+	serverURL, _, err := git.SplitServerURL(u, driver)
+	if err != nil {
+		return nil, err
+	}
+	return factory.NewClient(driver, serverURL, token)
+}
+
+// createRepository creates the repository with the upstream Git hosting
+// service.
+//
+// go-scm resolves GitLab namespaces by searching for the name, which doesn't
+// find nested subgroups, so the namespace ID is looked up from the full path
+// and the project is created directly with the GitLab API.
+func createRepository(ctx context.Context, client *scm.Client, ri *scm.RepositoryInput) (*scm.Repository, error) {
+	if client.Driver != scm.DriverGitlab || ri.Namespace == "" {
+		created, _, err := client.Repositories.Create(ctx, ri)
+		return created, err
+	}
+	ns := struct {
+		ID int `json:"id"`
+	}{}
+	if err := gitlabRequest(ctx, client, "GET", "api/v4/namespaces/"+url.PathEscape(ri.Namespace), nil, &ns); err != nil {
+		return nil, fmt.Errorf("failed to find the namespace %q: %w", ri.Namespace, err)
+	}
+	visibility := "public"
+	if ri.Private {
+		visibility = "private"
+	}
+	in := map[string]interface{}{
+		"name":         ri.Name,
+		"path":         ri.Name,
+		"description":  ri.Description,
+		"namespace_id": ns.ID,
+		"visibility":   visibility,
+	}
+	out := struct {
+		Name          string `json:"path"`
+		FullName      string `json:"path_with_namespace"`
+		Link          string `json:"web_url"`
+		Clone         string `json:"http_url_to_repo"`
+		CloneSSH      string `json:"ssh_url_to_repo"`
+		DefaultBranch string `json:"default_branch"`
+	}{}
+	if err := gitlabRequest(ctx, client, "POST", "api/v4/projects", in, &out); err != nil {
+		return nil, err
+	}
+	return &scm.Repository{
+		Namespace: ri.Namespace,
+		Name:      out.Name,
+		FullName:  out.FullName,
+		Link:      out.Link,
+		Clone:     out.Clone,
+		CloneSSH:  out.CloneSSH,
+		Branch:    out.DefaultBranch,
+		Private:   ri.Private,
+	}, nil
+}
+
+func gitlabRequest(ctx context.Context, client *scm.Client, method, path string, in, out interface{}) error {
+	req := &scm.Request{Method: method, Path: path}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Header = http.Header{"Content-Type": []string{"application/json"}}
+		req.Body = bytes.NewReader(b)
+	}
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status >= 300 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", http.StatusText(res.Status), err)
+		}
+		return fmt.Errorf("%s: %s", http.StatusText(res.Status), strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
 func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.Fs) error {
 	if exists, _ := ioutils.IsExisting(appFs, filepath.Join(o.OutputPath, ".git")); exists {
 		if err := appFs.RemoveAll(filepath.Join(o.OutputPath, ".git")); err != nil {
@@ -103,8 +237,25 @@ func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.
 	return nil
 }
 
+// normalizeGitURLs replaces the Git URLs with their canonical form, so that
+// SSH URLs are recorded in the manifest as HTTPS URLs.
+func normalizeGitURLs(urls ...*string) error {
+	for _, u := range urls {
+		normalized, err := giturl.Normalize(*u)
+		if err != nil {
+			return err
+		}
+		*u = normalized
+	}
+	return nil
+}
+
 func repoURL(u string) (string, error) {
-	parsed, err := url.Parse(u)
+	normalized, err := giturl.Normalize(u)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := url.Parse(normalized)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse %q: %w", u, err)
 	}
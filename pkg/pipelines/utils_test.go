@@ -2,7 +2,12 @@ package pipelines
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -12,6 +17,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/test"
 )
@@ -53,6 +59,95 @@ func TestBootstrapRepository_with_org(t *testing.T) {
 	assertRepositoryCreated(t, fakeData, "testing", "test-repo")
 }
 
+func TestBootstrapRepository_with_nested_namespace(t *testing.T) {
+	token := "this-is-a-test-token"
+	factory, fakeData := newMockClientFactory(t, token)
+	fakeData.CurrentUser = scm.User{Login: "test-user"}
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "https://example.com/group/subgroup/team/test-repo.git",
+			GitHostAccessToken: token,
+		},
+		factory,
+		newMockExecutor(),
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+	assertRepositoryCreated(t, fakeData, "group/subgroup/team", "test-repo")
+}
+
+func TestBootstrapRepository_with_gitlab_subgroup(t *testing.T) {
+	var created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			fmt.Fprint(w, `{"id": 1, "username": "test-user"}`)
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/namespaces/group%2Fsubgroup%2Fteam":
+			fmt.Fprint(w, `{"id": 42, "full_path": "group/subgroup/team"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"path": "test-repo", "path_with_namespace": "group/subgroup/team/test-repo", "ssh_url_to_repo": "git@gitlab.example.com:group/subgroup/team/test-repo.git"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	e := newMockExecutor()
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      ts.URL + "/group/subgroup/team/test-repo.git",
+			GitHostAccessToken: "this-is-a-test-token",
+			OutputPath:         "/tmp",
+		},
+		func(string) (*scm.Client, error) {
+			return factory.NewClient("gitlab", ts.URL, "this-is-a-test-token")
+		},
+		e,
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"name":         "test-repo",
+		"path":         "test-repo",
+		"description":  defaultRepoDescription,
+		"namespace_id": float64(42),
+		"visibility":   "private",
+	}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Fatalf("BootstrapRepository failed:\n%s", diff)
+	}
+	wantRemote := execution{
+		BaseDir: "/tmp",
+		Command: "git",
+		Args:    []string{"remote", "add", "origin", "git@gitlab.example.com:group/subgroup/team/test-repo.git"},
+	}
+	if diff := cmp.Diff(wantRemote, e.executed[4]); diff != "" {
+		t.Fatalf("repository pushed to the wrong remote:\n%s", diff)
+	}
+}
+
+func TestGitlabRequestWithMultipleChoices(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultipleChoices)
+		fmt.Fprint(w, `{"id": 42}`)
+	}))
+	defer ts.Close()
+	client, err := factory.NewClient("gitlab", ts.URL, "this-is-a-test-token")
+	assertNoError(t, err)
+
+	var out map[string]interface{}
+	err = gitlabRequest(context.TODO(), client, "GET", "api/v4/namespaces/test", nil, &out)
+	if !test.ErrorMatch(t, `Multiple Choices: {"id": 42}`, err) {
+		t.Fatalf("gitlabRequest() got error %v", err)
+	}
+}
+
 func TestBootstrapRepository_with_no_access_token(t *testing.T) {
 	token := "this-is-a-test-token"
 	factory, fakeData := newMockClientFactory(t, token)
@@ -70,6 +165,39 @@ func TestBootstrapRepository_with_no_access_token(t *testing.T) {
 	refuteRepositoryCreated(t, fakeData)
 }
 
+func TestBootstrapRepository_with_gitea(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "test-user")
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+	e := newMockExecutor()
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      gitea.URL + "/testing/test-repo.git",
+			GitHostAccessToken: "this-is-a-test-token",
+			OutputPath:         "/tmp",
+		},
+		factory.FromRepoURL,
+		e,
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff([]string{"testing/test-repo"}, gitea.Repositories); diff != "" {
+		t.Fatalf("BootstrapRepository failed:\n%s", diff)
+	}
+	want := execution{
+		BaseDir: "/tmp",
+		Command: "git",
+		Args:    []string{"remote", "add", "origin", "git@" + gitea.Host() + ":testing/test-repo.git"},
+	}
+	if diff := cmp.Diff(want, e.executed[4]); diff != "" {
+		t.Fatalf("repository pushed to the wrong remote:\n%s", diff)
+	}
+}
+
 func TestPushRepository(t *testing.T) {
 	repo := "git@github.com:testing/testing.git"
 	opts := &BootstrapOptions{
@@ -209,6 +337,69 @@ func TestCmdExecutor(t *testing.T) {
 	}
 }
 
+func TestBootstrapRepository_with_gitea_on_sub_path(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "test-user")
+	gitea.Prefix = "/gitea"
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      gitea.URL + "/gitea/testing/test-repo.git",
+			GitHostAccessToken: "this-is-a-test-token",
+			OutputPath:         "/tmp",
+		},
+		factory.FromRepoURL,
+		newMockExecutor(),
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+
+	if diff := cmp.Diff([]string{"testing/test-repo"}, gitea.Repositories); diff != "" {
+		t.Fatalf("BootstrapRepository failed:\n%s", diff)
+	}
+}
+
+func TestSplitRepositoryPath(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(
+		factory.Mapping("gitea.example.com", "gitea"),
+		factory.Mapping("bitbucket.example.com", "stash"))
+
+	pathTests := []struct {
+		repoURL   string
+		wantOrg   string
+		wantName  string
+		wantError string
+	}{
+		{"https://github.com/org/repo.git", "org", "repo", ""},
+		{"https://gitlab.com/group/subgroup/team/repo.git", "group/subgroup/team", "repo", ""},
+		{"https://gitea.example.com/gitea/org/repo.git", "org", "repo", ""},
+		{"https://bitbucket.example.com/scm/proj/repo.git", "PROJ", "repo", ""},
+		{"https://bitbucket.example.com/bitbucket/scm/proj/repo.git", "PROJ", "repo", ""},
+		{"https://github.com/repo.git", "", "", "failed to get the namespace and repository"},
+		{"https://bitbucket.example.com/proj/repo.git", "", "", "failed to get Bitbucket Server repository"},
+	}
+
+	for _, tt := range pathTests {
+		t.Run(tt.repoURL, func(rt *testing.T) {
+			u, err := url.Parse(tt.repoURL)
+			assertNoError(rt, err)
+			org, name, err := splitRepositoryPath(u)
+			if !test.ErrorMatch(rt, tt.wantError, err) {
+				rt.Fatalf("splitRepositoryPath() got error %v, want %s", err, tt.wantError)
+			}
+			if org != tt.wantOrg || name != tt.wantName {
+				rt.Errorf("splitRepositoryPath() got %q, %q, want %q, %q", org, name, tt.wantOrg, tt.wantName)
+			}
+		})
+	}
+}
+
 func TestRepoURL(t *testing.T) {
 	urlTests := []struct {
 		repoURL string
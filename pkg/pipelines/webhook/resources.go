@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"context"
+	"fmt"
 
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	"github.com/pkg/errors"
@@ -55,3 +56,28 @@ func (r *resources) getListenerAddress(ns, routeName string) (bool, string, erro
 
 	return route.Spec.TLS != nil, route.Spec.Host, nil
 }
+
+// getIngressAddress returns TLS is configured, and the external address host
+// of the Event Listener exposed by an Ingress.
+//
+// The host of the first rule is used, if the rule matches all hosts, the
+// address of the load balancer is used.
+func (r *resources) getIngressAddress(ns, ingressName string) (bool, string, error) {
+	ingress, err := r.kubeClient.NetworkingV1().Ingresses(ns).Get(context.Background(), ingressName, metav1.GetOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	hasTLS := len(ingress.Spec.TLS) > 0
+	if len(ingress.Spec.Rules) > 0 && ingress.Spec.Rules[0].Host != "" {
+		return hasTLS, ingress.Spec.Rules[0].Host, nil
+	}
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			return hasTLS, lb.Hostname, nil
+		}
+		if lb.IP != "" {
+			return hasTLS, lb.IP, nil
+		}
+	}
+	return false, "", fmt.Errorf("failed to find an address for the Ingress %s/%s", ns, ingressName)
+}
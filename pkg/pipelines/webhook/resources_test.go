@@ -14,8 +14,11 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	fakeRouteClientset "github.com/openshift/client-go/route/clientset/versioned/fake"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ktesting "k8s.io/client-go/testing"
+
+	"github.com/redhat-developer/kam/test"
 )
 
 const testNamespace = "tst-cicd"
@@ -60,6 +63,72 @@ func TestGetRouteHost(t *testing.T) {
 	}
 }
 
+func TestGetIngressAddress(t *testing.T) {
+	ingressTests := []struct {
+		desc     string
+		spec     networkingv1.IngressSpec
+		status   networkingv1.IngressStatus
+		wantTLS  bool
+		wantHost string
+		wantErr  string
+	}{
+		{
+			desc:     "host from the rule",
+			spec:     networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "el.example.com"}}},
+			wantHost: "el.example.com",
+		},
+		{
+			desc: "host from the rule with TLS",
+			spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "el.example.com"}},
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"el.example.com"}}},
+			},
+			wantTLS:  true,
+			wantHost: "el.example.com",
+		},
+		{
+			desc: "hostname from the load balancer",
+			spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{}}},
+			status: networkingv1.IngressStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			}},
+			wantHost: "lb.example.com",
+		},
+		{
+			desc: "IP from the load balancer",
+			status: networkingv1.IngressStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.10"}},
+			}},
+			wantHost: "192.0.2.10",
+		},
+		{
+			desc:    "no address",
+			wantErr: "failed to find an address for the Ingress tst-cicd/gitops-webhook-event-listener",
+		},
+	}
+
+	for _, tt := range ingressTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resources := fakeNewResources(nil, fakeKubeClientset.NewSimpleClientset(&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "gitops-webhook-event-listener", Namespace: testNamespace},
+				Spec:       tt.spec,
+				Status:     tt.status,
+			}))
+
+			hasTLS, host, err := resources.getIngressAddress(testNamespace, "gitops-webhook-event-listener")
+			if !test.ErrorMatch(t, tt.wantErr, err) {
+				t.Fatalf("error mismatch: got %v, want %v", err, tt.wantErr)
+			}
+			if hasTLS != tt.wantTLS {
+				t.Errorf("hasTLS got %v, want %v", hasTLS, tt.wantTLS)
+			}
+			if diff := cmp.Diff(tt.wantHost, host); diff != "" {
+				t.Errorf("host mismatch got\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestGetSecret(t *testing.T) {
 	kubeClient := fakeKubeClientset.NewSimpleClientset()
 
@@ -0,0 +1,323 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+)
+
+// Action is a change to a webhook in a Git repository.
+type Action string
+
+const (
+	// ActionNone is used for webhooks that are up to date.
+	ActionNone Action = "none"
+	// ActionCreate creates a missing webhook.
+	ActionCreate Action = "create"
+	// ActionFix replaces a webhook to the EventListener with the wrong events,
+	// or a webhook with a stale target when fixing stale webhooks.
+	ActionFix Action = "fix"
+	// ActionPrune deletes a webhook that isn't needed, or that has a stale
+	// target.
+	ActionPrune Action = "prune"
+)
+
+// SyncOptions provides the manifest to sync the webhooks for.
+type SyncOptions struct {
+	// AccessToken is used for all the repositories, if it's empty, the token
+	// for each repository is read from the keyring or environment.
+	AccessToken         string
+	PipelinesFolderPath string
+	// Prune deletes the webhooks to the EventListener that aren't needed.
+	Prune bool
+	// FixStale replaces the webhooks for an EventListener with a different
+	// target with webhooks to the EventListener, for the targets that have no
+	// webhook.
+	FixStale bool
+}
+
+// Change is a planned change to the webhooks in a Git repository.
+type Change struct {
+	Action Action `json:"action"`
+	// Target is "cicd" for the GitOps repository, or "<env>/<service>" for a
+	// service, it's empty for webhooks that aren't needed.
+	Target     string `json:"target,omitempty"`
+	Repository string `json:"repository"`
+	// ID is the ID of the existing webhook.
+	ID     string `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// CreatedID is the ID of the webhook created when the change is applied.
+	CreatedID string `json:"createdID,omitempty"`
+
+	secret types.NamespacedName
+}
+
+// SyncPlan is the set of changes needed to bring the webhooks of the
+// repositories in the manifest in line with the EventListener.
+type SyncPlan struct {
+	Changes []*Change
+
+	clusterResource *resources
+	listenerURL     string
+	repositories    map[string]*git.Repository
+}
+
+// syncTarget is a repository that needs a webhook, and the secret that
+// authenticates the events that the webhook delivers.
+type syncTarget struct {
+	name    string
+	repoURL string
+	secret  types.NamespacedName
+}
+
+// PlanSync walks the manifest, and compares the webhooks that the
+// EventListener needs, for the GitOps repository and every service with a
+// source URL, with the webhooks in the repositories.
+//
+// Nothing is changed until the plan is applied.
+func PlanSync(o *SyncOptions) (*SyncPlan, error) {
+	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), o.PipelinesFolderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	clusterResources, err := newResources()
+	if err != nil {
+		return nil, err
+	}
+	return planSync(manifest, clusterResources, o, func(repoURL string) (*git.Repository, error) {
+		accessToken := o.AccessToken
+		if accessToken == "" {
+			accessToken, err = accesstoken.GetAccessToken(repoURL)
+			if err != nil {
+				return nil, fmt.Errorf("unable to use access-token from keyring/env-var for %s: %v, please pass a valid token to --git-host-access-token", repoURL, err)
+			}
+		}
+		return git.NewRepository(repoURL, accessToken)
+	})
+}
+
+func planSync(manifest *config.Manifest, r *resources, o *SyncOptions, newRepository func(string) (*git.Repository, error)) (*SyncPlan, error) {
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a pipelines configuration in the manifest")
+	}
+	listenerURL, err := getListenerURL(r, cfg.Name, manifest.GetPlatform())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
+	}
+	targets, err := syncTargets(manifest, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{
+		clusterResource: r,
+		listenerURL:     listenerURL,
+		repositories:    map[string]*git.Repository{},
+	}
+	repoURLs := []string{}
+	byRepo := map[string][]*syncTarget{}
+	for _, t := range targets {
+		if _, ok := byRepo[t.repoURL]; !ok {
+			repoURLs = append(repoURLs, t.repoURL)
+		}
+		byRepo[t.repoURL] = append(byRepo[t.repoURL], t)
+	}
+	for _, repoURL := range repoURLs {
+		repository, err := newRepository(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		hooks, err := repository.Webhooks()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the webhooks for %s: %v", repoURL, err)
+		}
+		plan.repositories[repoURL] = repository
+		plan.Changes = append(plan.Changes, planRepository(repoURL, byRepo[repoURL], hooks, listenerURL, repository.HasWebhookEvents, o.Prune, o.FixStale)...)
+	}
+	return plan, nil
+}
+
+// planRepository plans the changes for the targets that share a repository.
+//
+// Each target needs its own webhook, because the events are authenticated
+// with the secret of the target. The secret of a webhook can't be read, so the
+// webhooks that are up to date are assigned to the targets in order.
+//
+// Webhooks for an EventListener with a different target may be stale, or may
+// belong to the EventListener in another cluster, so they are only replaced
+// when fixing stale webhooks, and the rest are only deleted when pruning.
+func planRepository(repoURL string, targets []*syncTarget, hooks []*scm.Hook, listenerURL string, hasEvents func(*scm.Hook) bool, prune, fixStale bool) []*Change {
+	current := []*scm.Hook{}
+	wrongEvents := []*scm.Hook{}
+	foreign := []*scm.Hook{}
+	for _, h := range hooks {
+		switch {
+		case h.Target == listenerURL && hasEvents(h):
+			current = append(current, h)
+		case h.Target == listenerURL:
+			wrongEvents = append(wrongEvents, h)
+		case isListenerHook(h):
+			foreign = append(foreign, h)
+		}
+	}
+
+	changes := []*Change{}
+	for i, t := range targets {
+		change := &Change{Target: t.name, Repository: repoURL, secret: t.secret}
+		switch {
+		case i < len(current):
+			change.Action = ActionNone
+			change.ID = current[i].ID
+		case len(wrongEvents) > 0:
+			change.Action = ActionFix
+			change.ID = wrongEvents[0].ID
+			change.Reason = fmt.Sprintf("wrong events %s", strings.Join(wrongEvents[0].Events, ","))
+			wrongEvents = wrongEvents[1:]
+		case fixStale && len(foreign) > 0:
+			change.Action = ActionFix
+			change.ID = foreign[0].ID
+			change.Reason = fmt.Sprintf("stale target %s", foreign[0].Target)
+			foreign = foreign[1:]
+		default:
+			change.Action = ActionCreate
+			change.Reason = "missing webhook"
+		}
+		changes = append(changes, change)
+	}
+
+	extras := []*scm.Hook{}
+	if len(current) > len(targets) {
+		extras = append(extras, current[len(targets):]...)
+	}
+	extras = append(extras, wrongEvents...)
+	for _, h := range extras {
+		change := &Change{Action: ActionNone, Repository: repoURL, ID: h.ID, Reason: "not needed, use --prune to delete"}
+		if prune {
+			change.Action = ActionPrune
+			change.Reason = "not needed"
+		}
+		changes = append(changes, change)
+	}
+	for _, h := range foreign {
+		change := &Change{Action: ActionNone, Repository: repoURL, ID: h.ID, Reason: fmt.Sprintf("target %s is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete", h.Target)}
+		if prune {
+			change.Action = ActionPrune
+			change.Reason = fmt.Sprintf("stale target %s", h.Target)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// isListenerHook returns true if the webhook was created for an
+// EventListener, the hooks are named on drivers that support it, and the
+// default hosts of the routes, and hosts of Ingresses that are generated from
+// their names, start with the route or Ingress name.
+func isListenerHook(h *scm.Hook) bool {
+	if h.Name == git.WebhookName {
+		return true
+	}
+	u, err := url.Parse(h.Target)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(u.Hostname(), eventlisteners.GitOpsWebhookEventListenerIngressName+"-")
+}
+
+// Apply makes the planned changes, the webhooks are created before the
+// webhooks that they replace are deleted, so that events are not missed.
+func (p *SyncPlan) Apply() error {
+	for _, c := range p.Changes {
+		if c.Action != ActionCreate && c.Action != ActionFix {
+			continue
+		}
+		secret, err := p.clusterResource.getWebhookSecret(c.secret.Namespace, c.secret.Name, eventlisteners.WebhookSecretKey)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook secret for %s: %v", c.Target, err)
+		}
+		created, err := p.repositories[c.Repository].CreateWebhook(p.listenerURL, secret)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook for %s in %s: %v", c.Target, c.Repository, err)
+		}
+		c.CreatedID = created
+	}
+	for _, c := range p.Changes {
+		if c.Action != ActionFix && c.Action != ActionPrune {
+			continue
+		}
+		if _, err := p.repositories[c.Repository].DeleteWebhooks([]string{c.ID}); err != nil {
+			return fmt.Errorf("failed to delete webhook in %s: %v", c.Repository, err)
+		}
+	}
+	return nil
+}
+
+// HasChanges returns true if applying the plan would change any webhooks.
+func (p *SyncPlan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Action != ActionNone {
+			return true
+		}
+	}
+	return false
+}
+
+// syncTargets returns the GitOps repository, and the source repositories of
+// the services in the manifest, with the secrets for their webhooks.
+func syncTargets(manifest *config.Manifest, cicdNamespace string) ([]*syncTarget, error) {
+	targets := []*syncTarget{}
+	if manifest.GitOpsURL != "" {
+		repoURL, err := giturl.Normalize(manifest.GitOpsURL)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, &syncTarget{
+			name:    "cicd",
+			repoURL: repoURL,
+			secret:  meta.NamespacedName(cicdNamespace, eventlisteners.GitOpsWebhookSecret),
+		})
+	}
+	v := &targetVisitor{cicdNamespace: cicdNamespace}
+	if err := manifest.Walk(v); err != nil {
+		return nil, err
+	}
+	return append(targets, v.targets...), nil
+}
+
+type targetVisitor struct {
+	cicdNamespace string
+	targets       []*syncTarget
+}
+
+func (v *targetVisitor) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
+	if svc.SourceURL == "" {
+		return nil
+	}
+	repoURL, err := giturl.Normalize(svc.SourceURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse the source URL of service %s: %v", svc.Name, err)
+	}
+	secret := meta.NamespacedName(v.cicdNamespace, secrets.MakeServiceWebhookSecretName(env.Name, svc.Name))
+	if svc.Webhook != nil && svc.Webhook.Secret != nil {
+		secret = meta.NamespacedName(svc.Webhook.Secret.Namespace, svc.Webhook.Secret.Name)
+	}
+	v.targets = append(v.targets, &syncTarget{
+		name:    env.Name + "/" + svc.Name,
+		repoURL: repoURL,
+		secret:  secret,
+	})
+	return nil
+}
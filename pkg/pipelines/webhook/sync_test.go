@@ -0,0 +1,306 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	routev1 "github.com/openshift/api/route/v1"
+	fakeRouteClientset "github.com/openshift/client-go/route/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeKubeClientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/test"
+)
+
+const testListenerURL = "https://gitops-webhook-event-listener-route-cicd.apps.example.com"
+
+func TestPlanRepository(t *testing.T) {
+	repoURL := "https://github.com/org/api.git"
+	api := &syncTarget{name: "dev/api", repoURL: repoURL}
+	web := &syncTarget{name: "dev/web", repoURL: repoURL}
+	current := &scm.Hook{ID: "1", Target: testListenerURL, Events: []string{"push", "pull_request"}}
+	wrongEvents := &scm.Hook{ID: "2", Target: testListenerURL, Events: []string{"push"}}
+	staleTarget := &scm.Hook{ID: "3", Target: "https://gitops-webhook-event-listener-route-cicd.apps.old.example.com", Events: []string{"push", "pull_request"}}
+	staleNamed := &scm.Hook{ID: "4", Name: git.WebhookName, Target: "https://el.example.com"}
+	other := &scm.Hook{ID: "5", Target: "https://ci.example.com/hook", Events: []string{"push"}}
+
+	planTests := []struct {
+		desc     string
+		targets  []*syncTarget
+		hooks    []*scm.Hook
+		prune    bool
+		fixStale bool
+		want     []*Change
+	}{
+		{
+			"missing webhook",
+			[]*syncTarget{api},
+			[]*scm.Hook{other},
+			false,
+			false,
+			[]*Change{{Action: ActionCreate, Target: "dev/api", Repository: repoURL, Reason: "missing webhook"}},
+		},
+		{
+			"up to date webhook",
+			[]*syncTarget{api},
+			[]*scm.Hook{other, current},
+			false,
+			false,
+			[]*Change{{Action: ActionNone, Target: "dev/api", Repository: repoURL, ID: "1"}},
+		},
+		{
+			"webhook with wrong events",
+			[]*syncTarget{api},
+			[]*scm.Hook{wrongEvents},
+			false,
+			false,
+			[]*Change{{Action: ActionFix, Target: "dev/api", Repository: repoURL, ID: "2", Reason: "wrong events push"}},
+		},
+		{
+			"webhooks with stale targets",
+			[]*syncTarget{api, web},
+			[]*scm.Hook{staleTarget, staleNamed},
+			false,
+			false,
+			[]*Change{
+				{Action: ActionCreate, Target: "dev/api", Repository: repoURL, Reason: "missing webhook"},
+				{Action: ActionCreate, Target: "dev/web", Repository: repoURL, Reason: "missing webhook"},
+				{Action: ActionNone, Repository: repoURL, ID: "3", Reason: "target https://gitops-webhook-event-listener-route-cicd.apps.old.example.com is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete"},
+				{Action: ActionNone, Repository: repoURL, ID: "4", Reason: "target https://el.example.com is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete"},
+			},
+		},
+		{
+			"pruned webhooks with stale targets",
+			[]*syncTarget{api},
+			[]*scm.Hook{staleTarget, wrongEvents},
+			true,
+			false,
+			[]*Change{
+				{Action: ActionFix, Target: "dev/api", Repository: repoURL, ID: "2", Reason: "wrong events push"},
+				{Action: ActionPrune, Repository: repoURL, ID: "3", Reason: "stale target https://gitops-webhook-event-listener-route-cicd.apps.old.example.com"},
+			},
+		},
+		{
+			"fixed webhooks with stale targets",
+			[]*syncTarget{api, web},
+			[]*scm.Hook{staleTarget, wrongEvents, staleNamed, other},
+			false,
+			true,
+			[]*Change{
+				{Action: ActionFix, Target: "dev/api", Repository: repoURL, ID: "2", Reason: "wrong events push"},
+				{Action: ActionFix, Target: "dev/web", Repository: repoURL, ID: "3", Reason: "stale target https://gitops-webhook-event-listener-route-cicd.apps.old.example.com"},
+				{Action: ActionNone, Repository: repoURL, ID: "4", Reason: "target https://el.example.com is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete"},
+			},
+		},
+		{
+			"shared repository",
+			[]*syncTarget{api, web},
+			[]*scm.Hook{current},
+			false,
+			false,
+			[]*Change{
+				{Action: ActionNone, Target: "dev/api", Repository: repoURL, ID: "1"},
+				{Action: ActionCreate, Target: "dev/web", Repository: repoURL, Reason: "missing webhook"},
+			},
+		},
+		{
+			"extra webhooks",
+			[]*syncTarget{api},
+			[]*scm.Hook{current, current, staleNamed},
+			false,
+			false,
+			[]*Change{
+				{Action: ActionNone, Target: "dev/api", Repository: repoURL, ID: "1"},
+				{Action: ActionNone, Repository: repoURL, ID: "1", Reason: "not needed, use --prune to delete"},
+				{Action: ActionNone, Repository: repoURL, ID: "4", Reason: "target https://el.example.com is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete"},
+			},
+		},
+		{
+			"pruned webhooks",
+			[]*syncTarget{api},
+			[]*scm.Hook{staleNamed, current, other},
+			true,
+			false,
+			[]*Change{
+				{Action: ActionNone, Target: "dev/api", Repository: repoURL, ID: "1"},
+				{Action: ActionPrune, Repository: repoURL, ID: "4", Reason: "stale target https://el.example.com"},
+			},
+		},
+	}
+
+	hasEvents := func(h *scm.Hook) bool {
+		return len(h.Events) != 1
+	}
+	for _, tt := range planTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := planRepository(repoURL, tt.targets, tt.hooks, testListenerURL, hasEvents, tt.prune, tt.fixStale)
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreUnexported(Change{})); diff != "" {
+				t.Errorf("planRepository() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncTargets(t *testing.T) {
+	m := testSyncManifest()
+
+	targets, err := syncTargets(m, "cicd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*syncTarget{
+		{name: "cicd", repoURL: "https://fake.com/org/gitops.git", secret: meta.NamespacedName("cicd", eventlisteners.GitOpsWebhookSecret)},
+		{name: "dev/api", repoURL: "https://fake.com/org/api.git", secret: meta.NamespacedName("cicd", "webhook-secret-dev-api")},
+		{name: "dev/web", repoURL: "https://fake.com/org/api.git", secret: meta.NamespacedName("cicd", "web-secret")},
+	}
+	if diff := cmp.Diff(want, targets, cmp.AllowUnexported(syncTarget{})); diff != "" {
+		t.Fatalf("syncTargets() failed:\n%s", diff)
+	}
+}
+
+func TestPlanSyncAndApply(t *testing.T) {
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+	repositories := map[string]*git.Repository{}
+	for _, u := range []string{"https://fake.com/org/gitops.git", "https://fake.com/org/api.git"} {
+		repo, err := git.NewRepository(u, "token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		repositories[u] = repo
+	}
+	gitops := repositories["https://fake.com/org/gitops.git"]
+	staleID, err := gitops.CreateWebhook("https://el.old.example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := gitops.Client.Repositories.CreateHook(context.Background(), "org/gitops", &scm.HookInput{Name: "ci", Target: "https://ci.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	api := repositories["https://fake.com/org/api.git"]
+	currentID, err := api.CreateWebhook(testListenerURL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := fakeNewResources(
+		fakeRouteClientset.NewSimpleClientset(&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: eventlisteners.GitOpsWebhookEventListenerRouteName, Namespace: "cicd"},
+			Spec: routev1.RouteSpec{
+				Host: "gitops-webhook-event-listener-route-cicd.apps.example.com",
+				TLS:  &routev1.TLSConfig{},
+			},
+		}).RouteV1(),
+		fakeKubeClientset.NewSimpleClientset(
+			testWebhookSecret(eventlisteners.GitOpsWebhookSecret),
+			testWebhookSecret("web-secret"),
+		))
+
+	plan, err := planSync(testSyncManifest(), r, &SyncOptions{}, func(u string) (*git.Repository, error) {
+		return repositories[u], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Change{
+		{Action: ActionCreate, Target: "cicd", Repository: "https://fake.com/org/gitops.git", Reason: "missing webhook"},
+		{Action: ActionNone, Repository: "https://fake.com/org/gitops.git", ID: staleID, Reason: "target https://el.old.example.com is stale or another cluster's EventListener, use --fix-stale to replace or --prune to delete"},
+		{Action: ActionNone, Target: "dev/api", Repository: "https://fake.com/org/api.git", ID: currentID},
+		{Action: ActionCreate, Target: "dev/web", Repository: "https://fake.com/org/api.git", Reason: "missing webhook"},
+	}
+	if diff := cmp.Diff(want, plan.Changes, cmpopts.IgnoreUnexported(Change{})); diff != "" {
+		t.Fatalf("planSync() failed:\n%s", diff)
+	}
+	if !plan.HasChanges() {
+		t.Fatal("HasChanges() got false, want true")
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	assertWebhooks(t, gitops, []string{plan.Changes[0].CreatedID})
+	assertWebhooks(t, api, []string{currentID, plan.Changes[3].CreatedID})
+	hooks, err := gitops.Webhooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 3 {
+		t.Fatalf("got %d webhooks in the GitOps repository, want 3", len(hooks))
+	}
+}
+
+func TestApplyWithMissingSecret(t *testing.T) {
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+	repo, err := git.NewRepository("https://fake.com/org/api.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &SyncPlan{
+		Changes: []*Change{
+			{Action: ActionCreate, Target: "dev/api", Repository: "https://fake.com/org/api.git", secret: meta.NamespacedName("cicd", "missing")},
+		},
+		clusterResource: fakeNewResources(nil, fakeKubeClientset.NewSimpleClientset()),
+		listenerURL:     testListenerURL,
+		repositories:    map[string]*git.Repository{"https://fake.com/org/api.git": repo},
+	}
+
+	err = plan.Apply()
+	test.AssertErrorMatch(t, "failed to get webhook secret for dev/api", err)
+	assertWebhooks(t, repo, []string{})
+}
+
+func assertWebhooks(t *testing.T, repo *git.Repository, want []string) {
+	t.Helper()
+	ids, err := repo.ListWebhooks(testListenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, ids); diff != "" {
+		t.Fatalf("webhooks mismatch:\n%s", diff)
+	}
+}
+
+func testWebhookSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cicd"},
+		Data:       map[string][]byte{eventlisteners.WebhookSecretKey: []byte("testing")},
+	}
+}
+
+func testSyncManifest() *config.Manifest {
+	return &config.Manifest{
+		GitOpsURL: "https://fake.com/org/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "app",
+						Services: []*config.Service{
+							{Name: "api", SourceURL: "https://fake.com/org/api.git"},
+							{
+								Name:       "web",
+								SourceURL:  "https://fake.com/org/api.git",
+								SourcePath: "web",
+								Webhook:    &config.Webhook{Secret: &config.Secret{Name: "web-secret", Namespace: "cicd"}},
+							},
+							{Name: "worker"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
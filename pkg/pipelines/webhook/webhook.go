@@ -3,11 +3,16 @@ package webhook
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/openshift/odo/pkg/log"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 )
@@ -52,6 +57,26 @@ func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 // Delete deletes webhooks on the target Git Repository that match the listener address
 // It returns the IDs of deleted webhooks.
 func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
+	deleteHooks, err := PrepareDelete(accessToken, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return nil, err
+	}
+	return deleteHooks()
+}
+
+// PrepareDelete finds the webhooks on the target Git Repository that match the
+// listener address, and returns a function that deletes them.
+//
+// This allows the webhooks of a service to be found while it's in the
+// manifest, and deleted once it has been removed.
+//
+// The webhooks to the listener are shared by all the services that use a
+// repository, so they are kept if other services use the repository of the
+// service.
+func PrepareDelete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (func() ([]string, error), error) {
+	if !isCICD {
+		return prepareDeleteServices(accessToken, pipelinesFile, []*QualifiedServiceName{serviceName})
+	}
 	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return nil, err
@@ -62,17 +87,180 @@ func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 		return nil, err
 	}
 
-	return webhook.delete(ids)
+	return func() ([]string, error) {
+		return webhook.delete(ids)
+	}, nil
+}
+
+// PrepareDeleteEnvironment finds the webhooks to the listener in the source
+// repositories of the services in an environment, and returns a function that
+// deletes them.
+//
+// The webhooks in repositories that are used by services in other
+// environments, or by the GitOps repository, are kept.
+func PrepareDeleteEnvironment(accessToken, pipelinesFile, envName string) (func() ([]string, error), error) {
+	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	env := manifest.GetEnvironment(envName)
+	if env == nil {
+		return nil, fmt.Errorf("environment %s does not exist", envName)
+	}
+	services := []*QualifiedServiceName{}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.SourceURL != "" {
+				services = append(services, &QualifiedServiceName{EnvironmentName: env.Name, ServiceName: svc.Name})
+			}
+		}
+	}
+	return prepareDeleteServices(accessToken, pipelinesFile, services)
+}
+
+// prepareDeleteServices finds the webhooks to the listener in the source
+// repositories of the removed services, each repository is only listed once,
+// and repositories that are used by other services are skipped.
+func prepareDeleteServices(accessToken, pipelinesFile string, removed []*QualifiedServiceName) (func() ([]string, error), error) {
+	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	users, err := repositoryUsers(manifest, removed)
+	if err != nil {
+		return nil, err
+	}
+	type deletion struct {
+		webhook *webhookInfo
+		ids     []string
+	}
+	deletions := []deletion{}
+	seen := map[string]bool{}
+	for _, svc := range removed {
+		repoURL := getSourceRepoURL(manifest, svc)
+		if repoURL == "" {
+			return nil, errors.New("failed to find Git repository URL in manifest")
+		}
+		normalized, err := giturl.Normalize(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		if len(users[normalized]) > 0 {
+			log.Warningf("Keeping the webhooks in %s, the repository is used by %s", repoURL, strings.Join(users[normalized], ", "))
+			continue
+		}
+		webhook, err := newWebhookInfo(accessToken, pipelinesFile, svc, false)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := webhook.list()
+		if err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, deletion{webhook: webhook, ids: ids})
+	}
+
+	return func() ([]string, error) {
+		deleted := []string{}
+		for _, d := range deletions {
+			ids, err := d.webhook.delete(d.ids)
+			deleted = append(deleted, ids...)
+			if err != nil {
+				return deleted, err
+			}
+		}
+		return deleted, nil
+	}, nil
+}
+
+// repositoryUsers returns the names of the services, and "cicd" for the GitOps
+// repository, that use each repository, excluding the removed services.
+func repositoryUsers(manifest *config.Manifest, removed []*QualifiedServiceName) (map[string][]string, error) {
+	targets, err := syncTargets(manifest, "")
+	if err != nil {
+		return nil, err
+	}
+	removedNames := map[string]bool{}
+	for _, svc := range removed {
+		removedNames[svc.EnvironmentName+"/"+svc.ServiceName] = true
+	}
+	users := map[string][]string{}
+	for _, t := range targets {
+		if !removedNames[t.name] {
+			users[t.repoURL] = append(users[t.repoURL], t.name)
+		}
+	}
+	return users, nil
+}
+
+// Webhook is a webhook to the EventListener in a Git repository.
+type Webhook struct {
+	ID         string   `json:"id"`
+	Repository string   `json:"repository"`
+	Target     string   `json:"target"`
+	Events     []string `json:"events"`
+	Active     bool     `json:"active"`
+	SkipVerify bool     `json:"skipVerify"`
+	// LastDelivery is the status of the last delivery, it's empty if the Git
+	// hosting service doesn't report it.
+	LastDelivery string `json:"lastDelivery,omitempty"`
+	// Stale is true if the webhook targets a previous address of the
+	// EventListener.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// List returns the webhooks to the listener in the target Git repository, the
+// webhooks with stale listener targets are included if includeStale is true.
+func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD, includeStale bool) ([]*Webhook, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.webhooks(includeStale)
+}
+
+// Rotation is the result of rotating the secret of the webhooks in a
+// repository.
+type Rotation struct {
+	// Updated is the IDs of the webhooks that were updated with the secret.
+	Updated []string
+	// Replaced is the IDs of the webhooks that were replaced by the Created
+	// webhook, on drivers that webhooks can't be updated on.
+	Replaced []string
+	Created  string
+}
+
+// Changed returns true if any webhook was updated or created with the secret.
+func (r *Rotation) Changed() bool {
+	return len(r.Updated) > 0 || r.Created != ""
 }
 
-// List returns an array of webhook IDs for the target Git repository/listeners
-func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
+// Rotate updates the webhooks on the target Git Repository that match the
+// listener address with the provided secret.
+// On drivers that webhooks can't be updated on, the webhooks are replaced with
+// a webhook that is authenticated with the secret.
+func Rotate(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, secret string) (*Rotation, error) {
 	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
 	if err != nil {
 		return nil, err
 	}
 
-	return webhook.list()
+	ids, err := webhook.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return &Rotation{}, nil
+	}
+	if !webhook.repository.CanUpdateWebhooks() {
+		return webhook.replace(ids, secret)
+	}
+	return webhook.update(ids, secret)
 }
 
 func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
@@ -97,7 +285,7 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 		return nil, err
 	}
 
-	listenerURL, err := getListenerURL(clusterResources, cicdNamepace)
+	listenerURL, err := getListenerURL(clusterResources, cicdNamepace, manifest.GetPlatform())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
 	}
@@ -127,6 +315,18 @@ func (w *webhookInfo) list() ([]string, error) {
 	return w.repository.ListWebhooks(w.listenerURL)
 }
 
+func (w *webhookInfo) webhooks(includeStale bool) ([]*Webhook, error) {
+	hooks, err := w.repository.Webhooks()
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := w.repository.LastDeliveries()
+	if err != nil {
+		return nil, err
+	}
+	return listenerWebhooks(w.gitRepoURL, hooks, deliveries, w.listenerURL, includeStale), nil
+}
+
 func (w *webhookInfo) delete(ids []string) ([]string, error) {
 	return w.repository.DeleteWebhooks(ids)
 }
@@ -140,6 +340,53 @@ func (w *webhookInfo) create() (string, error) {
 	return w.repository.CreateWebhook(w.listenerURL, secret)
 }
 
+// update updates the webhooks with the secret in place, so that they keep
+// their IDs and events.
+func (w *webhookInfo) update(ids []string, secret string) (*Rotation, error) {
+	r := &Rotation{Updated: []string{}}
+	for _, id := range ids {
+		if err := w.repository.UpdateWebhook(id, w.listenerURL, secret); err != nil {
+			return r, fmt.Errorf("failed to update webhook id %s: %w", id, err)
+		}
+		r.Updated = append(r.Updated, id)
+	}
+	return r, nil
+}
+
+// replace creates the new webhook before deleting the existing ones, so that
+// events are not missed.
+func (w *webhookInfo) replace(ids []string, secret string) (*Rotation, error) {
+	created, err := w.repository.CreateWebhook(w.listenerURL, secret)
+	if err != nil {
+		return &Rotation{}, fmt.Errorf("failed to create webhook: %v", err)
+	}
+	deleted, err := w.repository.DeleteWebhooks(ids)
+	return &Rotation{Replaced: deleted, Created: created}, err
+}
+
+// listenerWebhooks returns the webhooks to the listener, and the webhooks with
+// stale listener targets if includeStale is true.
+func listenerWebhooks(repoURL string, hooks []*scm.Hook, deliveries map[string]string, listenerURL string, includeStale bool) []*Webhook {
+	webhooks := []*Webhook{}
+	for _, h := range hooks {
+		stale := h.Target != listenerURL
+		if stale && (!includeStale || !isListenerHook(h)) {
+			continue
+		}
+		webhooks = append(webhooks, &Webhook{
+			ID:           h.ID,
+			Repository:   repoURL,
+			Target:       h.Target,
+			Events:       h.Events,
+			Active:       h.Active,
+			SkipVerify:   h.SkipVerify,
+			LastDelivery: deliveries[h.ID],
+			Stale:        stale,
+		})
+	}
+	return webhooks
+}
+
 // Get Git repository URL whether it is CICD configuration or service source repository
 // Return "" if not found
 func getRepoURL(manifest *config.Manifest, isCICD bool, serviceName *QualifiedServiceName) string {
@@ -166,9 +413,14 @@ func getSourceRepoURL(manifest *config.Manifest, service *QualifiedServiceName)
 	return ""
 }
 
-func getListenerURL(r *resources, cicdNamespace string) (string, error) {
-	hasTLS, host, err := r.getListenerAddress(cicdNamespace,
-		eventlisteners.GitOpsWebhookEventListenerRouteName)
+// getListenerURL returns the URL of the EventListener, which is exposed by a
+// Route, or an Ingress on the kubernetes platform.
+func getListenerURL(r *resources, cicdNamespace, platform string) (string, error) {
+	getAddress, name := r.getListenerAddress, eventlisteners.GitOpsWebhookEventListenerRouteName
+	if platform == config.PlatformKubernetes {
+		getAddress, name = r.getIngressAddress, eventlisteners.GitOpsWebhookEventListenerIngressName
+	}
+	hasTLS, host, err := getAddress(cicdNamespace, name)
 	if err != nil {
 		return "", err
 	}
@@ -5,7 +5,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/test"
 )
 
 func TestBuildURL(t *testing.T) {
@@ -127,3 +131,180 @@ func TestGetGitRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestReplace(t *testing.T) {
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
+	repo, err := git.NewRepository("https://fake.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerURL := "http://example.com/webhook"
+	oldID, err := repo.CreateWebhook(listenerURL, "old-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &webhookInfo{repository: repo, listenerURL: listenerURL}
+
+	rotation, err := w.replace([]string{oldID}, "new-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{oldID}, rotation.Replaced); diff != "" {
+		t.Fatalf("deleted webhooks mismatch:\n%s", diff)
+	}
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{rotation.Created}, ids); diff != "" {
+		t.Fatalf("webhooks after replacing mismatch:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	gitea := test.NewFakeGitea(t, "testing")
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping(gitea.Host(), "gitea"))
+	repo, err := git.NewRepository(gitea.URL+"/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerURL := "http://example.com/webhook"
+	oldID, err := repo.CreateWebhook(listenerURL, "old-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &webhookInfo{repository: repo, listenerURL: listenerURL}
+
+	rotation, err := w.update([]string{oldID}, "new-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Rotation{Updated: []string{oldID}}
+	if diff := cmp.Diff(want, rotation); diff != "" {
+		t.Fatalf("rotation mismatch:\n%s", diff)
+	}
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{oldID}, ids); diff != "" {
+		t.Fatalf("webhooks after updating mismatch:\n%s", diff)
+	}
+	if secret := gitea.Hooks["foo/bar"][0].Config["secret"]; secret != "new-secret" {
+		t.Fatalf("webhook updated with secret %q", secret)
+	}
+}
+
+func TestListenerWebhooks(t *testing.T) {
+	repoURL := "https://github.com/org/api.git"
+	current := &scm.Hook{ID: "1", Target: testListenerURL, Events: []string{"push", "pull_request"}, Active: true}
+	stale := &scm.Hook{ID: "2", Name: git.WebhookName, Target: "https://el.old.example.com", Events: []string{"push"}, SkipVerify: true}
+	other := &scm.Hook{ID: "3", Target: "https://ci.example.com/hook", Events: []string{"push"}, Active: true}
+	deliveries := map[string]string{"1": "200 OK", "3": "500 Internal Server Error"}
+
+	listTests := []struct {
+		desc         string
+		includeStale bool
+		want         []*Webhook
+	}{
+		{
+			"listener webhooks",
+			false,
+			[]*Webhook{
+				{ID: "1", Repository: repoURL, Target: testListenerURL, Events: []string{"push", "pull_request"}, Active: true, LastDelivery: "200 OK"},
+			},
+		},
+		{
+			"with stale webhooks",
+			true,
+			[]*Webhook{
+				{ID: "1", Repository: repoURL, Target: testListenerURL, Events: []string{"push", "pull_request"}, Active: true, LastDelivery: "200 OK"},
+				{ID: "2", Repository: repoURL, Target: "https://el.old.example.com", Events: []string{"push"}, SkipVerify: true, Stale: true},
+			},
+		},
+	}
+
+	for _, tt := range listTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := listenerWebhooks(repoURL, []*scm.Hook{current, stale, other}, deliveries, testListenerURL, tt.includeStale)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("listenerWebhooks() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepositoryUsers(t *testing.T) {
+	manifest := &config.Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "taxi",
+						Services: []*config.Service{
+							{Name: "api", SourceURL: "https://github.com/org/mono.git", SourcePath: "api"},
+							{Name: "web", SourceURL: "git@github.com:org/mono.git", SourcePath: "web"},
+							{Name: "worker", SourceURL: "https://github.com/org/worker.git"},
+						},
+					},
+				},
+			},
+			{
+				Name: "stage",
+				Apps: []*config.Application{
+					{
+						Name:     "taxi",
+						Services: []*config.Service{{Name: "worker", SourceURL: "https://github.com/org/worker.git"}},
+					},
+				},
+			},
+		},
+	}
+
+	usersTests := []struct {
+		name    string
+		removed []*QualifiedServiceName
+		want    map[string][]string
+	}{
+		{
+			"service in a monorepo",
+			[]*QualifiedServiceName{{EnvironmentName: "dev", ServiceName: "api"}},
+			map[string][]string{
+				"https://github.com/org/gitops.git": {"cicd"},
+				"https://github.com/org/mono.git":   {"dev/web"},
+				"https://github.com/org/worker.git": {"dev/worker", "stage/worker"},
+			},
+		},
+		{
+			"all the services in an environment",
+			[]*QualifiedServiceName{
+				{EnvironmentName: "dev", ServiceName: "api"},
+				{EnvironmentName: "dev", ServiceName: "web"},
+				{EnvironmentName: "dev", ServiceName: "worker"},
+			},
+			map[string][]string{
+				"https://github.com/org/gitops.git": {"cicd"},
+				"https://github.com/org/worker.git": {"stage/worker"},
+			},
+		},
+	}
+
+	for _, tt := range usersTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			got, err := repositoryUsers(manifest, tt.removed)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("repositoryUsers() failed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
+)
+
+const defaultWorkspaceSize = "1Gi"
+
+// ciWorkspaceBindings returns the bindings for the workspaces of the
+// PipelineRun that builds a service, the source workspace defaults to a
+// volume that is claimed for each PipelineRun.
+func ciWorkspaceBindings(ws *config.Workspaces) []pipelinev1.WorkspaceBinding {
+	bindings := []pipelinev1.WorkspaceBinding{workspaceBinding(pipelines.SourceWorkspace, ws.Source)}
+	if ws.Cache != nil {
+		bindings = append(bindings, workspaceBinding(pipelines.CacheWorkspace, ws.Cache))
+	}
+	return bindings
+}
+
+func workspaceBinding(name string, storage *config.WorkspaceStorage) pipelinev1.WorkspaceBinding {
+	if storage == nil {
+		storage = &config.WorkspaceStorage{}
+	}
+	if storage.ClaimName != "" {
+		return pipelinev1.WorkspaceBinding{
+			Name:                  name,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: storage.ClaimName},
+		}
+	}
+	size := storage.Size
+	if size == "" {
+		size = defaultWorkspaceSize
+	}
+	accessMode := corev1.PersistentVolumeAccessMode(storage.AccessMode)
+	if accessMode == "" {
+		accessMode = corev1.ReadWriteOnce
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{"storage": resource.MustParse(size)},
+			},
+		},
+	}
+	if storage.StorageClass != "" {
+		storageClass := storage.StorageClass
+		claim.Spec.StorageClassName = &storageClass
+	}
+	return pipelinev1.WorkspaceBinding{
+		Name:                name,
+		VolumeClaimTemplate: claim,
+	}
+}
+
+// mergeWorkspaces returns the workspaces configured for an environment, with
+// the workspaces configured for a service overriding them.
+func mergeWorkspaces(env, svc *config.Workspaces) *config.Workspaces {
+	if svc == nil {
+		return env
+	}
+	if env == nil {
+		return svc
+	}
+	merged := *env
+	if svc.Source != nil {
+		merged.Source = svc.Source
+	}
+	if svc.Cache != nil {
+		merged.Cache = svc.Cache
+	}
+	return &merged
+}
+
+// serviceCITemplateName returns the name of the TriggerTemplate for a service
+// with its own workspaces.
+func serviceCITemplateName(env *config.Environment, svc *config.Service) string {
+	return fmt.Sprintf("%s-%s-%s", appCITemplateName, env.Name, svc.Name)
+}
+
+func serviceCITemplatePath(name string) string {
+	return filepath.ToSlash(filepath.Join("06-templates", name+".yaml"))
+}
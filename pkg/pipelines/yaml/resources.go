@@ -45,6 +45,18 @@ func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 	return MarshalOutput(f, item)
 }
 
+// UnmarshalItemFromFile unmarshals the YAML in the file to item.
+func UnmarshalItemFromFile(fs afero.Fs, filename string, item interface{}) error {
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+	if err := yaml.Unmarshal(data, item); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", filename, err)
+	}
+	return nil
+}
+
 // MarshalOutput marshal output to given writer
 func MarshalOutput(out io.Writer, output interface{}) error {
 	data, err := yaml.Marshal(output)
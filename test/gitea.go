@@ -0,0 +1,161 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FakeGitea is an HTTP server that implements enough of the Gitea API to
+// create repositories and manage repository hooks.
+type FakeGitea struct {
+	*httptest.Server
+	// Login is the login of the user that owns the access token.
+	Login string
+	// Repositories records the full names of the created repositories.
+	Repositories []string
+	// Hooks records the hooks by repository full name.
+	Hooks map[string][]FakeGiteaHook
+	// Prefix is the sub-path that the API is served under, if Gitea is hosted
+	// with a sub-path.
+	Prefix string
+
+	nextID int64
+	mu     sync.Mutex
+}
+
+// FakeGiteaHook is a hook created in a FakeGitea repository.
+type FakeGiteaHook struct {
+	ID     int64             `json:"id"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+// NewFakeGitea starts a FakeGitea server, which is closed when the test
+// completes.
+func NewFakeGitea(t *testing.T, login string) *FakeGitea {
+	t.Helper()
+	f := &FakeGitea{Login: login, Hooks: map[string][]FakeGiteaHook{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// Host returns the host:port of the server.
+func (f *FakeGitea) Host() string {
+	u, _ := url.Parse(f.URL)
+	return u.Host
+}
+
+func (f *FakeGitea) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.URL.Path, f.Prefix+"/") {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, f.Prefix)
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v1/version":
+		writeJSON(w, http.StatusOK, map[string]string{"version": "1.15.0"})
+	case r.Method == http.MethodGet && path == "/api/v1/user":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": 1, "login": f.Login})
+	case r.Method == http.MethodPost && path == "/api/v1/user/repos":
+		f.createRepository(w, r, f.Login)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "org" && parts[2] == "repos":
+		f.createRepository(w, r, parts[1])
+	case len(parts) >= 4 && parts[0] == "repos" && parts[3] == "hooks":
+		f.handleHooks(w, r, parts[1]+"/"+parts[2], parts[4:])
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	}
+}
+
+func (f *FakeGitea) createRepository(w http.ResponseWriter, r *http.Request, owner string) {
+	in := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	fullName := owner + "/" + in.Name
+	f.Repositories = append(f.Repositories, fullName)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":        len(f.Repositories),
+		"name":      in.Name,
+		"full_name": fullName,
+		"owner":     map[string]string{"login": owner},
+		"private":   true,
+		"clone_url": fmt.Sprintf("%s/%s.git", f.URL, fullName),
+		"ssh_url":   fmt.Sprintf("git@%s:%s.git", f.Host(), fullName),
+	})
+}
+
+func (f *FakeGitea) handleHooks(w http.ResponseWriter, r *http.Request, repo string, rest []string) {
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 0:
+		hooks := f.Hooks[repo]
+		if hooks == nil {
+			hooks = []FakeGiteaHook{}
+		}
+		writeJSON(w, http.StatusOK, hooks)
+	case r.Method == http.MethodPost && len(rest) == 0:
+		hook := FakeGiteaHook{}
+		if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		f.nextID++
+		hook.ID = f.nextID
+		f.Hooks[repo] = append(f.Hooks[repo], hook)
+		writeJSON(w, http.StatusCreated, hook)
+	case r.Method == http.MethodPatch && len(rest) == 1:
+		id, _ := strconv.ParseInt(rest[0], 10, 64)
+		edit := FakeGiteaHook{}
+		if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		for i, h := range f.Hooks[repo] {
+			if h.ID != id {
+				continue
+			}
+			for k, v := range edit.Config {
+				h.Config[k] = v
+			}
+			h.Active = edit.Active
+			f.Hooks[repo][i] = h
+			writeJSON(w, http.StatusOK, h)
+			return
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	case r.Method == http.MethodDelete && len(rest) == 1:
+		id, _ := strconv.ParseInt(rest[0], 10, 64)
+		hooks := []FakeGiteaHook{}
+		for _, h := range f.Hooks[repo] {
+			if h.ID != id {
+				hooks = append(hooks, h)
+			}
+		}
+		f.Hooks[repo] = hooks
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}